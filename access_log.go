@@ -0,0 +1,293 @@
+// File: /access_log.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatingFile = io.Writer ke file yang diputar kalau ukurannya melewati
+// maxBytes: path -> path.1 -> path.2 ... sampai keep file lama, sisanya dihapus.
+// Aman dipakai dari banyak goroutine.
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+	keep     int
+
+	mu     sync.Mutex
+	f      *os.File // nil = tertutup, atau gagal dibuka ulang setelah rotasi
+	size   int64
+	closed bool
+}
+
+func openRotatingFile(path string, maxBytes int64, keep int) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, maxBytes: maxBytes, keep: keep}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("access log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("access log: %w", err)
+	}
+	rf.f = f
+	rf.size = info.Size()
+	return nil
+}
+
+// Write menulis satu record utuh; rotasi terjadi sebelum record yang
+// akan membuat file melewati maxBytes (satu record tidak pernah terbelah).
+// Rotasi yang gagal tidak menghentikan log: path dibuka lagi dan ditulis
+// melewati maxBytes, rotasi dicoba lagi di Write berikutnya.
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return 0, os.ErrClosed
+	}
+	if rf.f != nil && rf.size > 0 && rf.size+int64(len(p)) > rf.maxBytes {
+		if err := rf.rotate(); err != nil {
+			logWarnf("access log rotation failed, still writing to %s: %v", rf.path, err)
+		}
+	}
+	if rf.f == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// rotate menutup file lalu menggeser backup. Setelah error apa pun rf.f
+// boleh nil; Write yang membuka lagi path.
+func (rf *rotatingFile) rotate() error {
+	err := rf.f.Close()
+	rf.f = nil
+	if err != nil {
+		return fmt.Errorf("access log: %w", err)
+	}
+
+	// file paling lama dibuang, sisanya digeser satu nomor
+	if rf.keep == 0 {
+		if err := os.Remove(rf.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("access log: %w", err)
+		}
+	} else {
+		_ = os.Remove(rf.backupName(rf.keep))
+		for i := rf.keep - 1; i >= 1; i-- {
+			if err := os.Rename(rf.backupName(i), rf.backupName(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("access log: %w", err)
+			}
+		}
+		if err := os.Rename(rf.path, rf.backupName(1)); err != nil {
+			return fmt.Errorf("access log: %w", err)
+		}
+	}
+	return rf.open()
+}
+
+func (rf *rotatingFile) backupName(i int) string {
+	return rf.path + "." + strconv.Itoa(i)
+}
+
+// Close flush ke disk lalu menutup file; Write setelahnya gagal
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return nil
+	}
+	rf.closed = true
+	if rf.f == nil {
+		return nil
+	}
+	err := errors.Join(rf.f.Sync(), rf.f.Close())
+	rf.f = nil
+	return err
+}
+
+// accessEntry = data satu request untuk access log
+type accessEntry struct {
+	Time       time.Time
+	RequestID  string
+	RemoteAddr string
+	Method     string
+	URI        string // raw path, atau route pattern kalau -log-raw-path=false
+	Proto      string
+	Route      string
+	Status     int
+	Bytes      int64
+	Duration   time.Duration
+	Referer    string
+	UserAgent  string
+	Client     string // label API key, "" = tanpa key
+}
+
+// accessFormatter mengubah satu entry jadi satu baris (tanpa newline).
+// Format baru cukup implement interface ini dan didaftarkan di accessFormats.
+type accessFormatter interface {
+	Format(e accessEntry) string
+}
+
+var accessFormats = map[string]accessFormatter{
+	"common":   clfFormat{},
+	"combined": clfFormat{combined: true},
+	"json":     jsonAccessFormat{},
+	"dev":      devAccessFormat{},
+}
+
+func accessFormatNames() []string {
+	return slices.Sorted(maps.Keys(accessFormats))
+}
+
+// accessLogger menulis entry lewat format ke w. Tanpa format, entry jadi
+// record slog level info (field sama dengan log aplikasi).
+type accessLogger struct {
+	format accessFormatter
+	w      io.Writer
+	record *slog.Logger
+}
+
+func newAccessLogger(format, logFormat string, w io.Writer) *accessLogger {
+	if f, ok := accessFormats[format]; ok {
+		return &accessLogger{format: f, w: w}
+	}
+
+	opts := &slog.HandlerOptions{Level: levelInfo}
+	if logFormat == "json" {
+		return &accessLogger{record: slog.New(slog.NewJSONHandler(w, opts))}
+	}
+	return &accessLogger{record: slog.New(slog.NewTextHandler(w, opts))}
+}
+
+func (a *accessLogger) log(ctx context.Context, fields []any, e accessEntry) {
+	if a.format == nil {
+		a.record.With(fields...).Log(ctx, levelInfo, "request",
+			"route", e.Route,
+			"status", e.Status,
+			"duration_ms", durationMS(e.Duration),
+			"bytes", e.Bytes,
+		)
+		return
+	}
+	// satu Write per baris supaya baris dari request paralel tidak bercampur
+	_, _ = io.WriteString(a.w, a.format.Format(e)+"\n")
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// clfFormat = Common Log Format; combined menambah Referer dan User-Agent.
+// host - - [10/Oct/2000:13:55:36 -0700] "GET /x HTTP/1.1" 200 2326
+type clfFormat struct {
+	combined bool
+}
+
+func (f clfFormat) Format(e accessEntry) string {
+	bytes := "-"
+	if e.Bytes > 0 {
+		bytes = strconv.FormatInt(e.Bytes, 10)
+	}
+
+	// authuser = label API key
+	line := fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s`,
+		orDash(remoteHost(e.RemoteAddr)), orDash(clfEscape(strings.ReplaceAll(e.Client, " ", "_"))),
+		e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		clfEscape(e.Method), clfEscape(e.URI), clfEscape(e.Proto),
+		e.Status, bytes,
+	)
+	if f.combined {
+		line += fmt.Sprintf(` "%s" "%s"`, clfEscape(orDash(e.Referer)), clfEscape(orDash(e.UserAgent)))
+	}
+	return line
+}
+
+type jsonAccessFormat struct{}
+
+func (jsonAccessFormat) Format(e accessEntry) string {
+	b, _ := json.Marshal(struct {
+		Time       time.Time `json:"time"`
+		RequestID  string    `json:"request_id"`
+		RemoteAddr string    `json:"remote_addr"`
+		Method     string    `json:"method"`
+		URI        string    `json:"uri"`
+		Proto      string    `json:"proto"`
+		Route      string    `json:"route"`
+		Status     int       `json:"status"`
+		Bytes      int64     `json:"bytes"`
+		DurationMS float64   `json:"duration_ms"`
+		Referer    string    `json:"referer"`
+		UserAgent  string    `json:"user_agent"`
+		Client     string    `json:"client,omitempty"`
+	}{
+		e.Time, e.RequestID, orDash(e.RemoteAddr), e.Method, e.URI, e.Proto, e.Route,
+		e.Status, e.Bytes, durationMS(e.Duration), orDash(e.Referer), orDash(e.UserAgent), e.Client,
+	})
+	return string(b)
+}
+
+// devAccessFormat: ringkas untuk terminal, mis. "GET /users/{id} 404 0.12ms 62B"
+type devAccessFormat struct{}
+
+func (devAccessFormat) Format(e accessEntry) string {
+	return fmt.Sprintf("%s %s %d %.2fms %dB", e.Method, e.Route, e.Status, durationMS(e.Duration), e.Bytes)
+}
+
+// remoteHost = host tanpa port ("unix" tetap apa adanya)
+func remoteHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+// clfEscape: kutip dan karakter non-printable di-escape supaya satu
+// request selalu satu baris yang bisa di-parse
+func clfEscape(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,233 @@
+// File: /access_log_test.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// accessLogEntries = entry tetap untuk golden access log: request normal,
+// header kosong / aneh, Unix socket, dan label API key
+func accessLogEntries() []accessEntry {
+	at := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.FixedZone("WIB", 7*3600))
+	return []accessEntry{
+		{
+			Time: at, RequestID: "req-1", RemoteAddr: "203.0.113.7:51234",
+			Method: "GET", URI: "/users/1?pretty=true", Proto: "HTTP/1.1", Route: "/users/{id}",
+			Status: 200, Bytes: 142, Duration: 1250 * time.Microsecond,
+			Referer: "https://example.com/app", UserAgent: "curl/8.5.0",
+		},
+		{
+			// tanpa Referer/User-Agent dan body kosong = "-"
+			Time: at.Add(time.Second), RequestID: "req-2", RemoteAddr: "[2001:db8::1]:443",
+			Method: "DELETE", URI: "/users/1", Proto: "HTTP/2.0", Route: "/users/{id}",
+			Status: 204, Bytes: 0, Duration: 300 * time.Microsecond,
+			Referer: "  ", Client: "ops team",
+		},
+		{
+			// kutip, backslash dan byte kontrol di-escape, satu request = satu baris
+			Time: at.Add(2 * time.Second), RequestID: "req-3", RemoteAddr: "unix",
+			Method: "POST", URI: "/users/\"x\"\n", Proto: "HTTP/1.1", Route: "/users",
+			Status: 400, Bytes: 61, Duration: 2 * time.Millisecond,
+			Referer: "bad\x00ref", UserAgent: "agent \"quoted\"\x7f",
+		},
+		{
+			// RemoteAddr kosong (tidak lewat clientAddr)
+			Time: at.Add(3 * time.Second), Method: "GET", URI: "/health", Proto: "HTTP/1.0", Route: "/health",
+			Status: 503, Bytes: 27, Duration: 15 * time.Millisecond,
+		},
+	}
+}
+
+func TestAccessLogFormats(t *testing.T) {
+	dir := filepath.Join("testdata", "access_log")
+	for _, name := range accessFormatNames() {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			l := newAccessLogger(name, "text", &b)
+			for _, e := range accessLogEntries() {
+				l.log(t.Context(), nil, e)
+			}
+			got := b.String()
+
+			file := filepath.Join(dir, name+".golden")
+			if *updateGolden {
+				if err := os.MkdirAll(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("%v (run go test -run TestAccessLogFormats -update)", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s format differs from %s\ngot:\n%s\nwant:\n%s", name, file, got, want)
+			}
+		})
+	}
+}
+
+// format common/combined tidak pernah menghasilkan field kosong
+func TestAccessLogCLFHasNoEmptyFields(t *testing.T) {
+	for _, name := range []string{"common", "combined"} {
+		for _, e := range accessLogEntries() {
+			line := accessFormats[name].Format(e)
+			if strings.Contains(line, `""`) || strings.Contains(line, "  ") || strings.ContainsAny(line, "\n\x00\x7f") {
+				t.Errorf("%s: malformed line %q", name, line)
+			}
+		}
+	}
+}
+
+// readLines = isi file per baris, nil kalau file tidak ada
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestRotatingFileSizeAndKeep(t *testing.T) {
+	tests := []struct {
+		keep int
+		want map[string][]string // nama file -> isi
+	}{
+		{2, map[string][]string{
+			"access.log":   {"rec-6", "rec-7"},
+			"access.log.1": {"rec-4", "rec-5"},
+			"access.log.2": {"rec-2", "rec-3"},
+			"access.log.3": nil, // rec-0 dan rec-1 sudah dibuang
+		}},
+		{0, map[string][]string{
+			"access.log":   {"rec-6", "rec-7"},
+			"access.log.1": nil,
+		}},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "access.log")
+		// file lama ikut dihitung ukurannya
+		if err := os.WriteFile(path, []byte("rec-0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		rf, err := openRotatingFile(path, 12, tt.keep) // 2 record per file
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i <= 7; i++ {
+			if _, err := rf.Write([]byte(fmt.Sprintf("rec-%d\n", i))); err != nil {
+				t.Fatalf("keep %d: write %d: %v", tt.keep, i, err)
+			}
+		}
+		if err := rf.Close(); err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range tt.want {
+			if got := readLines(t, filepath.Join(filepath.Dir(path), name)); !slices.Equal(got, want) {
+				t.Errorf("keep %d: %s = %q, want %q", tt.keep, name, got, want)
+			}
+		}
+		if _, err := rf.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("keep %d: write after Close: %v", tt.keep, err)
+		}
+	}
+}
+
+// banyak goroutine: tidak ada record yang hilang atau terbelah antar file
+func TestRotatingFileConcurrentWrites(t *testing.T) {
+	const writers, perWriter = 8, 50
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	rf, err := openRotatingFile(path, 200, writers*perWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				_, _ = rf.Write([]byte(fmt.Sprintf("writer-%d record-%03d\n", w, i)))
+			}
+		}()
+	}
+	wg.Wait()
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(path + "*")
+	if len(files) < 10 {
+		t.Fatalf("only %d files, rotation did not happen", len(files))
+	}
+	seen := map[string]bool{}
+	for _, f := range files {
+		if info, _ := os.Stat(f); info.Size() > 200 {
+			t.Errorf("%s is %d bytes, over maxBytes", f, info.Size())
+		}
+		for _, line := range readLines(t, f) {
+			if !strings.HasPrefix(line, "writer-") || len(line) != len("writer-0 record-000") || seen[line] {
+				t.Fatalf("%s: bad or duplicate line %q", f, line)
+			}
+			seen[line] = true
+		}
+	}
+	if len(seen) != writers*perWriter {
+		t.Fatalf("%d records, want %d", len(seen), writers*perWriter)
+	}
+}
+
+// rotasi gagal: path dibuka lagi, record berikutnya tidak hilang
+func TestRotatingFileRotateError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	// access.log.1 = direktori berisi file, jadi rename ke sana gagal
+	if err := os.MkdirAll(filepath.Join(path+".1", "x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rf, err := openRotatingFile(path, 12, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	for i := 1; i <= 4; i++ {
+		if _, err := rf.Write([]byte(fmt.Sprintf("rec-%d\n", i))); err != nil {
+			t.Fatalf("write %d after failed rotation: %v", i, err)
+		}
+	}
+	if got := readLines(t, path); !slices.Equal(got, []string{"rec-1", "rec-2", "rec-3", "rec-4"}) {
+		t.Fatalf("access.log = %q", got)
+	}
+
+	// penyebabnya hilang: rotasi berikutnya jalan lagi
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rf.Write([]byte("rec-5\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLines(t, path); !slices.Equal(got, []string{"rec-5"}) {
+		t.Fatalf("access.log after recovery = %q", got)
+	}
+	if got := readLines(t, path+".1"); len(got) != 4 {
+		t.Fatalf("access.log.1 after recovery = %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+// File: /admin_auth.go
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const adminRealm = `Basic realm="admin", charset="UTF-8"`
+
+// adminAuth = HTTP Basic auth untuk /admin/* dan /debug/pprof/.
+// Password disimpan sebagai hash bcrypt, tidak pernah plaintext.
+type adminAuth struct {
+	user [32]byte // sha256 username, supaya perbandingan tidak bocor panjangnya
+	hash []byte
+}
+
+// newAdminAuth mengembalikan nil kalau user dan hash kosong (route admin tidak dipasang)
+func newAdminAuth(user, passwordHash string) (*adminAuth, error) {
+	if user == "" && passwordHash == "" {
+		return nil, nil
+	}
+	if user == "" || passwordHash == "" {
+		return nil, errors.New("admin user and admin password hash must be set together")
+	}
+	if _, err := bcrypt.Cost([]byte(passwordHash)); err != nil {
+		return nil, fmt.Errorf("admin password hash must be a bcrypt hash: %w", err)
+	}
+	return &adminAuth{user: sha256.Sum256([]byte(user)), hash: []byte(passwordHash)}, nil
+}
+
+// check: bcrypt tetap dijalankan walau username salah, jadi waktu
+// respons tidak membedakan username salah dan password salah
+func (a *adminAuth) check(r *http.Request) bool {
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	sum := sha256.Sum256([]byte(user))
+	userOK := subtle.ConstantTimeCompare(sum[:], a.user[:]) == 1
+	passOK := bcrypt.CompareHashAndPassword(a.hash, []byte(pass)) == nil
+	return userOK && passOK
+}
+
+// wrap: tanpa / salah credential = 401 unauthorized dengan WWW-Authenticate
+func (a *adminAuth) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.check(r) {
+			if _, _, ok := r.BasicAuth(); ok {
+				requestLog(r.Context()).Warn("invalid admin credentials")
+			}
+			w.Header().Set("WWW-Authenticate", adminRealm)
+			errorJSON(w, http.StatusUnauthorized, "unauthorized", "admin credentials required", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
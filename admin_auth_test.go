@@ -0,0 +1,78 @@
+// File: /admin_auth_test.go
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAdminBasicAuth(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		withAdmin(c)
+		c.Pprof = true
+	}))
+
+	tests := []struct {
+		name   string
+		header []string
+		want   int
+	}{
+		{"no credentials", nil, http.StatusUnauthorized},
+		{"wrong password", basicHeader(testAdminUser, "nope"), http.StatusUnauthorized},
+		{"wrong user", basicHeader("root", testAdminPassword), http.StatusUnauthorized},
+		{"not basic", []string{"Authorization", "Bearer " + testAdminPassword}, http.StatusUnauthorized},
+		{"correct", adminHeader(), http.StatusOK},
+	}
+	for _, tt := range tests {
+		for _, req := range []struct{ method, path, body string }{
+			{"POST", "/admin/fixtures", `{"name":"small"}`},
+			{"GET", "/debug/pprof/", ""},
+		} {
+			res, body := doRequest(t, ts, req.method, req.path, req.body, tt.header...)
+			if res.StatusCode != tt.want {
+				t.Errorf("%s %s: status %d, want %d: %s", tt.name, req.path, res.StatusCode, tt.want, body)
+				continue
+			}
+			if tt.want != http.StatusUnauthorized {
+				continue
+			}
+			if got := res.Header.Get("WWW-Authenticate"); got != adminRealm {
+				t.Errorf("%s %s: WWW-Authenticate %q", tt.name, req.path, got)
+			}
+			if got := decodeBody[errorResponse](t, body); got.Error != "unauthorized" {
+				t.Errorf("%s %s: body %s", tt.name, req.path, body)
+			}
+		}
+	}
+}
+
+// tanpa credential di config route admin tidak dipasang sama sekali;
+// -pprof tanpa credential sudah ditolak Validate
+func TestAdminRoutesUnregisteredWithoutCredentials(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.AllowReset = true }))
+	for _, req := range []struct{ method, path string }{
+		{"POST", "/admin/fixtures"},
+		{"POST", "/admin/reset"},
+		{"GET", "/debug/pprof/"},
+	} {
+		res, body := doRequest(t, ts, req.method, req.path, "", adminHeader()...)
+		if res.StatusCode != http.StatusNotFound || res.Header.Get("WWW-Authenticate") != "" {
+			t.Errorf("%s %s: status %d, headers %v: %s", req.method, req.path, res.StatusCode, res.Header, body)
+		}
+	}
+}
+
+func TestNewAdminAuthErrors(t *testing.T) {
+	if a, err := newAdminAuth("", ""); a != nil || err != nil {
+		t.Fatalf("empty = %v, %v; want nil", a, err)
+	}
+	for _, tt := range []struct{ user, hash string }{
+		{"admin", ""},
+		{"", "$2a$04$abcdefghijklmnopqrstuu"},
+		{"admin", "plaintext"},
+	} {
+		if _, err := newAdminAuth(tt.user, tt.hash); err == nil {
+			t.Errorf("newAdminAuth(%q, %q): no error", tt.user, tt.hash)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// File: /api_keys.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// apiKeySet menyimpan sha256 dari key -> label dan role. Lookup lewat hash supaya
+// waktu perbandingan tidak bergantung pada isi key yang dikirim client.
+type apiKeySet struct {
+	keys map[[32]byte]apiKey
+}
+
+type apiKey struct {
+	label string
+	role  Role
+	// user = user pemilik key (user=<id>), "" = key layanan tanpa user.
+	// Request dengan key ini dicatat sebagai aktivitas user (lastActiveAt).
+	user UserID
+}
+
+// loadAPIKeys menggabungkan key dari config (-api-key) dan -api-keys-file.
+// Format satu entry: "<key> [label] [role=admin|user] [user=<id>]", role default admin
+// (key tanpa role tetap boleh semua seperti sebelum ada role). Key dengan user=
+// memakai role user itu di store (lihat resolveCaller), role= diabaikan.
+// Kosong semua = nil (auth mati).
+func loadAPIKeys(entries []string, file string) (*apiKeySet, error) {
+	set := &apiKeySet{keys: map[[32]byte]apiKey{}}
+
+	for i, entry := range entries {
+		if err := set.add(entry); err != nil {
+			return nil, fmt.Errorf("api key #%d: %w", i+1, err)
+		}
+	}
+
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("api keys file: %w", err)
+		}
+		defer f.Close()
+
+		sc := bufio.NewScanner(f)
+		for n := 1; sc.Scan(); n++ {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if err := set.add(line); err != nil {
+				return nil, fmt.Errorf("api keys file %s:%d: %w", file, n, err)
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("api keys file %s: %w", file, err)
+		}
+	}
+
+	if len(set.keys) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+func (s *apiKeySet) add(entry string) error {
+	fields := strings.Fields(entry)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty key")
+	}
+
+	k := apiKey{role: RoleAdmin}
+	var label []string
+	for _, f := range fields[1:] {
+		if v, ok := strings.CutPrefix(f, "role="); ok {
+			role, err := parseRole(v)
+			if err != nil {
+				return err
+			}
+			k.role = role
+			continue
+		}
+		if v, ok := strings.CutPrefix(f, "user="); ok {
+			id, err := parseUserID(v)
+			if err != nil {
+				return fmt.Errorf("invalid user %q: %w", v, err)
+			}
+			k.user = id
+			continue
+		}
+		label = append(label, f)
+	}
+	k.label = strings.Join(label, " ")
+	if k.label == "" {
+		k.label = "unlabeled"
+	}
+
+	sum := sha256.Sum256([]byte(fields[0]))
+	if _, dup := s.keys[sum]; dup {
+		return fmt.Errorf("duplicate key (label %q)", k.label)
+	}
+	s.keys[sum] = k
+	return nil
+}
+
+func (s *apiKeySet) lookup(key string) (apiKey, bool) {
+	k, ok := s.keys[sha256.Sum256([]byte(key))]
+	return k, ok
+}
+
+type apiKeyLabelKey struct{}
+
+type callerUserKey struct{}
+
+// callerUserID = user pemilik API key request ini, "" kalau key tanpa user=
+func callerUserID(ctx context.Context) UserID {
+	id, _ := ctx.Value(callerUserKey{}).(UserID)
+	return id
+}
+
+type authenticatedKey struct{}
+
+// withAuthenticated menandai request sudah terautentikasi di luar API key
+// (X-Signature, sertifikat client) dengan akses penuh seperti key tanpa role.
+// client = label untuk access log dan apiKeyLabelFromContext.
+func withAuthenticated(r *http.Request, client string) context.Context {
+	if ri := routeInfoFrom(r.Context()); ri != nil {
+		ri.client = client
+	}
+	ctx := context.WithValue(r.Context(), authenticatedKey{}, client)
+	ctx = context.WithValue(ctx, apiKeyLabelKey{}, client)
+	return context.WithValue(ctx, roleKey{}, RoleAdmin)
+}
+
+// authenticatedClient = label dari withAuthenticated, "" kalau belum
+func authenticatedClient(ctx context.Context) string {
+	client, _ := ctx.Value(authenticatedKey{}).(string)
+	return client
+}
+
+// apiKeyLabelFromContext = label API key request ini, "" kalau tanpa key
+func apiKeyLabelFromContext(ctx context.Context) string {
+	label, _ := ctx.Value(apiKeyLabelKey{}).(string)
+	return label
+}
+
+// unsafeMethods = method yang selalu butuh API key
+var unsafeMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// requireAPIKey: keys nil = auth mati. Method mutasi selalu butuh X-API-Key;
+// GET/HEAD/OPTIONS hanya kalau authReads. Path di openPaths selalu terbuka.
+// Tanpa key = 401 unauthorized, key salah = 403 forbidden.
+// Label key masuk ke context, logger request dan access log; role dan user
+// pemilik key ke context untuk authorize.
+func requireAPIKey(keys *apiKeySet, authReads bool, openPaths []string, next http.Handler) http.Handler {
+	if keys == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// sudah terautentikasi lewat X-Signature atau sertifikat client
+		if authenticatedClient(r.Context()) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := r.Header.Get(apiKeyHeader)
+		protected := authReads || slices.Contains(unsafeMethods, r.Method)
+		if slices.Contains(openPaths, r.URL.Path) || (!protected && key == "") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if key == "" {
+			errorJSON(w, http.StatusUnauthorized, "unauthorized", "missing "+apiKeyHeader+" header", nil)
+			return
+		}
+		k, ok := keys.lookup(key)
+		if !ok {
+			requestLog(r.Context()).Warn("invalid api key")
+			errorJSON(w, http.StatusForbidden, "forbidden", "invalid API key", nil)
+			return
+		}
+
+		if ri := routeInfoFrom(r.Context()); ri != nil {
+			ri.client = k.label
+		}
+		ctx := context.WithValue(r.Context(), apiKeyLabelKey{}, k.label)
+		if k.user != "" {
+			// role sebenarnya diisi resolveCaller dari user di store;
+			// sampai di sana hak paling kecil
+			ctx = context.WithValue(ctx, roleKey{}, RoleUser)
+			ctx = context.WithValue(ctx, callerUserKey{}, k.user)
+			ctx = withLogger(ctx, requestLog(ctx).With("api_key", k.label, "user", k.user))
+		} else {
+			ctx = context.WithValue(ctx, roleKey{}, k.role)
+			ctx = withLogger(ctx, requestLog(ctx).With("api_key", k.label, "role", k.role))
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,114 @@
+// File: /api_keys_test.go
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	keys := []string{"good-key ops team", "other-key"}
+	tests := []struct {
+		name         string
+		authReads    bool
+		method, path string
+		key          string
+		wantStatus   int
+		wantCode     string
+	}{
+		{"write without key", false, "POST", "/users", "", http.StatusUnauthorized, "unauthorized"},
+		{"write with wrong key", false, "POST", "/users", "nope", http.StatusForbidden, "forbidden"},
+		{"write with key", false, "POST", "/users", "good-key", http.StatusCreated, ""},
+		{"delete without key", false, "DELETE", "/users/1", "", http.StatusUnauthorized, "unauthorized"},
+		{"read without key", false, "GET", "/users", "", http.StatusOK, ""},
+		// key yang dikirim tetap dicek walau route terbuka untuk baca
+		{"read with wrong key", false, "GET", "/users", "nope", http.StatusForbidden, "forbidden"},
+		{"auth-reads without key", true, "GET", "/users", "", http.StatusUnauthorized, "unauthorized"},
+		{"auth-reads head without key", true, "HEAD", "/users", "", http.StatusUnauthorized, ""},
+		{"auth-reads with key", true, "GET", "/users", "other-key", http.StatusOK, ""},
+		{"auth-reads open path", true, "GET", "/health", "", http.StatusOK, ""},
+		{"open path ignores wrong key", true, "GET", "/health", "nope", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, testConfig(func(c *Config) {
+				c.APIKeys = keys
+				c.AuthReads = tt.authReads
+			}))
+			body := ""
+			if tt.method == "POST" {
+				body = `{"name":"Ada"}`
+			}
+			var header []string
+			if tt.key != "" {
+				header = []string{apiKeyHeader, tt.key}
+			}
+			res, resBody := doRequest(t, ts, tt.method, tt.path, body, header...)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", res.StatusCode, tt.wantStatus, resBody)
+			}
+			if tt.wantCode != "" && decodeBody[errorResponse](t, resBody).Error != tt.wantCode {
+				t.Fatalf("body %s, want error %q", resBody, tt.wantCode)
+			}
+		})
+	}
+}
+
+// tanpa key sama sekali auth mati
+func TestRequireAPIKeyDisabled(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	if res, body := doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`); res.StatusCode != http.StatusCreated {
+		t.Fatalf("status %d: %s", res.StatusCode, body)
+	}
+}
+
+// label key dari -api-keys-file masuk ke access log
+func TestAPIKeysFileLabelInAccessLog(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(file, []byte("# partner keys\n\nfile-key partner billing\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	logCfg, logPath := withAccessLog(t, "json")
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		logCfg(c)
+		c.APIKeysFile = file
+	}))
+
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`, apiKeyHeader, "file-key")
+	doRequest(t, ts, "GET", "/users", "")
+
+	lines := waitAccessLog(t, logPath, 2)
+	for i, want := range []string{"partner billing", ""} {
+		var entry struct {
+			Client string `json:"client"`
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.Client != want {
+			t.Errorf("line %d client %q, want %q: %s", i, entry.Client, want, lines[i])
+		}
+	}
+}
+
+func TestLoadAPIKeysErrors(t *testing.T) {
+	tests := []struct {
+		entries []string
+		file    string
+	}{
+		{[]string{"k1", "k1 again"}, ""},
+		{[]string{"k1 role=root"}, ""},
+		{nil, filepath.Join(t.TempDir(), "missing.txt")},
+	}
+	for _, tt := range tests {
+		if _, err := loadAPIKeys(tt.entries, tt.file); err == nil {
+			t.Errorf("loadAPIKeys(%q, %q): no error", tt.entries, tt.file)
+		}
+	}
+	if set, err := loadAPIKeys(nil, ""); set != nil || err != nil {
+		t.Errorf("no keys = %v, %v; want nil set", set, err)
+	}
+}
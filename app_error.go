@@ -1,13 +1,85 @@
 // File: /app_error.go
 package main
 
+import (
+	"net/http"
+	"time"
+)
+
 type AppError struct {
 	Status  int
 	Code    string
 	Message string
 	Details any
+
+	// RetryAfter: kalau > 0, writeAppError mengirim header Retry-After
+	// (detik, dibulatkan ke atas), mis. untuk 429 dan 503
+	RetryAfter time.Duration
+
+	// Err = penyebab asli, hanya untuk log server, tidak pernah dikirim ke client
+	Err error
 }
 
+// sentinel untuk errors.Is: dicocokkan lewat Code, jadi
+// errors.Is(NewNotFound("user not found"), ErrNotFound) == true
+var (
+	ErrNotFound   = &AppError{Status: http.StatusNotFound, Code: "not_found", Message: "resource not found"}
+	ErrValidation = &AppError{Status: http.StatusBadRequest, Code: "validation_failed", Message: "validation failed"}
+	ErrConflict   = &AppError{Status: http.StatusConflict, Code: "conflict", Message: "conflict"}
+
+	ErrPreconditionFailed = &AppError{Status: http.StatusPreconditionFailed, Code: "precondition_failed", Message: "precondition failed"}
+)
+
 func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Message + ": " + e.Err.Error()
+	}
 	return e.Code + ": " + e.Message
 }
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is: dua AppError dianggap sama kalau Code-nya sama
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// NewNotFound = 404 not_found
+func NewNotFound(message string) *AppError {
+	return &AppError{
+		Status:  http.StatusNotFound,
+		Code:    ErrNotFound.Code,
+		Message: message,
+	}
+}
+
+// NewValidation = 400 validation_failed dengan daftar field yang salah
+func NewValidation(details []string) *AppError {
+	return &AppError{
+		Status:  http.StatusBadRequest,
+		Code:    ErrValidation.Code,
+		Message: ErrValidation.Message,
+		Details: details,
+	}
+}
+
+// NewPreconditionFailed = 412 precondition_failed (If-Match tidak cocok)
+func NewPreconditionFailed(message string) *AppError {
+	return &AppError{
+		Status:  http.StatusPreconditionFailed,
+		Code:    ErrPreconditionFailed.Code,
+		Message: message,
+	}
+}
+
+// NewConflict = 409 conflict
+func NewConflict(message string) *AppError {
+	return &AppError{
+		Status:  http.StatusConflict,
+		Code:    ErrConflict.Code,
+		Message: message,
+	}
+}
@@ -0,0 +1,104 @@
+// File: /app_error_test.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAppErrorConstructors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *AppError
+		sentinel *AppError
+		status   int
+		code     string
+		message  string
+		details  any
+	}{
+		{"NewNotFound", NewNotFound("user not found"), ErrNotFound, http.StatusNotFound, "not_found", "user not found", nil},
+		{"NewValidation", NewValidation([]string{"name is required"}), ErrValidation, http.StatusBadRequest, "validation_failed", "validation failed", []string{"name is required"}},
+		{"validationError", validationError("invalid body", []string{"count must not be negative"}), ErrValidation, http.StatusBadRequest, "validation_failed", "invalid body", []string{"count must not be negative"}},
+		{"NewConflict", NewConflict("name already taken"), ErrConflict, http.StatusConflict, "conflict", "name already taken", nil},
+		{"NewPreconditionFailed", NewPreconditionFailed("stale"), ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed", "stale", nil},
+	}
+	sentinels := []*AppError{ErrNotFound, ErrValidation, ErrConflict, ErrPreconditionFailed}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.err
+			if e.Status != tt.status || e.Code != tt.code || e.Message != tt.message || !reflect.DeepEqual(e.Details, tt.details) {
+				t.Fatalf("got %+v", e)
+			}
+			if e.Status != tt.sentinel.Status {
+				t.Errorf("status %d differs from sentinel %d", e.Status, tt.sentinel.Status)
+			}
+
+			// cocok lewat wrapping berlapis, dan hanya dengan sentinel-nya
+			wrapped := fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", e))
+			for _, s := range sentinels {
+				if got := errors.Is(wrapped, s); got != (s == tt.sentinel) {
+					t.Errorf("errors.Is(%s, %s) = %v", tt.name, s.Code, got)
+				}
+			}
+			if got := asAppError(t, wrapped); got != e {
+				t.Errorf("errors.As returned %p, want %p", got, e)
+			}
+		})
+	}
+}
+
+func TestAppErrorIsIgnoresOtherErrors(t *testing.T) {
+	if errors.Is(errors.New("not_found"), ErrNotFound) {
+		t.Error("plain error matched ErrNotFound")
+	}
+	if errors.Is(NewNotFound("x"), context.Canceled) {
+		t.Error("AppError matched context.Canceled")
+	}
+
+	// Err tetap bisa dicari lewat Unwrap, tanpa mengubah Code
+	e := &AppError{Status: http.StatusServiceUnavailable, Code: "store_unavailable", Message: "store unavailable", Err: context.DeadlineExceeded}
+	if !errors.Is(e, context.DeadlineExceeded) {
+		t.Error("cause not reachable through Unwrap")
+	}
+	if want := "store_unavailable: store unavailable: context deadline exceeded"; e.Error() != want {
+		t.Errorf("Error() = %q, want %q", e.Error(), want)
+	}
+}
+
+// UserService hanya mengembalikan error yang bisa dicocokkan dengan sentinel
+func TestUserServiceErrorsMatchSentinels(t *testing.T) {
+	svc, _ := newTestService(newFakeClock())
+	ctx := context.Background()
+	u, err := svc.CreateUser(ctx, "Ada", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, errCreate := svc.CreateUser(ctx, "  ", "", "")
+	_, errGet := svc.GetUser(ctx, "404", false)
+	errDelete := svc.DeleteUser(ctx, "404")
+	_, errRestore := svc.RestoreUser(ctx, "404")
+	_, errUpdate := svc.UpdateUser(ctx, u.ID, "Ada Lovelace", nil, u.Version+1)
+
+	tests := []struct {
+		name string
+		err  error
+		want *AppError
+	}{
+		{"create blank name", errCreate, ErrValidation},
+		{"get missing", errGet, ErrNotFound},
+		{"delete missing", errDelete, ErrNotFound},
+		{"restore missing", errRestore, ErrNotFound},
+		{"update stale version", errUpdate, ErrPreconditionFailed},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: error %v, want %s", tt.name, tt.err, tt.want.Code)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// File: /audit.go
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// action yang dicatat di audit log
+const (
+	auditUserCreate   = "user.create"
+	auditUserUpdate   = "user.update"
+	auditUserPatch    = "user.patch"
+	auditUserDelete   = "user.delete"
+	auditUserRestore  = "user.restore"
+	auditUserPassword = "user.password"
+	auditUserProfile  = "user.profile"
+)
+
+type AuditEvent struct {
+	Seq       uint64    `json:"seq"`
+	At        time.Time `json:"at"`
+	Action    string    `json:"action"`
+	UserID    UserID    `json:"userId"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+// AuditLog = ring buffer berukuran tetap; event paling lama ditimpa
+// setelah penuh. Size 0 = audit dimatikan.
+type AuditLog struct {
+	mu     sync.Mutex
+	events []AuditEvent
+	next   int // posisi tulis berikutnya
+	full   bool
+	seq    uint64
+	// now = sumber waktu AuditEvent.At, default time.Now
+	now func() time.Time
+}
+
+func NewAuditLog(size int) *AuditLog {
+	return &AuditLog{events: make([]AuditEvent, size), now: time.Now}
+}
+
+// Record mencatat satu event untuk request ctx. Di dalam batch
+// (withAuditBuffer) event ditahan dulu dan baru masuk log kalau batch di-commit.
+func (a *AuditLog) Record(ctx context.Context, action string, id UserID) {
+	ev := AuditEvent{At: a.now().UTC(), Action: action, UserID: id, RequestID: requestIDFromContext(ctx)}
+	if buf, ok := ctx.Value(auditBufferKey{}).(*auditBuffer); ok {
+		buf.events = append(buf.events, ev)
+		return
+	}
+	a.append(ev)
+}
+
+// append memberi Seq lalu menulis ev ke ring buffer
+func (a *AuditLog) append(ev AuditEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.events) == 0 {
+		return
+	}
+
+	a.seq++
+	ev.Seq = a.seq
+	a.events[a.next] = ev
+	a.next = (a.next + 1) % len(a.events)
+	if a.next == 0 {
+		a.full = true
+	}
+}
+
+// auditBuffer menampung event operasi batch sampai batch selesai. Batch
+// memegang gate (write lock), jadi operasinya berurutan dan tidak perlu mutex.
+type auditBuffer struct {
+	events []AuditEvent
+}
+
+type auditBufferKey struct{}
+
+// withAuditBuffer: event Record di ctx yang dihasilkan masuk buf, bukan log
+func withAuditBuffer(ctx context.Context, buf *auditBuffer) context.Context {
+	return context.WithValue(ctx, auditBufferKey{}, buf)
+}
+
+// commit memindahkan event buf ke log (batch sukses). Rollback = buf dibuang:
+// id user yang dibuat batch dipakai lagi setelah restoreSnapshot, jadi event
+// lama akan menunjuk user yang salah.
+func (a *AuditLog) commit(buf *auditBuffer) {
+	for _, ev := range buf.events {
+		a.append(ev)
+	}
+}
+
+// Recent mengembalikan event yang tersimpan, paling lama dulu
+func (a *AuditLog) Recent() []AuditEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.full {
+		return append([]AuditEvent{}, a.events[:a.next]...)
+	}
+	out := make([]AuditEvent, 0, len(a.events))
+	out = append(out, a.events[a.next:]...)
+	return append(out, a.events[:a.next]...)
+}
+
+func (a *AuditLog) Cap() int {
+	return len(a.events)
+}
+
+type AuditHandler struct {
+	log *AuditLog
+}
+
+func NewAuditHandler(log *AuditLog) *AuditHandler {
+	return &AuditHandler{log: log}
+}
+
+// GET /audit
+func (h *AuditHandler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	if !requireMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	events := h.log.Recent()
+	writeData(w, http.StatusOK, events, apiResponse{
+		"count":    len(events),
+		"capacity": h.log.Cap(),
+	})
+}
@@ -0,0 +1,150 @@
+// File: /audit_test.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// auditEnvelope = body GET /audit
+type auditEnvelope struct {
+	Data []AuditEvent `json:"data"`
+	Meta struct {
+		Count    int `json:"count"`
+		Capacity int `json:"capacity"`
+	} `json:"meta"`
+}
+
+func getAudit(t *testing.T, ts *httptest.Server) auditEnvelope {
+	t.Helper()
+	res, body := doRequest(t, ts, "GET", "/audit", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET /audit: status %d: %s", res.StatusCode, body)
+	}
+	return decodeBody[auditEnvelope](t, body)
+}
+
+func TestAuditRecordsWritesInOrder(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(func(c *Config) { c.SoftDelete = true }), WithServerClock(clock.Now))
+
+	steps := []struct {
+		method, path, body string
+		status             int
+	}{
+		{"POST", "/users", `{"name":"Ada"}`, http.StatusCreated},
+		{"POST", "/users", `{"name":"Grace"}`, http.StatusCreated},
+		{"PUT", "/users/1", `{"name":"Ada Lovelace"}`, http.StatusOK},
+		{"PATCH", "/users/2", `{"name":"Grace Hopper"}`, http.StatusOK},
+		{"GET", "/users/1", "", http.StatusOK}, // baca = tidak dicatat
+		{"DELETE", "/users/1", "", http.StatusOK},
+		{"POST", "/users/1/restore", "", http.StatusOK},
+		{"DELETE", "/users/99", "", http.StatusNotFound}, // gagal = tidak dicatat
+	}
+	for i, s := range steps {
+		clock.Advance(time.Second)
+		res, body := doRequest(t, ts, s.method, s.path, s.body, "X-Request-ID", fmt.Sprintf("audit-%d", i))
+		if res.StatusCode != s.status {
+			t.Fatalf("%s %s: status %d, want %d: %s", s.method, s.path, res.StatusCode, s.status, body)
+		}
+	}
+
+	want := []AuditEvent{
+		{Seq: 1, Action: auditUserCreate, UserID: "1", RequestID: "audit-0", At: testEpoch.Add(1 * time.Second)},
+		{Seq: 2, Action: auditUserCreate, UserID: "2", RequestID: "audit-1", At: testEpoch.Add(2 * time.Second)},
+		{Seq: 3, Action: auditUserUpdate, UserID: "1", RequestID: "audit-2", At: testEpoch.Add(3 * time.Second)},
+		{Seq: 4, Action: auditUserPatch, UserID: "2", RequestID: "audit-3", At: testEpoch.Add(4 * time.Second)},
+		{Seq: 5, Action: auditUserDelete, UserID: "1", RequestID: "audit-5", At: testEpoch.Add(6 * time.Second)},
+		{Seq: 6, Action: auditUserRestore, UserID: "1", RequestID: "audit-6", At: testEpoch.Add(7 * time.Second)},
+	}
+	got := getAudit(t, ts)
+	if got.Meta.Count != len(want) || got.Meta.Capacity != DefaultConfig().AuditLogSize {
+		t.Errorf("meta %+v", got.Meta)
+	}
+	if len(got.Data) != len(want) {
+		t.Fatalf("got %d events: %+v", len(got.Data), got.Data)
+	}
+	for i := range want {
+		if got.Data[i] != want[i] {
+			t.Errorf("event %d: got %+v, want %+v", i, got.Data[i], want[i])
+		}
+	}
+}
+
+func TestAuditLogCapsAtSize(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.AuditLogSize = 3 }))
+	for i := 1; i <= 5; i++ {
+		if res, body := doRequest(t, ts, "POST", "/users", fmt.Sprintf(`{"name":"user-%d"}`, i)); res.StatusCode != http.StatusCreated {
+			t.Fatalf("create %d: %d %s", i, res.StatusCode, body)
+		}
+	}
+
+	got := getAudit(t, ts)
+	if got.Meta.Count != 3 || got.Meta.Capacity != 3 || len(got.Data) != 3 {
+		t.Fatalf("meta %+v, %d events", got.Meta, len(got.Data))
+	}
+	// yang tersisa = 3 event terakhir, tetap paling lama dulu
+	for i, ev := range got.Data {
+		if wantSeq, wantID := uint64(i+3), UserID(fmt.Sprint(i+3)); ev.Seq != wantSeq || ev.UserID != wantID {
+			t.Errorf("event %d: seq %d user %s, want seq %d user %s", i, ev.Seq, ev.UserID, wantSeq, wantID)
+		}
+	}
+}
+
+func TestAuditLogDisabled(t *testing.T) {
+	a := NewAuditLog(0)
+	a.Record(context.Background(), auditUserCreate, "1")
+	if got := a.Recent(); len(got) != 0 || a.Cap() != 0 {
+		t.Fatalf("disabled log kept %+v", got)
+	}
+}
+
+func TestAuditLogConcurrentRecord(t *testing.T) {
+	a := NewAuditLog(50)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				a.Record(context.Background(), auditUserCreate, "1")
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := a.Recent()
+	if len(got) != 50 {
+		t.Fatalf("got %d events, want 50", len(got))
+	}
+	// 200 event, 50 terakhir: seq 151..200 berurutan
+	for i, ev := range got {
+		if ev.Seq != uint64(151+i) {
+			t.Fatalf("event %d has seq %d, want %d", i, ev.Seq, 151+i)
+		}
+	}
+}
+
+// batch: event baru masuk saat commit, rollback tidak meninggalkan event
+func TestAuditBatchCommitAndRollback(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+
+	ok := `{"operations":[{"method":"POST","path":"/users","body":{"name":"Ada"}},{"method":"PATCH","path":"/users/$1.id","body":{"name":"Ada L"}}]}`
+	if res, body := doRequest(t, ts, "POST", "/batch", ok); res.StatusCode != http.StatusOK {
+		t.Fatalf("batch: %d %s", res.StatusCode, body)
+	}
+	failing := `{"operations":[{"method":"POST","path":"/users","body":{"name":"Grace"}},{"method":"DELETE","path":"/users/99"}]}`
+	if res, body := doRequest(t, ts, "POST", "/batch", failing); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("failing batch: %d %s", res.StatusCode, body)
+	}
+
+	got := getAudit(t, ts)
+	if len(got.Data) != 2 || got.Data[0].Action != auditUserCreate || got.Data[1].Action != auditUserPatch || got.Data[1].UserID != "1" {
+		t.Fatalf("events %+v", got.Data)
+	}
+}
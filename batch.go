@@ -0,0 +1,318 @@
+// File: /batch.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const maxBatchOperations = 20
+
+// batchRoute = kombinasi method + bentuk path yang boleh ada di dalam batch.
+// Hanya route mutasi user, karena hanya itu yang bisa di-rollback.
+type batchRoute struct {
+	method   string
+	segments []string // "{id}" = segmen bebas (nilai atau placeholder)
+}
+
+var batchRoutes = []batchRoute{
+	{http.MethodPost, []string{"users"}},
+	{http.MethodPut, []string{"users", "{id}"}},
+	{http.MethodPatch, []string{"users", "{id}"}},
+	{http.MethodDelete, []string{"users", "{id}"}},
+	{http.MethodPost, []string{"users", "{id}", "restore"}},
+}
+
+// placeholder "$1.id" = field id dari data response operasi ke-1
+var batchRefPattern = regexp.MustCompile(`\$(\d+)\.([A-Za-z0-9_]+)`)
+
+type batchOperation struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type batchRequest struct {
+	Operations []batchOperation `json:"operations"`
+}
+
+// Validate = tahap "validasi semua dulu", sebelum ada yang dijalankan
+func (req batchRequest) Validate() error {
+	var details []string
+
+	if len(req.Operations) == 0 {
+		details = append(details, "operations must contain at least one operation")
+	}
+	if len(req.Operations) > maxBatchOperations {
+		details = append(details, fmt.Sprintf("operations must not contain more than %d operations", maxBatchOperations))
+	}
+
+	for i, op := range req.Operations {
+		prefix := fmt.Sprintf("operations[%d]: ", i)
+
+		if strings.Trim(strings.SplitN(op.Path, "?", 2)[0], "/") == "batch" {
+			details = append(details, prefix+"nested batch requests are not allowed")
+			continue
+		}
+		if !batchAllowed(strings.ToUpper(op.Method), op.Path) {
+			details = append(details, prefix+op.Method+" "+truncatePath(op.Path)+" is not allowed in a batch")
+		}
+		if len(op.Body) > 0 && !json.Valid(op.Body) {
+			details = append(details, prefix+"body must be valid JSON")
+		}
+
+		// referensi hanya boleh ke operasi sebelumnya
+		for _, m := range batchRefPattern.FindAllStringSubmatch(op.Path+string(op.Body), -1) {
+			n, _ := strconv.Atoi(m[1])
+			if n < 1 || n > i {
+				details = append(details, prefix+m[0]+" must refer to an earlier operation")
+			}
+		}
+	}
+
+	if len(details) > 0 {
+		return validationError("invalid batch", details)
+	}
+	return nil
+}
+
+func batchAllowed(method, path string) bool {
+	if strings.Contains(path, "?") {
+		return false
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, route := range batchRoutes {
+		if route.method != method || len(route.segments) != len(segments) {
+			continue
+		}
+		match := true
+		for i, want := range route.segments {
+			if want != "{id}" && want != segments[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// batchResult = hasil satu operasi, urut sesuai request
+type batchResult struct {
+	Status int    `json:"status"`
+	Body   any    `json:"body,omitempty"`
+	Error  string `json:"error,omitempty"` // alasan kalau tidak dijalankan
+}
+
+// BatchHandler menjalankan beberapa mutasi sebagai satu kesatuan.
+// gate dipegang (write lock) selama batch berjalan, sedangkan request
+// biasa memegang read lock (lihat withGate), jadi tidak ada yang menyela di
+// tengah batch.
+type BatchHandler struct {
+	store *UserStore
+	audit *AuditLog
+	next  http.Handler // mux tanpa /batch
+	gate  *sync.RWMutex
+}
+
+func NewBatchHandler(store *UserStore, audit *AuditLog, next http.Handler, gate *sync.RWMutex) *BatchHandler {
+	return &BatchHandler{store: store, audit: audit, next: next, gate: gate}
+}
+
+// POST /batch
+func (h *BatchHandler) HandleBatch(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	if err := authorizeRequest(r, actionBulk); err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	req, err := decodeJSON[batchRequest](w, r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	h.gate.Lock()
+	defer h.gate.Unlock()
+
+	snap := h.store.snapshot()
+	// audit event operasi ditahan sampai batch commit, rollback = dibuang
+	pending := &auditBuffer{}
+	ctx := withAuditBuffer(r.Context(), pending)
+
+	results := make([]batchResult, len(req.Operations))
+	var datas []any // data tiap operasi yang sukses, untuk placeholder
+	failed := -1
+
+	for i, op := range req.Operations {
+		path, body, err := resolveBatchRefs(op, datas)
+		if err == nil && !batchAllowed(strings.ToUpper(op.Method), path) {
+			err = fmt.Errorf("%s %s is not allowed in a batch", op.Method, truncatePath(path))
+		}
+		if err != nil {
+			results[i] = batchResult{Status: http.StatusBadRequest, Body: errorBody("invalid_batch_reference", err.Error(), nil)}
+			failed = i
+			break
+		}
+
+		sub, err := http.NewRequestWithContext(ctx, strings.ToUpper(op.Method), path, bytes.NewReader(body))
+		if err != nil {
+			results[i] = batchResult{Status: http.StatusBadRequest, Body: errorBody("invalid_path", err.Error(), nil)}
+			failed = i
+			break
+		}
+		sub.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		h.next.ServeHTTP(rec, sub)
+
+		var respBody map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+		results[i] = batchResult{Status: rec.Code, Body: respBody}
+
+		if rec.Code >= 400 {
+			failed = i
+			break
+		}
+		datas = append(datas, respBody["data"])
+	}
+
+	if failed < 0 {
+		h.audit.commit(pending)
+		writeData(w, http.StatusOK, results, apiResponse{
+			"committed": true,
+			"count":     len(results),
+		})
+		return
+	}
+
+	// ada yang gagal: kembalikan store ke kondisi sebelum batch
+	h.store.restoreSnapshot(snap)
+	for i := range results {
+		switch {
+		case i < failed:
+			results[i].Error = "rolled back"
+		case i > failed:
+			results[i] = batchResult{Error: "not executed"}
+		}
+	}
+
+	writeData(w, results[failed].Status, results, apiResponse{
+		"committed":   false,
+		"count":       len(results),
+		"failedIndex": failed,
+	})
+}
+
+// resolveBatchRefs mengganti "$N.field" di path dan di string body
+// dengan nilai dari data operasi ke-N.
+func resolveBatchRefs(op batchOperation, datas []any) (string, []byte, error) {
+	var refErr error
+	replace := func(ref string) string {
+		m := batchRefPattern.FindStringSubmatch(ref)
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 || n > len(datas) {
+			refErr = fmt.Errorf("%s refers to an operation that has not run", ref)
+			return ref
+		}
+		data, _ := datas[n-1].(map[string]any)
+		v, ok := data[m[2]]
+		if !ok {
+			refErr = fmt.Errorf("%s: operation %d has no field %q", ref, n, m[2])
+			return ref
+		}
+		switch v := v.(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			refErr = fmt.Errorf("%s is not a string or number", ref)
+			return ref
+		}
+	}
+
+	path := batchRefPattern.ReplaceAllStringFunc(op.Path, replace)
+
+	body := op.Body
+	if len(body) > 0 && batchRefPattern.Match(body) {
+		var v any
+		_ = json.Unmarshal(body, &v)
+		v = replaceBatchRefsInJSON(v, replace)
+		body, _ = json.Marshal(v)
+	}
+	return path, body, refErr
+}
+
+func replaceBatchRefsInJSON(v any, replace func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return batchRefPattern.ReplaceAllStringFunc(v, replace)
+	case map[string]any:
+		for k, child := range v {
+			v[k] = replaceBatchRefsInJSON(child, replace)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = replaceBatchRefsInJSON(child, replace)
+		}
+	}
+	return v
+}
+
+// withGate: request biasa memegang read lock gate, supaya tidak jalan
+// bersamaan dengan batch yang sedang berjalan. Export streaming (NDJSON/SSE,
+// tanpa -request-timeout) hanya memegangnya sampai response mulai ditulis:
+// snapshot data sudah diambil, dan export lambat tidak boleh membuat batch
+// yang menunggu write lock menahan semua request lain.
+func withGate(gate *sync.RWMutex, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gate.RLock()
+		if !streamingRequest(r) {
+			defer gate.RUnlock()
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gateWriter{ResponseWriter: w, release: sync.OnceFunc(gate.RUnlock)}
+		defer gw.release()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// gateWriter melepas read lock gate saat byte pertama (atau header) ditulis
+type gateWriter struct {
+	http.ResponseWriter
+	release func()
+}
+
+func (gw *gateWriter) WriteHeader(status int) {
+	gw.release()
+	gw.ResponseWriter.WriteHeader(status)
+}
+
+func (gw *gateWriter) Write(p []byte) (int, error) {
+	gw.release()
+	return gw.ResponseWriter.Write(p)
+}
+
+func (gw *gateWriter) Flush() {
+	gw.release()
+	_ = http.NewResponseController(gw.ResponseWriter).Flush()
+}
+
+func (gw *gateWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
@@ -0,0 +1,59 @@
+// File: /batch_test.go
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// export NDJSON melepas gate begitu mulai menulis, request biasa memegangnya
+// sampai selesai
+func TestGateStreamingRelease(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantLocked bool
+	}{
+		{"json", "/users", true},
+		{"ndjson export", "/users?format=ndjson", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := &sync.RWMutex{}
+			writing, done := make(chan struct{}), make(chan struct{})
+			h := withGate(gate, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// handler sudah memegang gate: batch harus menunggu
+				if gate.TryLock() {
+					t.Error("batch got the gate before the response started")
+				}
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte("{}\n"))
+				close(writing)
+				<-done // export lambat
+			}))
+
+			served := make(chan struct{})
+			go func() {
+				h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+				close(served)
+			}()
+			<-writing
+			locked := !gate.TryLock()
+			if !locked {
+				gate.Unlock()
+			}
+			close(done)
+			<-served
+
+			if locked != tt.wantLocked {
+				t.Fatalf("gate held while streaming = %t, want %t", locked, tt.wantLocked)
+			}
+			// setelah handler selesai gate selalu bebas (read lock tidak dilepas dua kali)
+			if !gate.TryLock() {
+				t.Fatal("gate still held after the request")
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// File: /body_log.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// logBodyMaxBytes = batas body request/response yang disalin ke log
+const logBodyMaxBytes = 4096
+
+// logBodies (-log-bodies) mencatat body request dan response di level debug.
+// Body request disalin saat handler membacanya (setelah limitBody), jadi
+// readJSON tetap melihat stream yang sama. Bisa berisi PII: default mati.
+// redact = field tambahan (-log-bodies-redact, mis. email) yang disamarkan
+// seperti secretBodyFields.
+func logBodies(enabled bool, redact []string, next http.Handler) http.Handler {
+	if !enabled {
+		return next
+	}
+	fields := maps.Clone(secretBodyFields)
+	for _, name := range redact {
+		fields[name] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !logEnabled(levelDebug) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		reqBody := &cappedBuffer{max: logBodyMaxBytes}
+		if r.Body != nil {
+			r.Body = &teeBody{ReadCloser: r.Body, copy: reqBody}
+		}
+		rec := &bodyRecorder{ResponseWriter: w, body: &cappedBuffer{max: logBodyMaxBytes}}
+
+		next.ServeHTTP(rec, r)
+
+		requestLog(r.Context()).Debug("bodies",
+			"request_body", requestBodyForLog(r, reqBody, fields),
+			"response_body", redactFields(rec.body.String(), fields),
+		)
+	})
+}
+
+// requestBodyForLog: hanya body JSON yang disalin (setelah redactFields).
+// Body lain (CSV import, multipart, form) bisa berisi password tanpa nama
+// field JSON, jadi hanya dicatat jenis dan ukurannya.
+func requestBodyForLog(r *http.Request, body *cappedBuffer, fields map[string]bool) string {
+	if body.total == 0 {
+		return ""
+	}
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mt != "application/json" && !strings.HasSuffix(mt, "+json")) {
+		if mt == "" {
+			mt = "unknown"
+		}
+		return "<" + mt + " " + strconv.Itoa(body.total) + " bytes>"
+	}
+	return redactFields(body.String(), fields)
+}
+
+// secretBodyFields tidak pernah ditulis ke log, walau -log-bodies aktif
+var secretBodyFields = map[string]bool{"password": true, "currentPassword": true, "newPassword": true}
+
+// redactFields mengganti nilai field di fields (di kedalaman mana pun, jadi
+// juga di dalam "data" response) dengan "[redacted]". Body yang bukan satu
+// nilai JSON utuh (terpotong, NDJSON, teks) tapi menyebut salah satu field
+// tidak dicatat sama sekali.
+func redactFields(body string, fields map[string]bool) string {
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() || strings.TrimSpace(body[dec.InputOffset():]) != "" {
+		for name := range fields {
+			if strings.Contains(body, `"`+name+`"`) {
+				return "<redacted " + strconv.Itoa(len(body)) + " bytes>"
+			}
+		}
+		return body
+	}
+
+	if !redactValue(v, fields) {
+		return body
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
+
+// redactValue mengubah v di tempat, true = ada yang disamarkan
+func redactValue(v any, fields map[string]bool) bool {
+	redacted := false
+	switch t := v.(type) {
+	case map[string]any:
+		for name, child := range t {
+			if fields[name] {
+				t[name] = "[redacted]"
+				redacted = true
+			} else if redactValue(child, fields) {
+				redacted = true
+			}
+		}
+	case []any:
+		for _, child := range t {
+			if redactValue(child, fields) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// cappedBuffer menyimpan paling banyak max byte, sisanya hanya dihitung
+type cappedBuffer struct {
+	max   int
+	buf   bytes.Buffer
+	total int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	if room := b.max - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
+
+// String = isi untuk log: body biner diganti ringkasan, body terpotong diberi penanda
+func (b *cappedBuffer) String() string {
+	if b.total == 0 {
+		return ""
+	}
+
+	data := b.buf.Bytes()
+	if b.total > len(data) {
+		// potongan terakhir bisa memotong rune UTF-8 di tengah
+		for len(data) > 0 && !utf8.Valid(data) {
+			data = data[:len(data)-1]
+		}
+	}
+	if isBinary(data) {
+		return "<binary " + strconv.Itoa(b.total) + " bytes>"
+	}
+	if b.total > len(data) {
+		return string(data) + "...[truncated, " + strconv.Itoa(b.total) + " bytes total]"
+	}
+	return string(data)
+}
+
+func isBinary(data []byte) bool {
+	if !utf8.Valid(data) {
+		return true
+	}
+	for _, r := range string(data) {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return true
+		}
+	}
+	return false
+}
+
+type teeBody struct {
+	io.ReadCloser
+	copy *cappedBuffer
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	_, _ = t.copy.Write(p[:n])
+	return n, err
+}
+
+// bodyRecorder menyalin body response ke body sambil tetap menulis ke client
+type bodyRecorder struct {
+	http.ResponseWriter
+	body *cappedBuffer
+}
+
+func (rec *bodyRecorder) Write(p []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(p)
+	_, _ = rec.body.Write(p[:n])
+	return n, err
+}
+
+func (rec *bodyRecorder) Flush() {
+	_ = http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+func (rec *bodyRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
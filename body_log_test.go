@@ -0,0 +1,116 @@
+// File: /body_log_test.go
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// bodyLogs = semua record "bodies" dari log
+func bodyLogs(t *testing.T, logs *logBuffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, rec := range logs.Records(t) {
+		if rec["msg"] == "bodies" {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+func TestLogBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     bool
+		redact      []string
+		level       logLevel
+		wantRequest string // "" = tidak ada record "bodies"
+		wantInResp  string
+	}{
+		{"enabled", true, nil, levelDebug,
+			`{"name":"Ada","password":"[redacted]"}`, `"name":"Ada"`},
+		{"enabled with redact", true, []string{"name"}, levelDebug,
+			`{"name":"[redacted]","password":"[redacted]"}`, `"name":"[redacted]"`},
+		{"disabled", false, nil, levelDebug, "", ""},
+		{"enabled below debug", true, nil, levelInfo, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t, tt.level)
+			ts := newTestServer(t, testConfig(func(c *Config) {
+				c.LogBodies = tt.enabled
+				c.LogBodiesRedact = tt.redact
+			}))
+			res, _ := doRequest(t, ts, "POST", "/users", `{"name":"Ada","password":"secret123"}`)
+			if res.StatusCode != http.StatusCreated {
+				t.Fatalf("status %d", res.StatusCode)
+			}
+
+			records := bodyLogs(t, logs)
+			if tt.wantRequest == "" {
+				if len(records) != 0 {
+					t.Fatalf("bodies logged: %v", records)
+				}
+				return
+			}
+			if len(records) != 1 {
+				t.Fatalf("got %d bodies records, want 1", len(records))
+			}
+			rec := records[0]
+			if rec["request_id"] != res.Header.Get("X-Request-Id") {
+				t.Errorf("request_id %v, want %s", rec["request_id"], res.Header.Get("X-Request-Id"))
+			}
+			if rec["request_body"] != tt.wantRequest {
+				t.Errorf("request_body %v, want %s", rec["request_body"], tt.wantRequest)
+			}
+			resp, _ := rec["response_body"].(string)
+			if !strings.Contains(resp, tt.wantInResp) || !strings.Contains(resp, `"id":1`) {
+				t.Errorf("response_body %q, want it to contain %s", resp, tt.wantInResp)
+			}
+			if strings.Contains(resp+rec["request_body"].(string), "secret123") {
+				t.Errorf("password in log: %v", rec)
+			}
+		})
+	}
+}
+
+// body non-JSON hanya dicatat jenis dan ukurannya, body besar dipotong
+func TestLogBodiesNonJSONAndLarge(t *testing.T) {
+	logs := captureLog(t, levelDebug)
+	ts := newTestServer(t, testConfig(func(c *Config) { c.LogBodies = true }))
+
+	doRequest(t, ts, "POST", "/users/import", "name,password\nAda,secret123\n", "Content-Type", "text/csv")
+	doRequest(t, ts, "POST", "/users", `{"name":"`+strings.Repeat("x", 2*logBodyMaxBytes)+`"}`)
+
+	records := bodyLogs(t, logs)
+	if len(records) != 2 {
+		t.Fatalf("got %d bodies records, want 2", len(records))
+	}
+	if got := records[0]["request_body"]; got != "<text/csv 28 bytes>" {
+		t.Errorf("csv request_body %v", got)
+	}
+	got, _ := records[1]["request_body"].(string)
+	if !strings.HasSuffix(got, "...[truncated, 8203 bytes total]") || len(got) > logBodyMaxBytes+50 {
+		t.Errorf("large request_body (%d bytes) ends %q", len(got), got[max(0, len(got)-40):])
+	}
+}
+
+func TestRedactFields(t *testing.T) {
+	fields := map[string]bool{"password": true, "email": true}
+	tests := []struct{ in, want string }{
+		{`{"name":"Ada"}`, `{"name":"Ada"}`},
+		{`{"email":"a@x","name":"Ada"}`, `{"email":"[redacted]","name":"Ada"}`},
+		{`{"data":[{"email":"a@x","n":1}]}`, `{"data":[{"email":"[redacted]","n":1}]}`},
+		// body yang bukan satu nilai JSON utuh tapi menyebut field: tidak dicatat
+		{`{"password":"secr`, `<redacted 17 bytes>`},
+		{"{\"email\":\"a\"}\n{\"email\":\"b\"}\n", `<redacted 28 bytes>`},
+		{`not json`, `not json`},
+		{``, ``},
+	}
+	for _, tt := range tests {
+		if got := redactFields(tt.in, fields); got != tt.want {
+			t.Errorf("redactFields(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// File: /cache_control.go
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// cacheClass = jenis response dari sisi cache. Handler hanya memilih kelas,
+// header sebenarnya ditentukan cachePolicy (-cache-control, -cache-max-age).
+type cacheClass int
+
+const (
+	// cacheNoStore = default: error, mutasi, /time, /health, dll.
+	cacheNoStore cacheClass = iota
+	// cacheRevalidate = koleksi: boleh disimpan tapi wajib revalidasi (ETag)
+	cacheRevalidate
+	// cachePrivate = baca satu user: cache browser saja selama max-age
+	cachePrivate
+)
+
+type cachePolicy struct {
+	enabled bool
+	maxAge  time.Duration
+}
+
+// header = nilai Cache-Control untuk kelas c
+func (p cachePolicy) header(c cacheClass) string {
+	if !p.enabled {
+		return "no-store"
+	}
+	switch c {
+	case cacheRevalidate:
+		return "no-cache"
+	case cachePrivate:
+		if secs := int(p.maxAge / time.Second); secs > 0 {
+			return "private, max-age=" + strconv.Itoa(secs)
+		}
+		return "private, no-cache"
+	}
+	return "no-store"
+}
+
+type cachePolicyKey struct{}
+
+// withCacheControl memasang Cache-Control: no-store di semua response lalu
+// menyimpan kebijakan di context untuk setCache. enabled=false = semua
+// response tetap no-store, apa pun kelas yang dipilih handler.
+func withCacheControl(enabled bool, maxAge time.Duration, next http.Handler) http.Handler {
+	p := cachePolicy{enabled: enabled, maxAge: maxAge}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", p.header(cacheNoStore))
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), cachePolicyKey{}, p)))
+	})
+}
+
+// setCache dipanggil handler tepat sebelum response sukses (juga sebelum
+// notModified, supaya 304 membawa header yang sama). errorJSON selalu
+// mengembalikan no-store, jadi error setelahnya tidak pernah di-cache.
+func setCache(w http.ResponseWriter, r *http.Request, c cacheClass) {
+	p, _ := r.Context().Value(cachePolicyKey{}).(cachePolicy)
+	w.Header().Set("Cache-Control", p.header(c))
+}
@@ -0,0 +1,69 @@
+// File: /cache_control_test.go
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Cache-Control persis per kelas response, untuk setiap konfigurasi cache
+func TestCacheControlClasses(t *testing.T) {
+	requests := []struct {
+		class              string
+		method, path, body string
+		header             []string
+	}{
+		{"user read", "GET", "/users/1", "", nil},
+		{"user read", "HEAD", "/users/1", "", nil},
+		{"user read", "GET", "/users/1/profile", "", nil},
+		{"user read", "GET", "/users/1", "", []string{"If-None-Match", "*"}},
+		{"collection", "GET", "/users", "", nil},
+		{"collection", "GET", "/users/exists?name=Ada", "", nil},
+		{"collection", "GET", "/users", "", []string{"If-None-Match", "*"}},
+		{"mutation", "POST", "/users", `{"name":"Grace"}`, nil},
+		{"mutation", "PATCH", "/users/1", `{"name":"Ada L."}`, nil},
+		{"mutation", "DELETE", "/users/2", "", nil},
+		{"error", "GET", "/users/99", "", nil},
+		{"error", "GET", "/users/abc", "", nil},
+		{"error", "POST", "/users", `{"name":""}`, nil},
+		{"error", "GET", "/nope", "", nil},
+		{"other", "GET", "/time", "", nil},
+		{"other", "GET", "/health", "", nil},
+	}
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		want   map[string]string
+	}{
+		{"defaults", nil, map[string]string{
+			"user read":  "private, max-age=5",
+			"collection": "no-cache",
+		}},
+		{"max-age 0", func(c *Config) { c.CacheMaxAge = 0 }, map[string]string{
+			"user read":  "private, no-cache",
+			"collection": "no-cache",
+		}},
+		{"max-age 1m", func(c *Config) { c.CacheMaxAge = time.Minute }, map[string]string{
+			"user read":  "private, max-age=60",
+			"collection": "no-cache",
+		}},
+		// -cache-control=false: no-store di mana-mana
+		{"disabled", func(c *Config) { c.CacheControl = false }, map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, testConfig(tt.mutate))
+			doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+			for _, req := range requests {
+				want, ok := tt.want[req.class]
+				if !ok {
+					want = "no-store" // error, mutasi, dan response lain
+				}
+				res, _ := doRequest(t, ts, req.method, req.path, req.body, req.header...)
+				if got := res.Header.Get("Cache-Control"); got != want {
+					t.Errorf("%s %s (%s, status %d): Cache-Control %q, want %q", req.method, req.path, req.class, res.StatusCode, got, want)
+				}
+			}
+		})
+	}
+}
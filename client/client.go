@@ -0,0 +1,213 @@
+// File: /client/client.go
+
+// Package client = SDK kecil untuk REST API ini: memanggil endpoint /users,
+// decode envelope {"data","meta"} dan error {"error","message","details"}.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Client struct {
+	// BaseURL mis. "http://localhost:8080" (tanpa slash di akhir juga boleh)
+	BaseURL string
+	// HTTPClient nil = http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// New = Client untuk baseURL; httpClient nil = http.DefaultClient
+func New(baseURL string, httpClient *http.Client) *Client {
+	return &Client{BaseURL: baseURL, HTTPClient: httpClient}
+}
+
+// AppError = error response API ({"error","message","details"}) plus status HTTP
+type AppError struct {
+	Status  int
+	Code    string
+	Message string
+	Details any
+}
+
+func (e *AppError) Error() string {
+	return fmt.Sprintf("%s: %s (HTTP %d)", e.Code, e.Message, e.Status)
+}
+
+// UserID bisa angka (mode int) atau UUID; selalu disimpan sebagai string
+type UserID string
+
+func (id *UserID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = UserID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("user id must be a number or a string")
+	}
+	*id = UserID(n.String())
+	return nil
+}
+
+type User struct {
+	ID           UserID     `json:"id"`
+	Name         string     `json:"name"`
+	Role         string     `json:"role"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	LastActiveAt time.Time  `json:"lastActiveAt"`
+	DeletedAt    *time.Time `json:"deletedAt,omitempty"`
+}
+
+// ListOptions = query GET /users; nilai kosong tidak dikirim
+type ListOptions struct {
+	Q              string
+	Sort           string // "name" atau "-name" untuk descending
+	Limit          int
+	Offset         int
+	IncludeDeleted bool
+}
+
+// UserList = satu halaman user plus total setelah filter
+type UserList struct {
+	Users []User
+	Total int
+}
+
+func (c *Client) CreateUser(ctx context.Context, name string) (User, error) {
+	var u User
+	err := c.do(ctx, http.MethodPost, "/users", map[string]string{"name": name}, &u, nil)
+	return u, err
+}
+
+func (c *Client) GetUser(ctx context.Context, id UserID) (User, error) {
+	var u User
+	err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(string(id)), nil, &u, nil)
+	return u, err
+}
+
+func (c *Client) ListUsers(ctx context.Context, opts ListOptions) (UserList, error) {
+	q := url.Values{}
+	if opts.Q != "" {
+		q.Set("q", opts.Q)
+	}
+	if opts.Sort != "" {
+		q.Set("sort", opts.Sort)
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		q.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if opts.IncludeDeleted {
+		q.Set("includeDeleted", "true")
+	}
+
+	path := "/users"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	var (
+		list UserList
+		meta struct {
+			Total int `json:"total"`
+		}
+	)
+	if err := c.do(ctx, http.MethodGet, path, nil, &list.Users, &meta); err != nil {
+		return UserList{}, err
+	}
+	list.Total = meta.Total
+	return list, nil
+}
+
+func (c *Client) DeleteUser(ctx context.Context, id UserID) error {
+	return c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(string(id)), nil, nil, nil)
+}
+
+// do kirim request JSON; response 2xx di-decode ke data/meta (boleh nil),
+// selain itu jadi *AppError
+func (c *Client) do(ctx context.Context, method, path string, body, data, meta any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.BaseURL, "/")+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+
+	var env struct {
+		Data json.RawMessage `json:"data"`
+		Meta json.RawMessage `json:"meta"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	if data != nil {
+		if err := json.Unmarshal(env.Data, data); err != nil {
+			return fmt.Errorf("%s %s: decode data: %w", method, path, err)
+		}
+	}
+	if meta != nil && len(env.Meta) > 0 {
+		if err := json.Unmarshal(env.Meta, meta); err != nil {
+			return fmt.Errorf("%s %s: decode meta: %w", method, path, err)
+		}
+	}
+	return nil
+}
+
+// decodeError: body yang bukan envelope error (mis. dari proxy) tetap jadi AppError
+func decodeError(resp *http.Response) error {
+	var body struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+		Details any    `json:"details"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+		return &AppError{
+			Status:  resp.StatusCode,
+			Code:    "http_error",
+			Message: http.StatusText(resp.StatusCode),
+		}
+	}
+	return &AppError{
+		Status:  resp.StatusCode,
+		Code:    body.Error,
+		Message: body.Message,
+		Details: body.Details,
+	}
+}
@@ -0,0 +1,91 @@
+// File: /client/client_test.go
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// server palsu untuk respons yang tidak dihasilkan API sendiri (proxy, dll.)
+func fakeServer(t *testing.T, status int, contentType, body string) *Client {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+	return New(ts.URL, ts.Client())
+}
+
+func TestDecodeErrorWithoutEnvelope(t *testing.T) {
+	c := fakeServer(t, http.StatusBadGateway, "text/html", "<h1>502 Bad Gateway</h1>")
+	_, err := c.GetUser(context.Background(), "1")
+
+	var apiErr *AppError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error %v (%T)", err, err)
+	}
+	if apiErr.Status != http.StatusBadGateway || apiErr.Code != "http_error" || apiErr.Message != "Bad Gateway" {
+		t.Fatalf("error %+v", apiErr)
+	}
+	if want := "http_error: Bad Gateway (HTTP 502)"; apiErr.Error() != want {
+		t.Errorf("Error() = %q, want %q", apiErr.Error(), want)
+	}
+}
+
+func TestDecodeInvalidSuccessBody(t *testing.T) {
+	c := fakeServer(t, http.StatusOK, "application/json", `{"data":`)
+	_, err := c.GetUser(context.Background(), "1")
+	if err == nil || !strings.Contains(err.Error(), "GET /users/1: decode response") {
+		t.Fatalf("error %v", err)
+	}
+}
+
+func TestUserIDAcceptsNumberOrString(t *testing.T) {
+	for _, body := range []string{`{"data":{"id":7,"name":"a"}}`, `{"data":{"id":"7","name":"a"}}`} {
+		c := fakeServer(t, http.StatusOK, "application/json", body)
+		u, err := c.GetUser(context.Background(), "7")
+		if err != nil || u.ID != "7" {
+			t.Errorf("%s: %+v, %v", body, u, err)
+		}
+	}
+
+	c := fakeServer(t, http.StatusOK, "application/json", `{"data":{"id":true}}`)
+	if _, err := c.GetUser(context.Background(), "7"); err == nil {
+		t.Error("boolean id accepted")
+	}
+}
+
+func TestListUsersQuery(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.RequestURI()
+		_, _ = w.Write([]byte(`{"data":[],"meta":{"total":0}}`))
+	}))
+	defer ts.Close()
+	c := &Client{BaseURL: ts.URL}
+
+	if _, err := c.ListUsers(context.Background(), ListOptions{}); err != nil || got != "/users" {
+		t.Fatalf("empty options: %q, %v", got, err)
+	}
+	opts := ListOptions{Q: "a b", Sort: "-name", Limit: 5, Offset: 10, IncludeDeleted: true}
+	if _, err := c.ListUsers(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := "/users?includeDeleted=true&limit=5&offset=10&q=a+b&sort=-name"; got != want {
+		t.Fatalf("query %q, want %q", got, want)
+	}
+}
+
+func TestContextCanceled(t *testing.T) {
+	c := fakeServer(t, http.StatusOK, "application/json", `{"data":{}}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetUser(ctx, "1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error %v, want context.Canceled", err)
+	}
+}
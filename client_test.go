@@ -0,0 +1,85 @@
+// File: /client_test.go
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"golang-beginner-restapi/client"
+)
+
+// TestClientAgainstServer: SDK di client/ terhadap server asli (semua middleware)
+func TestClientAgainstServer(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	c := &client.Client{BaseURL: ts.URL + "/", HTTPClient: ts.Client()}
+	ctx := context.Background()
+
+	ada, err := c.CreateUser(ctx, "Ada")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if ada.ID != "1" || ada.Name != "Ada" || ada.Role != string(RoleUser) || ada.CreatedAt.IsZero() {
+		t.Fatalf("created %+v", ada)
+	}
+	if _, err := c.CreateUser(ctx, "Grace"); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	got, err := c.GetUser(ctx, ada.ID)
+	if err != nil || got.ID != ada.ID || got.Name != "Ada" {
+		t.Fatalf("GetUser: %+v, %v", got, err)
+	}
+
+	list, err := c.ListUsers(ctx, client.ListOptions{Sort: "-name", Limit: 1})
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if list.Total != 2 || len(list.Users) != 1 || list.Users[0].Name != "Grace" {
+		t.Fatalf("ListUsers: %+v", list)
+	}
+	if list, err = c.ListUsers(ctx, client.ListOptions{Q: "ad"}); err != nil || list.Total != 1 || list.Users[0].ID != ada.ID {
+		t.Fatalf("ListUsers q=ad: %+v, %v", list, err)
+	}
+
+	if err := c.DeleteUser(ctx, ada.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	_, err = c.GetUser(ctx, ada.ID)
+	var apiErr *client.AppError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || apiErr.Code != "not_found" || apiErr.Message == "" {
+		t.Fatalf("GetUser after delete: %v", err)
+	}
+}
+
+func TestClientMapsValidationErrors(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	c := &client.Client{BaseURL: ts.URL, HTTPClient: ts.Client()}
+
+	_, err := c.CreateUser(context.Background(), "   ")
+	var apiErr *client.AppError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error %v (%T), want *client.AppError", err, err)
+	}
+	details, _ := apiErr.Details.([]any)
+	if apiErr.Status != http.StatusBadRequest || apiErr.Code != "validation_failed" || len(details) == 0 {
+		t.Fatalf("error %+v", apiErr)
+	}
+}
+
+func TestClientUUIDMode(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.IDMode = IDModeUUID }))
+	c := &client.Client{BaseURL: ts.URL, HTTPClient: ts.Client()}
+
+	u, err := c.CreateUser(context.Background(), "Ada")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(u.ID) != 36 {
+		t.Fatalf("id %q is not a UUID", u.ID)
+	}
+	if got, err := c.GetUser(context.Background(), u.ID); err != nil || got.ID != u.ID {
+		t.Fatalf("GetUser: %+v, %v", got, err)
+	}
+}
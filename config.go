@@ -0,0 +1,734 @@
+// File: /config.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Config = semua setting yang dibutuhkan untuk membangun server
+type Config struct {
+	// Listen: alamat host:port, boleh lebih dari satu. Kosong = ":" + Port.
+	Listen []string `json:"listen"`
+	Port   int      `json:"port"`
+
+	// Store: backend penyimpanan user (saat ini hanya "memory")
+	Store      string `json:"store"`
+	IDMode     IDMode `json:"idMode"`
+	SoftDelete bool   `json:"softDelete"`
+	// BcryptCost: cost bcrypt untuk password user; rendah (4) hanya untuk test
+	BcryptCost int `json:"bcryptCost"`
+	// AllowReset: daftarkan POST /admin/reset (hapus semua user), hanya untuk testing
+	AllowReset bool `json:"allowReset"`
+	// AllowMethodOverride: POST + X-HTTP-Method-Override: PUT|PATCH|DELETE
+	// dijalankan sebagai method itu (client lama yang hanya bisa GET/POST)
+	AllowMethodOverride bool `json:"allowMethodOverride"`
+	// APIKeys / APIKeysFile: entry "<key> [label] [role=admin|user] [user=<id>]". Ada key = POST/PUT/PATCH/DELETE
+	// butuh X-API-Key; AuthReads = GET juga. AuthOpenPaths selalu terbuka.
+	APIKeys       []string `json:"apiKeys" secret:"true"`
+	APIKeysFile   string   `json:"apiKeysFile"`
+	AuthReads     bool     `json:"authReads"`
+	AuthOpenPaths []string `json:"authOpenPaths"`
+	// SignatureSecret: shared secret HMAC untuk X-Signature/X-Timestamp.
+	// Request bertanda tangan valid tidak butuh X-API-Key. Kosong = mati.
+	SignatureSecret string `json:"signatureSecret" secret:"true"`
+
+	// AdminAllow/AdminDeny: CIDR (atau IP) yang boleh / tidak boleh mengakses
+	// /admin/* dan /debug/pprof/. Allow kosong = semua yang tidak di-deny.
+	AdminAllow []string `json:"adminAllow"`
+	AdminDeny  []string `json:"adminDeny"`
+	// AdminUser/AdminPasswordHash: Basic auth untuk /admin/* dan /debug/pprof/
+	// (hash bcrypt). Kosong = route admin tidak didaftarkan sama sekali.
+	AdminUser         string `json:"adminUser"`
+	AdminPasswordHash string `json:"adminPasswordHash" secret:"true"`
+	// SchemaValidation: body POST /users juga dicek terhadap schema CreateUserRequest di openapi.json
+	SchemaValidation bool `json:"schemaValidation"`
+	// Pprof: daftarkan net/http/pprof di /debug/pprof/ (jangan dibuka ke publik),
+	// hanya bersama AdminUser/AdminPasswordHash
+	Pprof bool `json:"pprof"`
+
+	// AuditLogSize: jumlah event audit terakhir yang disimpan (0 = mati)
+	AuditLogSize int `json:"auditLogSize"`
+
+	// SeedFixture: nama dataset fixture yang di-install saat start (kosong = tanpa seed)
+	SeedFixture string `json:"seedFixture"`
+	// Seed: jumlah user placeholder (user-1..user-N) yang dibuat saat start;
+	// SeedFile: file array JSON [{"name": ...}]. Keduanya setelah SeedFixture.
+	Seed     int    `json:"seed"`
+	SeedFile string `json:"seedFile"`
+
+	// probe store saat start: timeout per percobaan dan jumlah percobaan ulang
+	StoreProbeTimeout time.Duration `json:"storeProbeTimeout"`
+	StoreProbeRetries int           `json:"storeProbeRetries"`
+
+	MaxPathBytes  int   `json:"maxPathBytes"`
+	MaxQueryBytes int   `json:"maxQueryBytes"`
+	MaxBodyBytes  int64 `json:"maxBodyBytes"`
+
+	// MaxInflight: batas request yang diproses bersamaan (0 = tanpa batas).
+	// Request di atas batas menunggu sampai InflightQueueTimeout lalu 503.
+	MaxInflight          int           `json:"maxInflight"`
+	InflightQueueTimeout time.Duration `json:"inflightQueueTimeout"`
+
+	// timeout http.Server, 0 = tanpa batas
+	ReadTimeout       time.Duration `json:"readTimeout"`
+	ReadHeaderTimeout time.Duration `json:"readHeaderTimeout"`
+	WriteTimeout      time.Duration `json:"writeTimeout"`
+	IdleTimeout       time.Duration `json:"idleTimeout"`
+	// RequestTimeout: deadline context per request, lewat batas = 504 JSON.
+	// Harus lebih kecil dari WriteTimeout supaya 504 masih sempat terkirim.
+	RequestTimeout time.Duration `json:"requestTimeout"`
+	// IdempotencyTTL: lama response POST /users disimpan per Idempotency-Key
+	// (0 = header diabaikan)
+	IdempotencyTTL time.Duration `json:"idempotencyTTL"`
+
+	// HTTPS: aktif kalau TLSCert dan TLSKey diisi
+	TLSCert string `json:"tlsCert"`
+	TLSKey  string `json:"tlsKey"`
+	// TLSClientCA: bundle PEM CA untuk mTLS, koneksi tanpa sertifikat client
+	// yang valid ditolak saat handshake. TLSClientOptional = sertifikat opsional,
+	// tapi POST/PUT/PATCH/DELETE tetap wajib sertifikat.
+	TLSClientCA       string `json:"tlsClientCA"`
+	TLSClientOptional bool   `json:"tlsClientOptional"`
+	// RedirectHTTP: alamat listener HTTP kedua yang redirect ke HTTPS (mis. ":8081")
+	RedirectHTTP string `json:"redirectHTTP"`
+
+	// autocert (Let's Encrypt): HTTPS di :443, HTTP-01 challenge di :80.
+	// Tidak bisa digabung dengan TLSCert/TLSKey.
+	AutocertDomain string `json:"autocertDomain"` // satu atau lebih domain, dipisah koma
+	AutocertCache  string `json:"autocertCache"`  // direktori cache sertifikat
+
+	// SecurityHeadersEnabled: kirim header keamanan default (nosniff, DENY, CSP, ...).
+	// SecurityHeaders = override per header "Name: value", "Name:" mematikan header.
+	SecurityHeadersEnabled bool     `json:"securityHeadersEnabled"`
+	SecurityHeaders        []string `json:"securityHeaders"`
+	// ServerHeader: nilai header Server, kosong = tidak dikirim
+	ServerHeader string `json:"serverHeader"`
+	// CacheControl: false = semua response no-store (cache dimatikan total).
+	// CacheMaxAge = max-age baca satu user (private), 0 = selalu revalidasi.
+	CacheControl bool          `json:"cacheControl"`
+	CacheMaxAge  time.Duration `json:"cacheMaxAge"`
+	// Pretty: response JSON di-indent 2 spasi (default compact),
+	// bisa ditimpa per request dengan ?pretty=true / ?pretty=false
+	Pretty bool `json:"pretty"`
+
+	// UnixSocket: kalau diisi, server listen di socket ini dan Port diabaikan
+	UnixSocket string `json:"unixSocket"`
+	SocketMode string `json:"socketMode"` // permission file socket, oktal (mis. "0660")
+
+	// LogRawPath: log juga path asli selain route pattern (matikan untuk privasi)
+	LogRawPath bool `json:"logRawPath"`
+	// LogLevel: debug, info, warn, error (debug = log per request + body)
+	LogLevel string `json:"logLevel"`
+	// SlowRequestThreshold: request yang lebih lama dicatat WARN (0 = mati)
+	SlowRequestThreshold time.Duration `json:"slowRequestThreshold"`
+	// LogBodies: log body request/response (dipotong) di level debug.
+	// Bisa berisi data pribadi, jangan nyalakan di production.
+	LogBodies bool `json:"logBodies"`
+	// LogBodiesRedact: nama field tambahan (mis. email) yang disamarkan di log body;
+	// password selalu disamarkan
+	LogBodiesRedact []string `json:"logBodiesRedact"`
+	// LogFormat: text (default, untuk development) atau json
+	LogFormat string `json:"logFormat"`
+
+	// AccessLog: file untuk record per request (kosong = log aplikasi di stderr),
+	// diputar setelah AccessLogMaxBytes, AccessLogKeep file lama disimpan
+	AccessLog         string `json:"accessLog"`
+	AccessLogMaxBytes int64  `json:"accessLogMaxBytes"`
+	AccessLogKeep     int    `json:"accessLogKeep"`
+	// AccessLogFormat: common, combined, json, dev; kosong = record slog.
+	// Diisi tanpa AccessLog = ditulis ke stdout.
+	AccessLogFormat string `json:"accessLogFormat"`
+
+	// BasePath: prefix path untuk link _links (mis. "/api" di belakang proxy)
+	BasePath string `json:"basePath"`
+
+	// ConfigFile: file -config yang dipakai (kosong = tanpa file)
+	ConfigFile string `json:"-"`
+	// PrintConfig: cetak config efektif sebagai JSON lalu keluar
+	PrintConfig bool `json:"-"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Port:          8080,
+		Store:         "memory",
+		IDMode:        IDModeInt,
+		BcryptCost:    bcrypt.DefaultCost,
+		AuditLogSize:  100,
+		MaxPathBytes:  2048,
+		MaxQueryBytes: 2048,
+		MaxBodyBytes:  1 << 20, // 1MB
+
+		InflightQueueTimeout: 100 * time.Millisecond,
+
+		ReadTimeout:       5 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		RequestTimeout:    30 * time.Second,
+		IdempotencyTTL:    24 * time.Hour,
+
+		StoreProbeTimeout: 5 * time.Second,
+		StoreProbeRetries: 3,
+
+		SocketMode: "0660",
+
+		SecurityHeadersEnabled: true,
+		CacheControl:           true,
+		CacheMaxAge:            5 * time.Second,
+		AuthOpenPaths:          []string{"/health"},
+
+		LogRawPath: true,
+		LogLevel:   "info",
+		LogFormat:  "text",
+
+		SlowRequestThreshold: 500 * time.Millisecond,
+
+		AccessLogMaxBytes: 100 << 20, // 100MB
+		AccessLogKeep:     5,
+	}
+}
+
+// LoadConfig: default -> file -config -> environment variable -> flag (flag paling kuat).
+// lookupEnv biasanya os.LookupEnv, bisa diganti saat testing.
+func LoadConfig(args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	// flag di-parse dua kali: sekali untuk menemukan -config, sekali untuk override
+	var configFile string
+	probe := DefaultConfig()
+	if fs, _ := newConfigFlags(&probe, io.Discard); fs.Parse(args) == nil {
+		configFile = probe.ConfigFile
+	}
+	if v, ok := lookupEnv("CONFIG_FILE"); ok && configFile == "" {
+		configFile = strings.TrimSpace(v)
+	}
+
+	cfg := DefaultConfig()
+	if configFile != "" {
+		if err := cfg.applyFile(configFile); err != nil {
+			return Config{}, err
+		}
+	}
+
+	// error env tidak langsung dikembalikan, digabung dengan error Validate
+	// supaya semua nilai yang salah terlihat dalam satu pesan
+	envErr := cfg.applyEnv(lookupEnv)
+
+	fs, finish := newConfigFlags(&cfg, os.Stderr)
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+	finish()
+	cfg.ConfigFile = configFile
+
+	if err := errors.Join(envErr, cfg.Validate()); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// newConfigFlags mendaftarkan semua flag ke cfg. finish dipanggil setelah
+// Parse untuk menyalin flag yang butuh konversi (id-mode, listen).
+func newConfigFlags(cfg *Config, output io.Writer) (*flag.FlagSet, func()) {
+	fs := flag.NewFlagSet("golang-beginner-rest", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	idMode := new(string)
+	*idMode = string(cfg.IDMode)
+	listen := &stringList{}
+	var secHeaders []string
+	adminAllow, adminDeny := &stringList{}, &stringList{}
+	authOpen := &stringList{}
+	bodiesRedact := &stringList{}
+	var apiKeys []string
+
+	fs.StringVar(&cfg.ConfigFile, "config", cfg.ConfigFile, "YAML (.yaml, .yml) or JSON (.json) config file; env and flags override it (env CONFIG_FILE)")
+	fs.IntVar(&cfg.Port, "port", cfg.Port, "HTTP port for REST server, used when -listen is not set (env PORT)")
+	fs.Var(listen, "listen", "host:port to listen on; repeat the flag for several addresses (env LISTEN, comma-separated)")
+	fs.StringVar(&cfg.Store, "store", cfg.Store, "user store backend: memory (env STORE)")
+	fs.StringVar(idMode, "id-mode", string(cfg.IDMode), "user ID format: int or uuid (env ID_MODE)")
+	fs.BoolVar(&cfg.SoftDelete, "soft-delete", cfg.SoftDelete, "DELETE /users/{id} only marks users as deleted (env SOFT_DELETE)")
+	fs.IntVar(&cfg.BcryptCost, "bcrypt-cost", cfg.BcryptCost, fmt.Sprintf("bcrypt cost for user passwords, %d-%d; lower is faster and weaker (env BCRYPT_COST)", bcrypt.MinCost, bcrypt.MaxCost))
+	fs.Func("api-key", "accepted X-API-Key as \"<key> [label] [role=admin|user] [user=<id>]\" (default role admin; user= records requests as that user's activity and uses that user's role); repeatable; any key enables auth for POST/PUT/PATCH/DELETE (env API_KEYS, comma-separated)", func(v string) error {
+		apiKeys = append(apiKeys, v)
+		return nil
+	})
+	fs.StringVar(&cfg.APIKeysFile, "api-keys-file", cfg.APIKeysFile, "file with one \"<key> [label] [role=admin|user] [user=<id>]\" per line, # comments allowed (env API_KEYS_FILE)")
+	fs.BoolVar(&cfg.AuthReads, "auth-reads", cfg.AuthReads, "with API keys configured, require X-API-Key for GET/HEAD too (env AUTH_READS)")
+	fs.StringVar(&cfg.SignatureSecret, "signature-secret", cfg.SignatureSecret, "shared secret for X-Signature: sha256=<hex HMAC-SHA256 of \"<X-Timestamp>.<METHOD>.<path?query>.<body>\">; signed requests need no API key (env SIGNATURE_SECRET)")
+	fs.Var(authOpen, "auth-open-path", "path that never needs an API key; repeatable, replaces the default /health (env AUTH_OPEN_PATHS, comma-separated)")
+	fs.Var(adminAllow, "admin-allow", "CIDR or IP allowed to reach /admin/* and /debug/pprof/; repeatable, empty = any address not denied (env ADMIN_ALLOW, comma-separated)")
+	fs.Var(adminDeny, "admin-deny", "CIDR or IP refused on /admin/* and /debug/pprof/ with 403, checked before -admin-allow; repeatable (env ADMIN_DENY, comma-separated)")
+	fs.StringVar(&cfg.AdminUser, "admin-user", cfg.AdminUser, "Basic auth user for /admin/* and /debug/pprof/; admin routes are only registered with -admin-password-hash (env ADMIN_USER)")
+	fs.StringVar(&cfg.AdminPasswordHash, "admin-password-hash", cfg.AdminPasswordHash, "bcrypt hash of the admin password, e.g. from htpasswd -nbBC 10 \"\" pass (env ADMIN_PASSWORD_HASH)")
+	fs.BoolVar(&cfg.SchemaValidation, "schema-validation", cfg.SchemaValidation, "also validate POST /users bodies against the embedded OpenAPI schema, with JSON-path error details (env SCHEMA_VALIDATION)")
+	fs.BoolVar(&cfg.Pprof, "pprof", cfg.Pprof, "serve net/http/pprof profiles under /debug/pprof/; requires -admin-user and -admin-password-hash (env PPROF)")
+	fs.BoolVar(&cfg.AllowReset, "allow-reset", cfg.AllowReset, "enable POST /admin/reset, which deletes all users; for test environments only (env ALLOW_RESET)")
+	fs.BoolVar(&cfg.AllowMethodOverride, "allow-method-override", cfg.AllowMethodOverride, "run POST requests with X-HTTP-Method-Override: PUT|PATCH|DELETE as that method (env ALLOW_METHOD_OVERRIDE)")
+	fs.IntVar(&cfg.AuditLogSize, "audit-log-size", cfg.AuditLogSize, "number of recent create/update/delete events kept for GET /audit, 0 = disabled (env AUDIT_LOG_SIZE)")
+	fs.StringVar(&cfg.SeedFixture, "seed-fixture", cfg.SeedFixture, "install a named fixture dataset at startup: small, medium, conflict-heavy (env SEED_FIXTURE)")
+	fs.IntVar(&cfg.Seed, "seed", cfg.Seed, "create N placeholder users (user-1..user-N) at startup (env SEED)")
+	fs.StringVar(&cfg.SeedFile, "seed-file", cfg.SeedFile, "create users from a JSON array file [{\"name\": ...}] at startup (env SEED_FILE)")
+	fs.DurationVar(&cfg.StoreProbeTimeout, "store-probe-timeout", cfg.StoreProbeTimeout, "timeout of each startup store probe attempt (env STORE_PROBE_TIMEOUT)")
+	fs.IntVar(&cfg.StoreProbeRetries, "store-probe-retries", cfg.StoreProbeRetries, "extra startup store probe attempts before failing boot, for slow-starting backends (env STORE_PROBE_RETRIES)")
+	fs.IntVar(&cfg.MaxPathBytes, "max-path-bytes", cfg.MaxPathBytes, "max URL path length in bytes, after percent-decoding (env MAX_PATH_BYTES)")
+	fs.IntVar(&cfg.MaxQueryBytes, "max-query-bytes", cfg.MaxQueryBytes, "max query string length in bytes, after percent-decoding (env MAX_QUERY_BYTES)")
+	fs.Int64Var(&cfg.MaxBodyBytes, "max-body-bytes", cfg.MaxBodyBytes, "max request body size in bytes (env MAX_BODY_BYTES)")
+	fs.IntVar(&cfg.MaxInflight, "max-inflight", cfg.MaxInflight, "max requests handled at once, extra requests get 503 server_busy; 0 = unlimited (env MAX_INFLIGHT)")
+	fs.DurationVar(&cfg.InflightQueueTimeout, "inflight-queue-timeout", cfg.InflightQueueTimeout, "how long a request over -max-inflight waits for a free slot before 503, 0 = reject at once (env INFLIGHT_QUEUE_TIMEOUT)")
+	fs.DurationVar(&cfg.ReadTimeout, "read-timeout", cfg.ReadTimeout, "max time to read a whole request, 0 = no limit (env READ_TIMEOUT)")
+	fs.DurationVar(&cfg.ReadHeaderTimeout, "read-header-timeout", cfg.ReadHeaderTimeout, "max time to read request headers, 0 = no limit (env READ_HEADER_TIMEOUT)")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", cfg.WriteTimeout, "max time to write a response, 0 = no limit (env WRITE_TIMEOUT)")
+	fs.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "max keep-alive idle time, 0 = no limit (env IDLE_TIMEOUT)")
+	fs.DurationVar(&cfg.RequestTimeout, "request-timeout", cfg.RequestTimeout, "cancel handlers and reply 504 after this long, 0 = no limit; keep below -write-timeout (env REQUEST_TIMEOUT)")
+	fs.DurationVar(&cfg.IdempotencyTTL, "idempotency-ttl", cfg.IdempotencyTTL, "replay POST /users responses for a repeated Idempotency-Key within this long, 0 = ignore the header (env IDEMPOTENCY_TTL)")
+	fs.StringVar(&cfg.TLSCert, "tls-cert", cfg.TLSCert, "TLS certificate file (PEM); serves HTTPS together with -tls-key (env TLS_CERT)")
+	fs.StringVar(&cfg.TLSKey, "tls-key", cfg.TLSKey, "TLS private key file (PEM) (env TLS_KEY)")
+	fs.StringVar(&cfg.TLSClientCA, "tls-client-ca", cfg.TLSClientCA, "PEM CA bundle for client certificates (mTLS); connections without a valid client cert are refused (env TLS_CLIENT_CA)")
+	fs.BoolVar(&cfg.TLSClientOptional, "tls-client-optional", cfg.TLSClientOptional, "with -tls-client-ca, accept connections without a client cert but require one for POST/PUT/PATCH/DELETE (env TLS_CLIENT_OPTIONAL)")
+	fs.StringVar(&cfg.RedirectHTTP, "redirect-http", cfg.RedirectHTTP, "extra plain HTTP listen address that redirects to HTTPS with 308, e.g. :8081 (env REDIRECT_HTTP)")
+	fs.StringVar(&cfg.AutocertDomain, "autocert-domain", cfg.AutocertDomain, "obtain certificates from Let's Encrypt for these comma-separated domains; serves HTTPS on :443 and ACME challenges on :80 (env AUTOCERT_DOMAIN)")
+	fs.StringVar(&cfg.AutocertCache, "autocert-cache", cfg.AutocertCache, "writable directory for autocert certificates (env AUTOCERT_CACHE)")
+	fs.BoolVar(&cfg.SecurityHeadersEnabled, "security-headers", cfg.SecurityHeadersEnabled, "send default security headers (nosniff, X-Frame-Options, Referrer-Policy, CSP, HSTS with TLS) (env SECURITY_HEADERS)")
+	fs.Func("security-header", "override one security header as \"Name: value\", or \"Name:\" to drop it; repeatable, replaces securityHeaders from the config file", func(v string) error {
+		secHeaders = append(secHeaders, v)
+		return nil
+	})
+	fs.BoolVar(&cfg.CacheControl, "cache-control", cfg.CacheControl, "let user reads be cached (private, max-age) and collections revalidated (no-cache); false = no-store everywhere (env CACHE_CONTROL)")
+	fs.DurationVar(&cfg.CacheMaxAge, "cache-max-age", cfg.CacheMaxAge, "max-age for single-user reads, 0 = always revalidate (env CACHE_MAX_AGE)")
+	fs.BoolVar(&cfg.Pretty, "pretty", cfg.Pretty, "indent JSON responses; per request ?pretty=true|false overrides it (env PRETTY)")
+	fs.StringVar(&cfg.ServerHeader, "server-header", cfg.ServerHeader, "value of the Server response header, empty = not sent (env SERVER_HEADER)")
+	fs.StringVar(&cfg.UnixSocket, "unix-socket", cfg.UnixSocket, "serve on this Unix domain socket path instead of -port, e.g. /run/api.sock (env UNIX_SOCKET)")
+	fs.StringVar(&cfg.SocketMode, "socket-mode", cfg.SocketMode, "octal file permissions of the -unix-socket file (env SOCKET_MODE)")
+	fs.BoolVar(&cfg.LogRawPath, "log-raw-path", cfg.LogRawPath, "also log the raw request path next to the route pattern; disable to keep IDs out of logs (env LOG_RAW_PATH)")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "log level: debug (per-request lines and decoded bodies), info, warn, error (env LOG_LEVEL)")
+	fs.DurationVar(&cfg.SlowRequestThreshold, "slow-request-threshold", cfg.SlowRequestThreshold, "log a WARN line for requests slower than this, 0 = disabled (env SLOW_REQUEST_THRESHOLD)")
+	fs.Var(bodiesRedact, "log-bodies-redact", "JSON field name to mask in -log-bodies output, e.g. email; repeatable, passwords are always masked (env LOG_BODIES_REDACT, comma-separated)")
+	fs.BoolVar(&cfg.LogBodies, "log-bodies", cfg.LogBodies, "with -log-level=debug, also log request and response bodies (truncated, binary elided); may leak personal data (env LOG_BODIES)")
+	fs.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "log output format: text or json (env LOG_FORMAT)")
+	fs.StringVar(&cfg.AccessLog, "access-log", cfg.AccessLog, "write one line per request to this file (info level, rotated by size) instead of the debug log (env ACCESS_LOG)")
+	fs.Int64Var(&cfg.AccessLogMaxBytes, "access-log-max-bytes", cfg.AccessLogMaxBytes, "rotate the -access-log file when it would grow past this size (env ACCESS_LOG_MAX_BYTES)")
+	fs.IntVar(&cfg.AccessLogKeep, "access-log-keep", cfg.AccessLogKeep, "number of rotated access log files to keep, 0 = none (env ACCESS_LOG_KEEP)")
+	fs.StringVar(&cfg.AccessLogFormat, "access-log-format", cfg.AccessLogFormat, "access log line format: common, combined, json, dev; empty = structured record like -log-format; written to stdout when -access-log is unset (env ACCESS_LOG_FORMAT)")
+	fs.StringVar(&cfg.BasePath, "base-path", cfg.BasePath, "path prefix used when building _links, e.g. /api behind a reverse proxy (env BASE_PATH)")
+	fs.BoolVar(&cfg.PrintConfig, "print-config", false, "print the effective configuration as JSON (secrets redacted) and exit")
+
+	return fs, func() {
+		cfg.IDMode = IDMode(*idMode)
+		// -listen mengganti LISTEN dari env/file, bukan menambah
+		if len(*listen) > 0 {
+			cfg.Listen = *listen
+		}
+		if len(secHeaders) > 0 {
+			cfg.SecurityHeaders = secHeaders
+		}
+		if len(apiKeys) > 0 {
+			cfg.APIKeys = apiKeys
+		}
+		if len(*authOpen) > 0 {
+			cfg.AuthOpenPaths = *authOpen
+		}
+		if len(*bodiesRedact) > 0 {
+			cfg.LogBodiesRedact = *bodiesRedact
+		}
+		if len(*adminAllow) > 0 {
+			cfg.AdminAllow = *adminAllow
+		}
+		if len(*adminDeny) > 0 {
+			cfg.AdminDeny = *adminDeny
+		}
+	}
+}
+
+// Effective = config dalam bentuk yang dibaca manusia untuk -print-config:
+// key sesuai tag json, durasi sebagai "5s", field bertag secret:"true"
+// diganti "***" kalau terisi.
+func (c Config) Effective() map[string]any {
+	out := map[string]any{}
+	v := reflect.ValueOf(c)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		value := v.Field(i).Interface()
+		switch x := value.(type) {
+		case time.Duration:
+			value = x.String()
+		case IDMode:
+			value = string(x)
+		}
+		if f.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+			value = "***"
+		}
+		out[name] = value
+	}
+	return out
+}
+
+func (c *Config) applyEnv(lookupEnv func(string) (string, bool)) error {
+	var errs []error
+
+	envInt := func(key string, dst *int) {
+		if v, ok := lookupEnv(key); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a number", key, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	envDuration := func(key string, dst *time.Duration) {
+		if v, ok := lookupEnv(key); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(v))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a duration (e.g. 5s)", key, v))
+				return
+			}
+			*dst = d
+		}
+	}
+	envBool := func(key string, dst *bool) {
+		if v, ok := lookupEnv(key); ok {
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a boolean", key, v))
+				return
+			}
+			*dst = b
+		}
+	}
+	envString := func(key string, dst *string) {
+		if v, ok := lookupEnv(key); ok {
+			*dst = strings.TrimSpace(v)
+		}
+	}
+
+	envInt("PORT", &c.Port)
+	if v, ok := lookupEnv("LISTEN"); ok {
+		c.Listen = splitList(v)
+	}
+	envString("STORE", &c.Store)
+	if v, ok := lookupEnv("ID_MODE"); ok {
+		c.IDMode = IDMode(strings.TrimSpace(v))
+	}
+	envBool("SOFT_DELETE", &c.SoftDelete)
+	envInt("BCRYPT_COST", &c.BcryptCost)
+	envBool("ALLOW_RESET", &c.AllowReset)
+	envBool("ALLOW_METHOD_OVERRIDE", &c.AllowMethodOverride)
+	envBool("PPROF", &c.Pprof)
+	envBool("SCHEMA_VALIDATION", &c.SchemaValidation)
+	if v, ok := lookupEnv("API_KEYS"); ok {
+		c.APIKeys = splitList(v)
+	}
+	envString("API_KEYS_FILE", &c.APIKeysFile)
+	envBool("AUTH_READS", &c.AuthReads)
+	envString("SIGNATURE_SECRET", &c.SignatureSecret)
+	if v, ok := lookupEnv("AUTH_OPEN_PATHS"); ok {
+		c.AuthOpenPaths = splitList(v)
+	}
+	if v, ok := lookupEnv("ADMIN_ALLOW"); ok {
+		c.AdminAllow = splitList(v)
+	}
+	if v, ok := lookupEnv("ADMIN_DENY"); ok {
+		c.AdminDeny = splitList(v)
+	}
+	envString("ADMIN_USER", &c.AdminUser)
+	envString("ADMIN_PASSWORD_HASH", &c.AdminPasswordHash)
+	envInt("AUDIT_LOG_SIZE", &c.AuditLogSize)
+	envString("SEED_FIXTURE", &c.SeedFixture)
+	envInt("SEED", &c.Seed)
+	envString("SEED_FILE", &c.SeedFile)
+	envDuration("STORE_PROBE_TIMEOUT", &c.StoreProbeTimeout)
+	envInt("STORE_PROBE_RETRIES", &c.StoreProbeRetries)
+	envInt("MAX_PATH_BYTES", &c.MaxPathBytes)
+	envInt("MAX_QUERY_BYTES", &c.MaxQueryBytes)
+	if v, ok := lookupEnv("MAX_BODY_BYTES"); ok {
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("MAX_BODY_BYTES: %q is not a number", v))
+		}
+		c.MaxBodyBytes = n
+	}
+	envInt("MAX_INFLIGHT", &c.MaxInflight)
+	envDuration("INFLIGHT_QUEUE_TIMEOUT", &c.InflightQueueTimeout)
+	envDuration("READ_TIMEOUT", &c.ReadTimeout)
+	envDuration("READ_HEADER_TIMEOUT", &c.ReadHeaderTimeout)
+	envDuration("WRITE_TIMEOUT", &c.WriteTimeout)
+	envDuration("IDLE_TIMEOUT", &c.IdleTimeout)
+	envDuration("REQUEST_TIMEOUT", &c.RequestTimeout)
+	envDuration("IDEMPOTENCY_TTL", &c.IdempotencyTTL)
+	envString("TLS_CERT", &c.TLSCert)
+	envString("TLS_KEY", &c.TLSKey)
+	envString("TLS_CLIENT_CA", &c.TLSClientCA)
+	envBool("TLS_CLIENT_OPTIONAL", &c.TLSClientOptional)
+	envString("REDIRECT_HTTP", &c.RedirectHTTP)
+	envBool("SECURITY_HEADERS", &c.SecurityHeadersEnabled)
+	envString("SERVER_HEADER", &c.ServerHeader)
+	envBool("CACHE_CONTROL", &c.CacheControl)
+	envDuration("CACHE_MAX_AGE", &c.CacheMaxAge)
+	envBool("PRETTY", &c.Pretty)
+	envString("AUTOCERT_DOMAIN", &c.AutocertDomain)
+	envString("AUTOCERT_CACHE", &c.AutocertCache)
+	envString("UNIX_SOCKET", &c.UnixSocket)
+	envString("SOCKET_MODE", &c.SocketMode)
+	envBool("LOG_RAW_PATH", &c.LogRawPath)
+	envString("LOG_LEVEL", &c.LogLevel)
+	envDuration("SLOW_REQUEST_THRESHOLD", &c.SlowRequestThreshold)
+	envBool("LOG_BODIES", &c.LogBodies)
+	if v, ok := lookupEnv("LOG_BODIES_REDACT"); ok {
+		c.LogBodiesRedact = splitList(v)
+	}
+	envString("LOG_FORMAT", &c.LogFormat)
+	envString("ACCESS_LOG", &c.AccessLog)
+	if v, ok := lookupEnv("ACCESS_LOG_MAX_BYTES"); ok {
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ACCESS_LOG_MAX_BYTES: %q is not a number", v))
+		}
+		c.AccessLogMaxBytes = n
+	}
+	envInt("ACCESS_LOG_KEEP", &c.AccessLogKeep)
+	envString("ACCESS_LOG_FORMAT", &c.AccessLogFormat)
+	envString("BASE_PATH", &c.BasePath)
+
+	return errors.Join(errs...)
+}
+
+// Validate cek nilai yang tidak masuk akal sebelum server dibangun
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port must be between 1 and 65535, got %d", c.Port))
+	}
+	for _, addr := range c.Listen {
+		if _, port, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, fmt.Errorf("listen address %q must be host:port: %v", addr, err))
+		} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+			errs = append(errs, fmt.Errorf("listen address %q has an invalid port", addr))
+		}
+	}
+	if c.Store != "memory" {
+		errs = append(errs, fmt.Errorf("unsupported store %q (want memory)", c.Store))
+	}
+
+	idMode, err := parseIDMode(string(c.IDMode))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	c.IDMode = idMode
+
+	if c.AuditLogSize < 0 {
+		errs = append(errs, fmt.Errorf("audit log size must not be negative, got %d", c.AuditLogSize))
+	}
+	if c.SeedFixture != "" {
+		if _, ok := fixtureDatasets[c.SeedFixture]; !ok {
+			errs = append(errs, fmt.Errorf("unknown seed fixture %q (want one of %s)", c.SeedFixture, strings.Join(fixtureNames(), ", ")))
+		}
+	}
+	if c.Seed < 0 {
+		errs = append(errs, fmt.Errorf("seed must not be negative, got %d", c.Seed))
+	}
+	if c.StoreProbeTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("store probe timeout must be positive, got %s", c.StoreProbeTimeout))
+	}
+	if c.StoreProbeRetries < 0 {
+		errs = append(errs, fmt.Errorf("store probe retries must not be negative, got %d", c.StoreProbeRetries))
+	}
+	if c.MaxInflight < 0 {
+		errs = append(errs, fmt.Errorf("max inflight must not be negative, got %d", c.MaxInflight))
+	}
+	if c.InflightQueueTimeout < 0 {
+		errs = append(errs, fmt.Errorf("inflight queue timeout must not be negative, got %s", c.InflightQueueTimeout))
+	}
+	if c.MaxPathBytes <= 0 {
+		errs = append(errs, fmt.Errorf("max path bytes must be positive, got %d", c.MaxPathBytes))
+	}
+	if c.MaxQueryBytes <= 0 {
+		errs = append(errs, fmt.Errorf("max query bytes must be positive, got %d", c.MaxQueryBytes))
+	}
+	if c.MaxBodyBytes <= 0 {
+		errs = append(errs, fmt.Errorf("max body bytes must be positive, got %d", c.MaxBodyBytes))
+	}
+
+	timeouts := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"read timeout", c.ReadTimeout},
+		{"read header timeout", c.ReadHeaderTimeout},
+		{"write timeout", c.WriteTimeout},
+		{"idle timeout", c.IdleTimeout},
+		{"request timeout", c.RequestTimeout},
+		{"idempotency ttl", c.IdempotencyTTL},
+		{"cache max age", c.CacheMaxAge},
+		{"slow request threshold", c.SlowRequestThreshold},
+	}
+	for _, t := range timeouts {
+		if t.d < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", t.name, t.d))
+		}
+	}
+
+	if _, err := newIPFilter(c.AdminAllow, c.AdminDeny); err != nil {
+		errs = append(errs, fmt.Errorf("admin ip filter: %w", err))
+	}
+	if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+		errs = append(errs, fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.BcryptCost))
+	}
+	if _, err := newAdminAuth(c.AdminUser, c.AdminPasswordHash); err != nil {
+		errs = append(errs, err)
+	}
+	// tanpa credential admin /debug/pprof/ tidak pernah dipasang
+	if c.Pprof && (c.AdminUser == "" || c.AdminPasswordHash == "") {
+		errs = append(errs, errors.New("pprof requires admin user and admin password hash"))
+	}
+	if _, err := securityHeaders(*c); err != nil {
+		errs = append(errs, err)
+	}
+
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		errs = append(errs, errors.New("tls cert and tls key must be set together"))
+	}
+	if c.TLSClientCA != "" && !c.TLSEnabled() {
+		errs = append(errs, errors.New("tls client ca requires tls cert and tls key"))
+	}
+	if c.TLSClientOptional && c.TLSClientCA == "" {
+		errs = append(errs, errors.New("tls client optional requires tls client ca"))
+	}
+	if c.RedirectHTTP != "" && !c.TLSEnabled() {
+		errs = append(errs, errors.New("redirect http requires tls cert and tls key"))
+	}
+
+	if c.AutocertDomain != "" || c.AutocertCache != "" {
+		if c.AutocertDomain == "" {
+			errs = append(errs, errors.New("autocert cache requires autocert domain"))
+		}
+		for _, d := range c.AutocertDomains() {
+			if strings.ContainsAny(d, ":/ ") {
+				errs = append(errs, fmt.Errorf("autocert domain %q must be a bare host name (no scheme or port)", d))
+			}
+		}
+		if c.AutocertCache == "" {
+			errs = append(errs, errors.New("autocert domain requires autocert cache"))
+		}
+		if c.TLSCert != "" || c.TLSKey != "" {
+			errs = append(errs, errors.New("autocert cannot be combined with tls cert or tls key"))
+		}
+		if c.RedirectHTTP != "" {
+			errs = append(errs, errors.New("autocert already serves :80, redirect http must not be set"))
+		}
+	}
+
+	if _, err := strconv.ParseUint(c.SocketMode, 8, 32); err != nil {
+		errs = append(errs, fmt.Errorf("socket mode must be an octal permission such as 0660, got %q", c.SocketMode))
+	}
+	if _, err := parseLogLevel(c.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+	if !slices.Contains(logFormats, c.LogFormat) {
+		errs = append(errs, fmt.Errorf("invalid log format %q (want %s)", c.LogFormat, strings.Join(logFormats, ", ")))
+	}
+	if c.AccessLogMaxBytes <= 0 {
+		errs = append(errs, fmt.Errorf("access log max bytes must be positive, got %d", c.AccessLogMaxBytes))
+	}
+	if c.AccessLogKeep < 0 {
+		errs = append(errs, fmt.Errorf("access log keep must not be negative, got %d", c.AccessLogKeep))
+	}
+	if _, ok := accessFormats[c.AccessLogFormat]; c.AccessLogFormat != "" && !ok {
+		errs = append(errs, fmt.Errorf("invalid access log format %q (want %s)", c.AccessLogFormat, strings.Join(accessFormatNames(), ", ")))
+	}
+	if c.BasePath != "" && !strings.HasPrefix(c.BasePath, "/") {
+		errs = append(errs, fmt.Errorf("base path must start with /, got %q", c.BasePath))
+	}
+	if len(c.Listen) > 0 && (c.UnixSocket != "" || c.AutocertEnabled()) {
+		errs = append(errs, errors.New("listen cannot be combined with unix socket or autocert"))
+	}
+	if c.UnixSocket != "" && (c.TLSEnabled() || c.AutocertEnabled() || c.RedirectHTTP != "") {
+		errs = append(errs, errors.New("unix socket cannot be combined with tls, autocert or redirect http; terminate TLS in the proxy"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ListenAddrs = alamat TCP yang di-bind: Listen, atau ":" + Port sebagai fallback
+func (c Config) ListenAddrs() []string {
+	if len(c.Listen) > 0 {
+		return c.Listen
+	}
+	return []string{":" + strconv.Itoa(c.Port)}
+}
+
+// RedirectPort = port HTTPS tujuan redirect, diambil dari alamat listen pertama
+func (c Config) RedirectPort() int {
+	if _, port, err := net.SplitHostPort(c.ListenAddrs()[0]); err == nil {
+		if n, err := strconv.Atoi(port); err == nil {
+			return n
+		}
+	}
+	return c.Port
+}
+
+// SocketFileMode = SocketMode yang sudah di-parse (panggil setelah Validate)
+func (c Config) SocketFileMode() os.FileMode {
+	m, _ := strconv.ParseUint(c.SocketMode, 8, 32)
+	return os.FileMode(m)
+}
+
+func (c Config) TLSEnabled() bool {
+	return c.TLSCert != "" && c.TLSKey != ""
+}
+
+func (c Config) AutocertEnabled() bool {
+	return c.AutocertDomain != ""
+}
+
+// AutocertDomains memecah AutocertDomain per koma, entry kosong dibuang
+func (c Config) AutocertDomains() []string {
+	var domains []string
+	for _, d := range strings.Split(c.AutocertDomain, ",") {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// stringList = flag yang boleh diulang (-listen a -listen b)
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, splitList(v)...)
+	return nil
+}
+
+// splitList memecah "a, b,,c" menjadi [a b c]
+func splitList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+// File: /config_file.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyFile mengisi c dari file YAML/JSON. Key mengikuti tag json di Config
+// (lihat -print-config), durasi ditulis sebagai string seperti "5s".
+// File yang tidak ada, key yang tidak dikenal dan tipe yang salah = error.
+func (c *Config) applyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	var doc map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&doc)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension (want .yaml, .yml or .json)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	fields := configFileFields(c)
+
+	// urutkan key supaya pesan error stabil
+	keys := slices.Sorted(maps.Keys(doc))
+
+	var unknown []string
+	for _, key := range keys {
+		if _, ok := fields[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("config file %s: unknown keys: %s", path, strings.Join(unknown, ", "))
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := setConfigField(fields[key], doc[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("config file %s:\n%w", path, errors.Join(errs...))
+	}
+	return nil
+}
+
+// configFileFields = nama key (tag json) -> field Config yang bisa diisi
+func configFileFields(c *Config) map[string]reflect.Value {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+
+	fields := map[string]reflect.Value{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = v.Field(i)
+		}
+	}
+	return fields
+}
+
+// setConfigField mengkonversi nilai hasil decode YAML/JSON ke tipe field
+func setConfigField(field reflect.Value, raw any) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("must be a duration string such as \"5s\"")
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration", s)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		field.SetString(s)
+
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("must be true or false")
+		}
+		field.SetBool(b)
+
+	case reflect.Int, reflect.Int64:
+		n, ok := configInt(raw)
+		if !ok {
+			return fmt.Errorf("must be an integer")
+		}
+		field.SetInt(n)
+
+	case reflect.Slice:
+		// null / [] = list kosong (nil, sama dengan default), supaya output
+		// -print-config JSON bisa dimuat lagi
+		if raw == nil {
+			field.SetZero()
+			return nil
+		}
+		list, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("must be a list of strings")
+		}
+		out := make([]string, len(list))
+		for i, item := range list {
+			if out[i], ok = item.(string); !ok {
+				return fmt.Errorf("must be a list of strings")
+			}
+		}
+		if len(out) == 0 {
+			out = nil
+		}
+		field.Set(reflect.ValueOf(out))
+
+	default:
+		return fmt.Errorf("unsupported config field type %s", field.Type())
+	}
+	return nil
+}
+
+// configInt: YAML memberi int, JSON (UseNumber) memberi json.Number
+func configInt(raw any) (int64, bool) {
+	switch n := raw.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
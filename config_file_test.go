@@ -0,0 +1,163 @@
+// File: /config_file_test.go
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// fullTestConfig = config valid dengan nilai non-default di tiap jenis field
+// (string, bool, int, int64, durasi, list, secret)
+func fullTestConfig(t *testing.T) Config {
+	hash, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.Listen = []string{"127.0.0.1:9001", "[::1]:9002"}
+	cfg.IDMode = IDModeUUID
+	cfg.SoftDelete = true
+	cfg.BcryptCost = 5
+	cfg.AllowMethodOverride = true
+	cfg.APIKeys = []string{"k1 ops", "k2 reader role=user user=3"}
+	cfg.AuthReads = true
+	cfg.AuthOpenPaths = []string{"/health", "/status"}
+	cfg.SignatureSecret = "s3cret"
+	cfg.AdminAllow = []string{"10.0.0.0/8"}
+	cfg.AdminUser = "root"
+	cfg.AdminPasswordHash = string(hash)
+	cfg.AuditLogSize = 7
+	cfg.MaxBodyBytes = 4096
+	cfg.MaxInflight = 3
+	cfg.InflightQueueTimeout = 250 * time.Millisecond
+	cfg.ReadTimeout = 2 * time.Second
+	cfg.RequestTimeout = 1500 * time.Millisecond
+	cfg.CacheMaxAge = time.Minute
+	cfg.Pretty = true
+	cfg.LogLevel = "warn"
+	cfg.LogFormat = "json"
+	cfg.LogBodiesRedact = []string{"password", "token"}
+	cfg.AccessLogMaxBytes = 1 << 30
+	cfg.AccessLogFormat = "combined"
+	cfg.BasePath = "/api"
+	return cfg
+}
+
+// configFileDoc = isi file config untuk cfg: sama dengan -print-config,
+// tapi secret ditulis apa adanya
+func configFileDoc(cfg Config) map[string]any {
+	doc := cfg.Effective()
+	for name, f := range configFileFields(&cfg) {
+		if doc[name] == "***" {
+			doc[name] = f.Interface()
+		}
+	}
+	return doc
+}
+
+func TestConfigFileRoundTrip(t *testing.T) {
+	want := fullTestConfig(t)
+	doc := configFileDoc(want)
+
+	for _, ext := range []string{".json", ".yaml"} {
+		t.Run(ext, func(t *testing.T) {
+			var data []byte
+			var err error
+			if ext == ".json" {
+				data, err = json.MarshalIndent(doc, "", "  ")
+			} else {
+				data, err = yaml.Marshal(doc)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "config"+ext)
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadConfig([]string{"-config", path}, envMap(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := want
+			want.ConfigFile = path
+			if !reflect.DeepEqual(got, want) {
+				for name, f := range configFileFields(&got) {
+					if w := configFileFields(&want)[name]; !reflect.DeepEqual(f.Interface(), w.Interface()) {
+						t.Errorf("%s: got %v, want %v", name, f.Interface(), w.Interface())
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestConfigExampleIsComplete: config.example.yaml bisa dimuat dan memuat
+// semua key, jadi field baru tidak lupa didokumentasikan
+func TestConfigExampleIsComplete(t *testing.T) {
+	if _, err := LoadConfig([]string{"-config", "config.example.yaml"}, envMap(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile("config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	for name := range configFileFields(&cfg) {
+		if _, ok := doc[name]; !ok {
+			t.Errorf("config.example.yaml has no %s key", name)
+		}
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{write("unknown.yaml", "port: 1\ncolour: red\n"), "unknown keys: colour"},
+		{write("types.json", `{"port":"80","softDelete":1,"readTimeout":5}`), "port: must be an integer"},
+		{write("duration.yaml", "readTimeout: soon\n"), `readTimeout: "soon" is not a duration`},
+		{write("config.toml", "port = 1\n"), "unsupported extension"},
+		{filepath.Join(dir, "missing.yaml"), "config file:"},
+	}
+	for _, tt := range tests {
+		_, err := LoadConfig([]string{"-config", tt.path}, envMap(nil))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %v, want %q", filepath.Base(tt.path), err, tt.want)
+		}
+	}
+
+	// prioritas: file < env < flag
+	path := write("port.yaml", "port: 1111\nlogLevel: debug\n")
+	cfg, err := LoadConfig([]string{"-config", path, "-log-level", "error"}, envMap(map[string]string{"PORT": "2222"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 2222 || cfg.LogLevel != "error" {
+		t.Fatalf("port %d, logLevel %q", cfg.Port, cfg.LogLevel)
+	}
+}
@@ -0,0 +1,93 @@
+// File: /config_test.go
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envMap = lookupEnv dari map, supaya test tidak bergantung environment proses
+func envMap(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := LoadConfig(nil, envMap(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Fatalf("LoadConfig without env or flags differs from DefaultConfig:\n%+v\n%+v", cfg, DefaultConfig())
+	}
+	if cfg.Port != 8080 || cfg.MaxBodyBytes != 1<<20 || cfg.LogFormat != "text" {
+		t.Fatalf("defaults = port %d, maxBodyBytes %d, logFormat %q", cfg.Port, cfg.MaxBodyBytes, cfg.LogFormat)
+	}
+}
+
+func TestLoadConfigEnvAndFlagOverrides(t *testing.T) {
+	env := envMap(map[string]string{
+		"PORT":            "9090",
+		"MAX_BODY_BYTES":  "2048",
+		"REQUEST_TIMEOUT": "3s",
+		"LOG_FORMAT":      "json",
+		"SOFT_DELETE":     "true",
+	})
+
+	cfg, err := LoadConfig(nil, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 9090 || cfg.MaxBodyBytes != 2048 || cfg.RequestTimeout != 3*time.Second || cfg.LogFormat != "json" || !cfg.SoftDelete {
+		t.Fatalf("env not applied: %+v", cfg)
+	}
+
+	// flag menang atas env
+	cfg, err = LoadConfig([]string{"-port", "7070", "-log-format", "text"}, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 7070 || cfg.LogFormat != "text" || cfg.MaxBodyBytes != 2048 {
+		t.Fatalf("flags did not override env: port %d, logFormat %q, maxBodyBytes %d", cfg.Port, cfg.LogFormat, cfg.MaxBodyBytes)
+	}
+}
+
+func TestLoadConfigInvalidValues(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want []string
+	}{
+		{name: "non-numeric port", env: map[string]string{"PORT": "http"}, want: []string{`PORT: "http" is not a number`}},
+		{name: "bad duration", env: map[string]string{"REQUEST_TIMEOUT": "soon"}, want: []string{`REQUEST_TIMEOUT: "soon" is not a duration`}},
+		{name: "bad boolean", env: map[string]string{"SOFT_DELETE": "maybe"}, want: []string{`SOFT_DELETE: "maybe" is not a boolean`}},
+		{name: "port out of range", args: []string{"-port", "70000"}, want: []string{"port"}},
+		{name: "unknown log format", args: []string{"-log-format", "xml"}, want: []string{"xml"}},
+		{name: "pprof without admin", args: []string{"-pprof"}, want: []string{"pprof requires admin user and admin password hash"}},
+		{name: "pprof with half the admin credentials", args: []string{"-pprof", "-admin-user", "ops"}, want: []string{"pprof requires admin user and admin password hash"}},
+		// semua kesalahan dilaporkan sekaligus
+		{
+			name: "env and validation errors together",
+			args: []string{"-max-path-bytes", "0"},
+			env:  map[string]string{"PORT": "x"},
+			want: []string{`PORT: "x" is not a number`, "max path bytes must be positive"},
+		},
+	}
+	for _, tt := range tests {
+		_, err := LoadConfig(tt.args, envMap(tt.env))
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: error %q does not mention %q", tt.name, err, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// File: /contract_test.go
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test -run TestContract -update = tulis ulang testdata/contract/*.golden
+// dari response saat ini (TestAccessLogFormats: testdata/access_log/). Hanya dipakai kalau perubahan bentuk response memang
+// disengaja; review diff golden-nya seperti kode biasa.
+var updateGolden = flag.Bool("update", false, "rewrite golden files under testdata")
+
+const contractDir = "testdata/contract"
+
+// contractStep = satu request di skenario kontrak, dijalankan berurutan
+// terhadap server yang sama (state dari step sebelumnya terbawa).
+type contractStep struct {
+	// name = nama file golden, unik
+	name   string
+	method string
+	path   string
+	body   string
+	// header = pasangan "Name", "value"; Content-Type JSON otomatis kalau ada body
+	header []string
+	// digest: golden hanya menyimpan sha256 body (dokumen statis besar)
+	digest bool
+	// skipBody: body tidak dibandingkan karena isinya latency nyata (/metrics)
+	skipBody bool
+}
+
+// volatileHeaders nilainya diganti "<normalized>" sebelum dibandingkan
+var volatileHeaders = []string{"Date"}
+
+// volatileJSONKeys = field body yang berasal dari pengukuran nyata (bukan jam
+// server), jadi tidak bisa dibuat tetap lewat fakeClock
+var volatileJSONKeys = map[string]bool{"durationMs": true, "checkedAt": true}
+
+// contractScenario mencakup semua route publik dan jalur error utamanya
+var contractScenario = []contractStep{
+	{name: "index", method: "GET", path: "/"},
+	{name: "health", method: "GET", path: "/health"},
+	{name: "status", method: "GET", path: "/status"},
+	{name: "time", method: "GET", path: "/time"},
+	{name: "echo", method: "GET", path: "/echo?name=Ann"},
+	{name: "echo-missing-name", method: "GET", path: "/echo"},
+	{name: "sum", method: "POST", path: "/sum", body: `{"values":[1,2,3]}`},
+	{name: "sum-overflow", method: "POST", path: "/sum", body: `{"values":[9223372036854775807,1]}`},
+	{name: "sum-wrong-method", method: "GET", path: "/sum"},
+	{name: "mul", method: "POST", path: "/mul", body: `{"a":6,"b":7}`},
+	{name: "unknown-route", method: "GET", path: "/nope"},
+	{name: "trailing-slash", method: "GET", path: "/users/?limit=1"},
+	{name: "options-users", method: "OPTIONS", path: "/users"},
+
+	{name: "users-create", method: "POST", path: "/users", body: `{"name":"Alice Doe"}`},
+	{name: "users-create-second", method: "POST", path: "/users", body: `{"name":"Budi Santoso","password":"secret123"}`},
+	{name: "users-create-invalid", method: "POST", path: "/users", body: `{"name":""}`},
+	{name: "users-create-unknown-field", method: "POST", path: "/users", body: `{"name":"X Y","email":"x@y.z"}`},
+	{name: "users-create-text-plain", method: "POST", path: "/users", body: `name=x`, header: []string{"Content-Type", "text/plain"}},
+	{name: "users-list", method: "GET", path: "/users"},
+	{name: "users-list-not-modified", method: "GET", path: "/users", header: []string{"If-None-Match", `"users-2"`}},
+	{name: "users-get-many", method: "GET", path: "/users?ids=1,99"},
+	{name: "users-get", method: "GET", path: "/users/1"},
+	{name: "users-head", method: "HEAD", path: "/users/1"},
+	{name: "users-get-invalid-id", method: "GET", path: "/users/abc"},
+	{name: "users-get-missing", method: "GET", path: "/users/99"},
+	{name: "users-put", method: "PUT", path: "/users/1", body: `{"name":"Alice Updated"}`},
+	{name: "users-patch-stale", method: "PATCH", path: "/users/1", body: `{"name":"Stale"}`, header: []string{"If-Match", `"1"`}},
+	{name: "users-profile-patch", method: "PATCH", path: "/users/1/profile", body: `{"displayName":"Alice","bio":"hi"}`},
+	{name: "users-profile-get", method: "GET", path: "/users/1/profile"},
+	{name: "users-password", method: "POST", path: "/users/2/password", body: `{"currentPassword":"secret123","newPassword":"secret456"}`, header: []string{"If-Match", `"1"`}},
+	{name: "users-password-wrong", method: "POST", path: "/users/2/password", body: `{"currentPassword":"nope-nope","newPassword":"secret789"}`},
+	{name: "users-order", method: "GET", path: "/users/1/orders/7"},
+	{name: "users-recent", method: "GET", path: "/users/recent?since=2024-01-02T03:04:00Z"},
+	{name: "users-exists", method: "GET", path: "/users/exists?name=alice%20updated"},
+	{name: "users-import", method: "POST", path: "/users/import", body: "name,role\nCitra Lestari,user\n,user\n", header: []string{"Content-Type", "text/csv"}},
+	{name: "users-delete", method: "DELETE", path: "/users/2"},
+	{name: "users-restore", method: "POST", path: "/users/2/restore"},
+	{name: "users-item-wrong-method", method: "POST", path: "/users/1"},
+
+	{name: "batch-commit", method: "POST", path: "/batch", body: `{"operations":[{"method":"POST","path":"/users","body":{"name":"Dewi Batch"}},{"method":"PATCH","path":"/users/$1.id","body":{"name":"Dewi Renamed"}}]}`},
+	{name: "batch-rollback", method: "POST", path: "/batch", body: `{"operations":[{"method":"POST","path":"/users","body":{"name":"Eko Rollback"}},{"method":"DELETE","path":"/users/999"}]}`},
+	{name: "audit", method: "GET", path: "/audit"},
+
+	{name: "metrics", method: "GET", path: "/metrics", skipBody: true},
+	{name: "openapi", method: "GET", path: "/openapi.json", digest: true},
+	{name: "docs", method: "GET", path: "/docs", digest: true},
+	{name: "examples", method: "GET", path: "/examples", digest: true},
+	{name: "examples-one", method: "GET", path: "/examples/users.create"},
+	{name: "examples-missing", method: "GET", path: "/examples/nope"},
+	{name: "admin-disabled", method: "GET", path: "/admin/fixtures"},
+}
+
+// TestContract menjalankan contractScenario terhadap stack lengkap (NewServer
+// + semua middleware) dengan fakeClock dan X-Request-ID tetap, lalu
+// membandingkan tiap response dengan golden file-nya.
+func TestContract(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		c.SoftDelete = true
+	}), WithServerClock(clock.Now))
+
+	names := map[string]bool{}
+	for i, step := range contractScenario {
+		if names[step.name] {
+			t.Fatalf("duplicate step name %q", step.name)
+		}
+		names[step.name] = true
+
+		clock.Advance(time.Second)
+		header := append([]string{"X-Request-ID", fmt.Sprintf("contract-%03d", i+1)}, step.header...)
+		res, body := doRequest(t, ts, step.method, step.path, step.body, header...)
+		got := renderContract(step, res, body)
+
+		file := filepath.Join(contractDir, step.name+".golden")
+		if *updateGolden {
+			if err := os.MkdirAll(contractDir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(file)
+		if err != nil {
+			t.Errorf("%s: %v (run go test -run TestContract -update)", step.name, err)
+			continue
+		}
+		if diff := contractDiff(string(want), got); len(diff) > 0 {
+			t.Errorf("%s %s %s differs from %s:\n  %s", step.name, step.method, step.path, file, strings.Join(diff, "\n  "))
+		}
+	}
+
+	if !*updateGolden {
+		// golden sisa step yang sudah dihapus dari skenario
+		files, _ := filepath.Glob(filepath.Join(contractDir, "*.golden"))
+		for _, f := range files {
+			if !names[strings.TrimSuffix(filepath.Base(f), ".golden")] {
+				t.Errorf("%s has no step in contractScenario", f)
+			}
+		}
+	}
+}
+
+// renderContract = isi golden: request line, status, header terurut, baris
+// kosong, lalu body (JSON di-indent, digest, atau teks apa adanya)
+func renderContract(step contractStep, res *http.Response, body string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", step.method, step.path)
+	fmt.Fprintf(&b, "status: %d\n", res.StatusCode)
+
+	// body dengan field volatile panjangnya ikut berubah
+	var rendered string
+	volatileLength := step.skipBody
+	switch {
+	case body == "":
+	case step.skipBody:
+		rendered = "<body not compared>\n"
+	case step.digest:
+		sum := sha256.Sum256([]byte(body))
+		rendered = fmt.Sprintf("sha256:%s\n", hex.EncodeToString(sum[:]))
+	case isJSONResponse(res):
+		rendered, volatileLength = normalizeJSON(body)
+	default:
+		rendered = body
+		if !strings.HasSuffix(body, "\n") {
+			rendered += "\n"
+		}
+	}
+
+	keys := make([]string, 0, len(res.Header))
+	for k := range res.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := strings.Join(res.Header.Values(k), ", ")
+		if slices.Contains(volatileHeaders, k) || (volatileLength && k == "Content-Length") {
+			v = "<normalized>"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", k, v)
+	}
+	b.WriteString("\n")
+	b.WriteString(rendered)
+	return b.String()
+}
+
+func isJSONResponse(res *http.Response) bool {
+	mt, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// normalizeJSON meng-indent body dan mengganti volatileJSONKeys;
+// replaced = ada field yang diganti
+func normalizeJSON(body string) (out string, replaced bool) {
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return body, false
+	}
+	v = replaceVolatile(v, &replaced)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	return buf.String(), replaced
+}
+
+func replaceVolatile(v any, replaced *bool) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if volatileJSONKeys[k] {
+				t[k] = "<normalized>"
+				*replaced = true
+			} else {
+				t[k] = replaceVolatile(child, replaced)
+			}
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = replaceVolatile(child, replaced)
+		}
+	}
+	return v
+}
+
+// contractDiff membandingkan bagian header per baris dan body secara
+// JSON-aware: yang dilaporkan path field yang beda, bukan byte pertama.
+func contractDiff(want, got string) []string {
+	wantHead, wantBody, _ := strings.Cut(want, "\n\n")
+	gotHead, gotBody, _ := strings.Cut(got, "\n\n")
+
+	var diff []string
+	wantLines, gotLines := strings.Split(wantHead, "\n"), strings.Split(gotHead, "\n")
+	for _, l := range wantLines {
+		if !slices.Contains(gotLines, l) {
+			diff = append(diff, "- "+l)
+		}
+	}
+	for _, l := range gotLines {
+		if !slices.Contains(wantLines, l) {
+			diff = append(diff, "+ "+l)
+		}
+	}
+
+	var wv, gv any
+	if json.Unmarshal([]byte(wantBody), &wv) == nil && json.Unmarshal([]byte(gotBody), &gv) == nil {
+		jsonDiff("$", wv, gv, &diff)
+	} else if wantBody != gotBody {
+		diff = append(diff, fmt.Sprintf("body: want %q, got %q", wantBody, gotBody))
+	}
+	return diff
+}
+
+// jsonDiff menambahkan satu baris per perbedaan, mis. "$.data.name: want "a", got "b""
+func jsonDiff(path string, want, got any, out *[]string) {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(w)+len(g))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		for k := range g {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			wc, inWant := w[k]
+			gc, inGot := g[k]
+			switch {
+			case !inGot:
+				*out = append(*out, fmt.Sprintf("%s.%s: missing (want %s)", path, k, compactJSON(wc)))
+			case !inWant:
+				*out = append(*out, fmt.Sprintf("%s.%s: unexpected %s", path, k, compactJSON(gc)))
+			default:
+				jsonDiff(path+"."+k, wc, gc, out)
+			}
+		}
+		return
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			break
+		}
+		if len(w) != len(g) {
+			*out = append(*out, fmt.Sprintf("%s: want %d items, got %d", path, len(w), len(g)))
+		}
+		for i := 0; i < min(len(w), len(g)); i++ {
+			jsonDiff(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], out)
+		}
+		return
+	}
+	if !reflect.DeepEqual(want, got) {
+		*out = append(*out, fmt.Sprintf("%s: want %s, got %s", path, compactJSON(want), compactJSON(got)))
+	}
+}
+
+func compactJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// TestContractDiff memastikan diff reporter menunjuk field yang berubah
+func TestContractDiff(t *testing.T) {
+	want := "GET /x\nstatus: 200\n\n{\"data\":{\"name\":\"a\",\"gone\":1},\"list\":[1,2]}\n"
+	got := "GET /x\nstatus: 404\n\n{\"data\":{\"name\":\"b\",\"new\":true},\"list\":[1]}\n"
+
+	diff := contractDiff(want, got)
+	wantDiff := []string{
+		"- status: 200",
+		"+ status: 404",
+		`$.data.gone: missing (want 1)`,
+		`$.data.name: want "a", got "b"`,
+		`$.data.new: unexpected true`,
+		`$.list: want 2 items, got 1`,
+	}
+	if !slices.Equal(diff, wantDiff) {
+		t.Fatalf("diff =\n%s\nwant\n%s", strings.Join(diff, "\n"), strings.Join(wantDiff, "\n"))
+	}
+	if d := contractDiff(want, want); len(d) != 0 {
+		t.Fatalf("same input: diff = %v", d)
+	}
+}
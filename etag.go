@@ -0,0 +1,81 @@
+// File: /etag.go
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hashETag = strong ETag dari hash JSON v: representasi yang sama selalu
+// menghasilkan tag yang sama, perubahan apa pun (termasuk UpdatedAt) mengganti
+// tag. Dipakai ETag user, list bisa memakai helper yang sama.
+func hashETag(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return strconv.Quote(hex.EncodeToString(sum[:16]))
+}
+
+// etagMatch: salah satu ETag di header If-None-Match sama dengan etag,
+// atau header berisi "*". Perbandingan lemah (RFC 9110 13.1.2), jadi
+// W/"3" cocok dengan "3".
+func etagMatch(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, part := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(part), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified memasang ETag (dan Last-Modified kalau modified tidak nol) lalu
+// menulis 304 kalau If-None-Match cocok. If-Modified-Since hanya dipakai kalau
+// If-None-Match tidak dikirim (RFC 9110 13.1.3).
+// Response 304 tidak punya body dan Content-Type (writeJSON tidak dipanggil).
+// true = response sudah selesai, handler langsung return.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	match := false
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		match = etagMatch(inm, etag)
+	} else if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.IsZero() {
+		// Last-Modified hanya sampai detik
+		match = !modified.Truncate(time.Second).After(ims)
+	}
+	if !match {
+		return false
+	}
+	w.Header().Del("Content-Type")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// collectionETag = ETag GET /users dari versi koleksi. Representasi NDJSON
+// beda dengan JSON, jadi ETag-nya juga beda.
+func collectionETag(version uint64, ndjson bool) string {
+	tag := "users-" + strconv.FormatUint(version, 10)
+	if ndjson {
+		tag += "-ndjson"
+	}
+	return strconv.Quote(tag)
+}
@@ -0,0 +1,171 @@
+// File: /etag_test.go
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestETagMatch(t *testing.T) {
+	tests := []struct {
+		header, etag string
+		want         bool
+	}{
+		{`"3"`, `"3"`, true},
+		{`"4"`, `"3"`, false},
+		{`*`, `"3"`, true},
+		{` * `, `"3"`, true},
+		{``, `"3"`, false},
+		{`"1", "3"`, `"3"`, true},
+		{`"1","2"`, `"3"`, false},
+		// perbandingan lemah: W/ diabaikan di kedua sisi
+		{`W/"3"`, `"3"`, true},
+		{`"3"`, `W/"3"`, true},
+		{`3`, `"3"`, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatch(tt.header, tt.etag); got != tt.want {
+			t.Errorf("etagMatch(%q, %q) = %v, want %v", tt.header, tt.etag, got, tt.want)
+		}
+	}
+}
+
+// If-None-Match di GET /users/{id}: cocok = 304 tanpa body dan Content-Type
+func TestUserIfNoneMatch(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+	res, _ := doRequest(t, ts, "GET", "/users/1", "")
+	etag := res.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("GET has no ETag")
+	}
+
+	tests := []struct {
+		name, header string
+		want         int
+	}{
+		{"match", etag, http.StatusNotModified},
+		{"mismatch", `"999"`, http.StatusOK},
+		{"wildcard", "*", http.StatusNotModified},
+		{"one of several", `"999", ` + etag, http.StatusNotModified},
+		{"weak", "W/" + etag, http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		for _, method := range []string{"GET", "HEAD"} {
+			res, body := doRequest(t, ts, method, "/users/1", "", "If-None-Match", tt.header)
+			if res.StatusCode != tt.want {
+				t.Errorf("%s %s: status %d, want %d", method, tt.name, res.StatusCode, tt.want)
+				continue
+			}
+			if res.Header.Get("ETag") != etag {
+				t.Errorf("%s %s: ETag %q, want %q", method, tt.name, res.Header.Get("ETag"), etag)
+			}
+			if tt.want != http.StatusNotModified {
+				continue
+			}
+			if body != "" || res.Header.Get("Content-Type") != "" {
+				t.Errorf("%s %s: 304 with Content-Type %q, body %q", method, tt.name, res.Header.Get("Content-Type"), body)
+			}
+		}
+	}
+
+	// setelah update ETag lama tidak cocok lagi
+	doRequest(t, ts, "PATCH", "/users/1", `{"name":"Ada Lovelace"}`)
+	res, body := doRequest(t, ts, "GET", "/users/1", "", "If-None-Match", etag)
+	if res.StatusCode != http.StatusOK || res.Header.Get("ETag") == etag || decodeBody[userEnvelope](t, body).Data.Name != "Ada Lovelace" {
+		t.Fatalf("after update: status %d, ETag %q: %s", res.StatusCode, res.Header.Get("ETag"), body)
+	}
+}
+
+// ETag GET /users: mutasi apa pun mengganti ETag, periode tanpa perubahan
+// (termasuk baca dan request yang ditolak) tetap 304
+func TestCollectionETag(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(nil), WithServerClock(clock.Now))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+	res, _ := doRequest(t, ts, "GET", "/users", "")
+	etag, lastModified := res.Header.Get("ETag"), res.Header.Get("Last-Modified")
+	if etag == "" || lastModified != testEpoch.Format(http.TimeFormat) {
+		t.Fatalf("GET /users: ETag %q, Last-Modified %q", etag, lastModified)
+	}
+
+	// tanpa perubahan: baca, validasi gagal, PATCH tanpa perubahan, waktu lewat
+	clock.Advance(time.Hour)
+	doRequest(t, ts, "GET", "/users/1", "")
+	doRequest(t, ts, "POST", "/users", `{"name":""}`)
+	doRequest(t, ts, "PATCH", "/users/1", `{"name":"Ada"}`)
+	doRequest(t, ts, "DELETE", "/users/99", "")
+	for _, h := range [][]string{{"If-None-Match", etag}, {"If-Modified-Since", lastModified}} {
+		res, body := doRequest(t, ts, "GET", "/users", "", h...)
+		if res.StatusCode != http.StatusNotModified || body != "" || res.Header.Get("ETag") != etag {
+			t.Fatalf("unchanged %s: status %d, ETag %q: %q", h[0], res.StatusCode, res.Header.Get("ETag"), body)
+		}
+	}
+
+	// setiap mutasi mengganti ETag dan Last-Modified
+	seen := map[string]bool{etag: true}
+	for _, m := range []struct{ method, path, body string }{
+		{"POST", "/users", `{"name":"Grace"}`},
+		{"PATCH", "/users/1", `{"name":"Ada Lovelace"}`},
+		{"PATCH", "/users/1/profile", `{"bio":"math"}`},
+		{"DELETE", "/users/2", ""},
+	} {
+		clock.Advance(time.Minute)
+		if res, body := doRequest(t, ts, m.method, m.path, m.body); res.StatusCode >= 400 {
+			t.Fatalf("%s %s: status %d: %s", m.method, m.path, res.StatusCode, body)
+		}
+		res, body := doRequest(t, ts, "GET", "/users", "", "If-None-Match", etag)
+		next := res.Header.Get("ETag")
+		if res.StatusCode != http.StatusOK || body == "" || seen[next] {
+			t.Fatalf("after %s %s: status %d, ETag %q (seen %v)", m.method, m.path, res.StatusCode, next, seen)
+		}
+		if got := res.Header.Get("Last-Modified"); got != clock.Now().Format(http.TimeFormat) {
+			t.Fatalf("after %s %s: Last-Modified %q", m.method, m.path, got)
+		}
+		seen[next] = true
+		etag = next
+	}
+
+	// JSON dan NDJSON punya ETag sendiri-sendiri
+	res, _ = doRequest(t, ts, "GET", "/users?format=ndjson", "", "If-None-Match", etag)
+	if res.StatusCode != http.StatusOK || res.Header.Get("ETag") == etag {
+		t.Fatalf("NDJSON: status %d, ETag %q", res.StatusCode, res.Header.Get("ETag"))
+	}
+}
+
+// ETag user = hash representasinya: Touch (Version tetap) dan Reset (Version
+// mulai lagi dari 1) tetap menghasilkan ETag baru
+func TestUserETagFollowsRepresentation(t *testing.T) {
+	clock := newFakeClock()
+	srv, ts := newTestServerWithRoutes(t, testConfig(nil), nil, WithServerClock(clock.Now))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+	res, body := doRequest(t, ts, "GET", "/users/1", "")
+	etag, before := res.Header.Get("ETag"), decodeBody[userEnvelope](t, body).Data
+
+	clock.Advance(time.Hour)
+	srv.store.Touch(before.ID, clock.Now())
+	res, body = doRequest(t, ts, "GET", "/users/1", "", "If-None-Match", etag)
+	after := decodeBody[userEnvelope](t, body).Data
+	if res.StatusCode != http.StatusOK || after.Version != before.Version || after.LastActiveAt.Equal(before.LastActiveAt) {
+		t.Fatalf("after Touch: status %d: %s", res.StatusCode, body)
+	}
+	touched := res.Header.Get("ETag")
+	if touched == etag {
+		t.Fatalf("ETag %s unchanged after Touch", etag)
+	}
+	// ETag dari GET sebelum Touch sudah basi untuk If-Match
+	if res, body := doRequest(t, ts, "PATCH", "/users/1", `{"name":"X"}`, "If-Match", etag); res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("If-Match before Touch: status %d: %s", res.StatusCode, body)
+	}
+
+	// user lain dengan id dan Version yang sama
+	srv.store.Reset()
+	doRequest(t, ts, "POST", "/users", `{"name":"Grace"}`)
+	for _, old := range []string{etag, touched} {
+		if res, _ := doRequest(t, ts, "GET", "/users/1", "", "If-None-Match", old); res.StatusCode != http.StatusOK {
+			t.Fatalf("after Reset: If-None-Match %s: status %d", old, res.StatusCode)
+		}
+	}
+}
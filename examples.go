@@ -0,0 +1,233 @@
+// File: /examples.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// routeExample = contoh request/response untuk satu route.
+// Ditulis sebagai value Go supaya ikut berubah saat struct berubah.
+type routeExample struct {
+	ID      string
+	Method  string
+	Path    string // path template seperti di openapi.json
+	Status  int
+	Summary string
+
+	Request  any // nil = tanpa body
+	Response any
+}
+
+// exampleDoc = bentuk JSON yang disajikan di GET /examples/{route-id}
+type exampleDoc struct {
+	ID       string `json:"id"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Status   int    `json:"status"`
+	Summary  string `json:"summary"`
+	Request  any    `json:"request,omitempty"`
+	Response any    `json:"response"`
+}
+
+var exampleTime = time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+var routeExamples = []routeExample{
+	{
+		ID:      "users.create",
+		Method:  http.MethodPost,
+		Path:    "/users",
+		Status:  http.StatusCreated,
+		Summary: "Create a user",
+		Request: apiResponse{"name": "Alice"},
+		Response: dataBody(linkBuilder{}.user(User{
+			ID:           "1",
+			Name:         "Alice",
+			Role:         RoleUser,
+			CreatedAt:    exampleTime,
+			UpdatedAt:    exampleTime,
+			LastActiveAt: exampleTime,
+		}), nil),
+	},
+	{
+		ID:       "users.create.validation_failed",
+		Method:   http.MethodPost,
+		Path:     "/users",
+		Status:   http.StatusBadRequest,
+		Summary:  "Create a user without a name",
+		Request:  apiResponse{"name": ""},
+		Response: errorBody("validation_failed", "missing required fields", []string{"name is required"}),
+	},
+	{
+		ID:       "users.get.not_found",
+		Method:   http.MethodGet,
+		Path:     "/users/{id}",
+		Status:   http.StatusNotFound,
+		Summary:  "Get a user that does not exist",
+		Response: errorBody("not_found", "resource not found", nil),
+	},
+}
+
+// buildOpenAPI menyisipkan examples ke spec dan memvalidasi setiap example
+// terhadap schema operasi yang bersangkutan.
+func buildOpenAPI(rawSpec []byte, examples []routeExample) ([]byte, map[string]exampleDoc, error) {
+	var spec map[string]any
+	if err := json.Unmarshal(rawSpec, &spec); err != nil {
+		return nil, nil, fmt.Errorf("openapi.json: %w", err)
+	}
+	v := schemaValidator{root: spec}
+
+	docs := make(map[string]exampleDoc, len(examples))
+	var problems []string
+
+	for _, ex := range examples {
+		if _, dup := docs[ex.ID]; dup {
+			problems = append(problems, ex.ID+": duplicate example id")
+			continue
+		}
+
+		op, err := specOperation(spec, ex.Path, ex.Method)
+		if err != nil {
+			problems = append(problems, ex.ID+": "+err.Error())
+			continue
+		}
+
+		doc := exampleDoc{
+			ID:      ex.ID,
+			Method:  ex.Method,
+			Path:    ex.Path,
+			Status:  ex.Status,
+			Summary: ex.Summary,
+		}
+
+		if ex.Request != nil {
+			body, err := inlineRef(v, op, "requestBody")
+			if err != nil {
+				problems = append(problems, ex.ID+": request: "+err.Error())
+				continue
+			}
+			value, errs := attachExample(v, body, ex.ID, ex.Summary, ex.Request)
+			if len(errs) > 0 {
+				problems = append(problems, prefixAll(ex.ID+": request ", errs)...)
+				continue
+			}
+			doc.Request = value
+		}
+
+		responses, _ := op["responses"].(map[string]any)
+		if responses == nil {
+			problems = append(problems, ex.ID+": operation has no responses")
+			continue
+		}
+		resp, err := inlineRef(v, responses, strconv.Itoa(ex.Status))
+		if err != nil {
+			problems = append(problems, ex.ID+": response: "+err.Error())
+			continue
+		}
+		value, errs := attachExample(v, resp, ex.ID, ex.Summary, ex.Response)
+		if len(errs) > 0 {
+			problems = append(problems, prefixAll(ex.ID+": response ", errs)...)
+			continue
+		}
+		doc.Response = value
+
+		docs[ex.ID] = doc
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("invalid openapi examples:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	out, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, docs, nil
+}
+
+func specOperation(spec map[string]any, path, method string) (map[string]any, error) {
+	paths, _ := spec["paths"].(map[string]any)
+	item, _ := paths[path].(map[string]any)
+	op, _ := item[strings.ToLower(method)].(map[string]any)
+	if op == nil {
+		return nil, fmt.Errorf("no operation %s %s in openapi.json", method, path)
+	}
+	return op, nil
+}
+
+// inlineRef mengganti parent[key] yang berupa $ref dengan salinannya,
+// karena examples tidak boleh ditempel di sebelah $ref.
+func inlineRef(v schemaValidator, parent map[string]any, key string) (map[string]any, error) {
+	node, ok := parent[key].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s is not documented", key)
+	}
+
+	ref, isRef := node["$ref"].(string)
+	if !isRef {
+		return node, nil
+	}
+
+	resolved, err := v.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// deep copy lewat JSON supaya komponen bersama tidak ikut berubah
+	data, _ := json.Marshal(resolved)
+	var copied map[string]any
+	_ = json.Unmarshal(data, &copied)
+
+	parent[key] = copied
+	return copied, nil
+}
+
+// attachExample memvalidasi example terhadap schema application/json di node,
+// lalu menambahkannya ke node.content["application/json"].examples[id].
+func attachExample(v schemaValidator, node map[string]any, id, summary string, example any) (any, []string) {
+	content, _ := node["content"].(map[string]any)
+	media, _ := content["application/json"].(map[string]any)
+	if media == nil {
+		return nil, []string{"has no application/json content"}
+	}
+	schema, _ := media["schema"].(map[string]any)
+	if schema == nil {
+		return nil, []string{"has no schema"}
+	}
+
+	data, err := json.Marshal(example)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, []string{err.Error()}
+	}
+
+	if errs := v.validateValue(schema, value); len(errs) > 0 {
+		return nil, errs
+	}
+
+	examples, _ := media["examples"].(map[string]any)
+	if examples == nil {
+		examples = map[string]any{}
+		media["examples"] = examples
+	}
+	examples[id] = map[string]any{
+		"summary": summary,
+		"value":   value,
+	}
+	return value, nil
+}
+
+func prefixAll(prefix string, msgs []string) []string {
+	out := make([]string, len(msgs))
+	for i, m := range msgs {
+		out[i] = prefix + m
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+// File: /examples_test.go
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRouteExamplesEmbeddedInSpec(t *testing.T) {
+	spec, docs, err := buildOpenAPI(openAPISpec, routeExamples)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc any
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatal(err)
+	}
+	// examplesAt = .paths[path][method] lalu keys, berakhir di content.application/json.examples
+	examplesAt := func(path, method string, keys ...string) map[string]any {
+		node := doc
+		for _, k := range append(append([]string{"paths", path, method}, keys...), "content", "application/json", "examples") {
+			m, _ := node.(map[string]any)
+			node = m[k]
+		}
+		m, _ := node.(map[string]any)
+		return m
+	}
+
+	if _, ok := examplesAt("/users", "post", "requestBody")["users.create"]; !ok {
+		t.Error("POST /users request has no users.create example")
+	}
+	for status, id := range map[string]string{"201": "users.create", "400": "users.create.validation_failed"} {
+		if _, ok := examplesAt("/users", "post", "responses", status)[id]; !ok {
+			t.Errorf("POST /users %s response has no %s example", status, id)
+		}
+	}
+	if _, ok := examplesAt("/users/{id}", "get", "responses", "404")["users.get.not_found"]; !ok {
+		t.Error("GET /users/{id} 404 response has no users.get.not_found example")
+	}
+
+	if len(docs) != len(routeExamples) {
+		t.Fatalf("%d example docs for %d examples", len(docs), len(routeExamples))
+	}
+}
+
+func TestBuildOpenAPIRejectsInvalidExamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		example routeExample
+		want    string
+	}{
+		{
+			name: "wrong request type",
+			example: routeExample{ID: "bad.request", Method: http.MethodPost, Path: "/users", Status: http.StatusCreated,
+				Request: apiResponse{"name": 5}, Response: routeExamples[0].Response},
+			want: "bad.request: request ",
+		},
+		{
+			name: "response missing required field",
+			example: routeExample{ID: "bad.response", Method: http.MethodGet, Path: "/users/{id}", Status: http.StatusNotFound,
+				Response: apiResponse{"message": "no error code"}},
+			want: "bad.response: response ",
+		},
+		{
+			name:    "undocumented status",
+			example: routeExample{ID: "bad.status", Method: http.MethodGet, Path: "/users/{id}", Status: http.StatusTeapot, Response: apiResponse{}},
+			want:    "bad.status: response: 418 is not documented",
+		},
+		{
+			name:    "unknown operation",
+			example: routeExample{ID: "bad.op", Method: http.MethodPut, Path: "/sum", Status: http.StatusOK, Response: apiResponse{}},
+			want:    "bad.op: no operation PUT /sum in openapi.json",
+		},
+		{
+			name:    "duplicate id",
+			example: routeExamples[0],
+			want:    "users.create: duplicate example id",
+		},
+	}
+	for _, tt := range tests {
+		examples := append(append([]routeExample{}, routeExamples...), tt.example)
+		_, _, err := buildOpenAPI(openAPISpec, examples)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestExamplesEndpoint(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+
+	_, body := doRequest(t, ts, "GET", "/examples", "")
+	if !strings.Contains(body, `"data":["users.create","users.create.validation_failed","users.get.not_found"]`) {
+		t.Fatalf("GET /examples = %s", body)
+	}
+
+	res, body := doRequest(t, ts, "GET", "/examples/users.get.not_found", "")
+	ex := decodeBody[struct {
+		Data exampleDoc `json:"data"`
+	}](t, body).Data
+	if res.StatusCode != http.StatusOK || ex.Status != http.StatusNotFound || ex.Path != "/users/{id}" {
+		t.Fatalf("GET /examples/users.get.not_found: status %d: %s", res.StatusCode, body)
+	}
+}
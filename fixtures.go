@@ -0,0 +1,217 @@
+// File: /fixtures.go
+package main
+
+import (
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fixtureDataset = dataset kanonik untuk QA, hasilnya selalu sama untuk seed yang sama
+type fixtureDataset struct {
+	Seed  int64 `json:"seed"`
+	Users int   `json:"users"`
+	// NamePool > 0 membatasi jumlah nama unik (untuk dataset banyak duplikat)
+	NamePool int `json:"namePool,omitempty"`
+}
+
+// dataset disimpan di kode supaya ikut versi API
+var fixtureDatasets = map[string]fixtureDataset{
+	"small":          {Seed: 1, Users: 5},
+	"medium":         {Seed: 2, Users: 100},
+	"conflict-heavy": {Seed: 3, Users: 40, NamePool: 3},
+}
+
+// waktu dasar CreatedAt fixture, supaya deterministic
+var fixtureBaseTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const fixtureSampleSize = 5
+
+var (
+	fakeFirstNames = []string{"Alice", "Budi", "Citra", "Dewi", "Eko", "Fajar", "Gita", "Hadi", "Indah", "Joko"}
+	fakeLastNames  = []string{"Santoso", "Wijaya", "Pratama", "Lestari", "Hidayat", "Saputra", "Nugroho", "Kusuma"}
+)
+
+// fakeUserNames menghasilkan n nama palsu secara deterministic dari seed
+func fakeUserNames(seed int64, n int, pool int) []string {
+	rng := rand.New(rand.NewSource(seed))
+	fakeName := func() string {
+		return fakeFirstNames[rng.Intn(len(fakeFirstNames))] + " " + fakeLastNames[rng.Intn(len(fakeLastNames))]
+	}
+
+	out := make([]string, n)
+	if pool <= 0 {
+		for i := range out {
+			out[i] = fakeName()
+		}
+		return out
+	}
+
+	names := make([]string, pool)
+	for i := range names {
+		names[i] = fakeName()
+	}
+	for i := range out {
+		name := names[rng.Intn(pool)]
+		// variasi huruf kecil supaya bentrok secara case-insensitive
+		if rng.Intn(2) == 0 {
+			name = strings.ToLower(name)
+		}
+		out[i] = name
+	}
+	return out
+}
+
+func (d fixtureDataset) build() []User {
+	names := fakeUserNames(d.Seed, d.Users, d.NamePool)
+
+	users := make([]User, len(names))
+	for i, name := range names {
+		users[i] = User{
+			Name:      name,
+			CreatedAt: fixtureBaseTime.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return users
+}
+
+type installedFixture struct {
+	Name        string         `json:"name"`
+	Params      fixtureDataset `json:"params"`
+	InstalledAt time.Time      `json:"installedAt"`
+}
+
+type FixturesHandler struct {
+	store *UserStore
+
+	mu        sync.Mutex
+	installed *installedFixture
+}
+
+func NewFixturesHandler(store *UserStore) *FixturesHandler {
+	return &FixturesHandler{store: store}
+}
+
+// /admin/fixtures -> GET deskripsi dataset, POST install dataset
+func (h *FixturesHandler) HandleFixtures(w http.ResponseWriter, r *http.Request) {
+	if !requireMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.mu.Lock()
+		installed := h.installed
+		h.mu.Unlock()
+
+		writeData(w, http.StatusOK, apiResponse{
+			"resources": apiResponse{
+				"users": describeUsers(h.store.List()),
+			},
+			"seed":     installed,
+			"datasets": fixtureNames(),
+		}, nil)
+		return
+
+	case http.MethodPost:
+		type installFixtureRequest struct {
+			Name string `json:"name"`
+		}
+		req, err := decodeJSON[installFixtureRequest](w, r)
+		if err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+
+		installed, err := h.install(req.Name)
+		if err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+
+		writeData(w, http.StatusOK, apiResponse{
+			"installed": installed,
+			"resources": apiResponse{
+				"users": describeUsers(h.store.List()),
+			},
+		}, nil)
+		return
+	}
+}
+
+// POST /admin/reset (hanya terdaftar dengan -allow-reset)
+func (h *FixturesHandler) HandleReset(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	h.mu.Lock()
+	removed := h.store.Reset()
+	h.installed = nil
+	h.mu.Unlock()
+
+	logWarnf("store reset: %d users removed", removed)
+	writeData(w, http.StatusOK, apiResponse{
+		"removed": removed,
+	}, nil)
+}
+
+// install mengganti isi store dengan dataset bernama name
+func (h *FixturesHandler) install(name string) (*installedFixture, error) {
+	name = strings.TrimSpace(name)
+	dataset, ok := fixtureDatasets[name]
+	if !ok {
+		return nil, &AppError{
+			Status:  http.StatusBadRequest,
+			Code:    "validation_failed",
+			Message: "unknown fixture dataset",
+			Details: apiResponse{
+				"name":     name,
+				"datasets": fixtureNames(),
+			},
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.store.Replace(dataset.build())
+	h.installed = &installedFixture{
+		Name:        name,
+		Params:      dataset,
+		InstalledAt: time.Now().UTC(),
+	}
+	return h.installed, nil
+}
+
+func describeUsers(users []User) apiResponse {
+	sort.Slice(users, func(i, j int) bool { return lessUserID(users[i].ID, users[j].ID) })
+
+	desc := apiResponse{
+		"count": len(users),
+	}
+	if len(users) > 0 {
+		desc["minId"] = users[0].ID
+		desc["maxId"] = users[len(users)-1].ID
+	}
+
+	samples := users
+	if len(samples) > fixtureSampleSize {
+		samples = samples[:fixtureSampleSize]
+	}
+	desc["samples"] = samples
+
+	return desc
+}
+
+func fixtureNames() []string {
+	names := make([]string, 0, len(fixtureDatasets))
+	for name := range fixtureDatasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
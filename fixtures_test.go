@@ -0,0 +1,176 @@
+// File: /fixtures_test.go
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFixtureDatasetsInstallExactCounts(t *testing.T) {
+	want := map[string]struct {
+		users, maxDistinctNames int
+	}{
+		"small":          {users: 5},
+		"medium":         {users: 100},
+		"conflict-heavy": {users: 40, maxDistinctNames: 3},
+	}
+	if len(want) != len(fixtureDatasets) {
+		t.Fatalf("fixtureDatasets has %d datasets, test covers %d", len(fixtureDatasets), len(want))
+	}
+
+	for name, w := range want {
+		t.Run(name, func(t *testing.T) {
+			store := NewUserStore(IDModeInt)
+			store.Create("Leftover", RoleUser, nil)
+			h := NewFixturesHandler(store)
+
+			if _, err := h.install(name); err != nil {
+				t.Fatalf("install: %v", err)
+			}
+			users := store.List()
+			sort.Slice(users, func(i, j int) bool { return lessUserID(users[i].ID, users[j].ID) })
+			if len(users) != w.users {
+				t.Fatalf("installed %d users, want %d", len(users), w.users)
+			}
+			distinct := map[string]bool{}
+			for i, u := range users {
+				if u.Name == "Leftover" {
+					t.Fatalf("install kept the previous store contents")
+				}
+				if u.ID != UserID(strconv.Itoa(i+1)) {
+					t.Fatalf("users[%d].ID = %s, want %d", i, u.ID, i+1)
+				}
+				distinct[strings.ToLower(u.Name)] = true
+			}
+			if w.maxDistinctNames > 0 && len(distinct) > w.maxDistinctNames {
+				t.Fatalf("%d distinct names, want at most %d", len(distinct), w.maxDistinctNames)
+			}
+		})
+	}
+}
+
+func TestFixtureDatasetIsDeterministic(t *testing.T) {
+	a := fixtureDatasets["medium"].build()
+	b := fixtureDatasets["medium"].build()
+	for i := range a {
+		if a[i].Name != b[i].Name || !a[i].CreatedAt.Equal(b[i].CreatedAt) {
+			t.Fatalf("users[%d] differs between builds: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+}
+
+func TestAdminFixturesEndpoint(t *testing.T) {
+	ts := newTestServer(t, testConfig(withAdmin))
+
+	res, _ := doRequest(t, ts, "POST", "/admin/fixtures", `{"name":"small"}`)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("without credentials: status %d, want 401", res.StatusCode)
+	}
+
+	res, body := doRequest(t, ts, "POST", "/admin/fixtures", `{"name":"conflict-heavy"}`, adminHeader()...)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("install: status %d: %s", res.StatusCode, body)
+	}
+	var out struct {
+		Data struct {
+			Installed struct {
+				Name string `json:"name"`
+			} `json:"installed"`
+			Resources struct {
+				Users struct {
+					Count int `json:"count"`
+				} `json:"users"`
+			} `json:"resources"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Data.Installed.Name != "conflict-heavy" || out.Data.Resources.Users.Count != 40 {
+		t.Fatalf("install response = %s", body)
+	}
+
+	_, body = doRequest(t, ts, "GET", "/users?limit=1", "")
+	if !strings.Contains(body, `"total":40`) {
+		t.Fatalf("GET /users after install = %s", body)
+	}
+
+	res, body = doRequest(t, ts, "POST", "/admin/fixtures", `{"name":"huge"}`, adminHeader()...)
+	if res.StatusCode != http.StatusBadRequest || !strings.Contains(body, `"datasets":["conflict-heavy","medium","small"]`) {
+		t.Fatalf("unknown dataset: status %d: %s", res.StatusCode, body)
+	}
+}
+
+func TestAdminResetClearsStoreAndRestartsIDs(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		withAdmin(c)
+		c.AllowReset = true
+	}))
+	for _, name := range []string{"a", "b", "c"} {
+		doRequest(t, ts, "POST", "/users", `{"name":"`+name+`"}`)
+	}
+	doRequest(t, ts, "DELETE", "/users/2", "")
+
+	res, body := doRequest(t, ts, "POST", "/admin/reset", "", adminHeader()...)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("reset: status %d: %s", res.StatusCode, body)
+	}
+	var out struct {
+		Data struct {
+			Removed int `json:"removed"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &out); err != nil || out.Data.Removed != 2 {
+		t.Fatalf("reset body %s", body)
+	}
+
+	_, body = doRequest(t, ts, "GET", "/users", "")
+	if list := decodeBody[userListEnvelope](t, body); list.Meta.Total != 0 || len(list.Data) != 0 {
+		t.Fatalf("users after reset: %s", body)
+	}
+	_, body = doRequest(t, ts, "POST", "/users", `{"name":"fresh"}`)
+	if id := decodeBody[userEnvelope](t, body).Data.ID; id != "1" {
+		t.Fatalf("first id after reset = %s, want 1", id)
+	}
+
+	// reset kedua: hanya user baru tadi
+	_, body = doRequest(t, ts, "POST", "/admin/reset", "", adminHeader()...)
+	if !strings.Contains(body, `"removed":1`) {
+		t.Fatalf("second reset: %s", body)
+	}
+}
+
+func TestAdminResetGuards(t *testing.T) {
+	both := func(c *Config) {
+		withAdmin(c)
+		c.AllowReset = true
+	}
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		method string
+		header []string
+		status int
+	}{
+		{"no credentials", both, "POST", nil, http.StatusUnauthorized},
+		{"wrong password", both, "POST", []string{"Authorization", "Basic YWRtaW46bm9wZQ=="}, http.StatusUnauthorized},
+		{"GET", both, "GET", adminHeader(), http.StatusMethodNotAllowed},
+		{"without -allow-reset", withAdmin, "POST", adminHeader(), http.StatusNotFound},
+		{"without admin user", func(c *Config) { c.AllowReset = true }, "POST", adminHeader(), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		ts := newTestServer(t, testConfig(tt.mutate))
+		doRequest(t, ts, "POST", "/users", `{"name":"keep"}`)
+
+		if res, body := doRequest(t, ts, tt.method, "/admin/reset", "", tt.header...); res.StatusCode != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, res.StatusCode, tt.status, body)
+		}
+		if _, body := doRequest(t, ts, "GET", "/users", ""); decodeBody[userListEnvelope](t, body).Meta.Total != 1 {
+			t.Errorf("%s: store changed: %s", tt.name, body)
+		}
+	}
+}
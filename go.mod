@@ -1,3 +1,13 @@
 module golang-beginner-restapi
 
 go 1.25.6
+
+require (
+	golang.org/x/crypto v0.50.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/net v0.52.0 // indirect
+	golang.org/x/text v0.36.0 // indirect
+)
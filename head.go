@@ -0,0 +1,74 @@
+// File: /head.go
+package main
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// headAsGet: HEAD dijalankan sebagai GET, jadi route dan handler cukup
+// mengenal GET. Body dibuang headWriter; Content-Length = panjang body GET
+// kalau handler tidak mengisinya, sehingga header HEAD sama dengan GET.
+func headAsGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		get := r.Clone(r.Context())
+		get.Method = http.MethodGet
+
+		hw := &headWriter{ResponseWriter: w}
+		next.ServeHTTP(hw, get)
+		hw.send(true)
+	})
+}
+
+// headWriter menahan status sampai handler selesai (atau Flush) supaya
+// Content-Length bisa dihitung dari body yang dibuang
+type headWriter struct {
+	http.ResponseWriter
+	status int
+	n      int
+	sent   bool
+}
+
+func (hw *headWriter) WriteHeader(status int) {
+	if hw.status == 0 {
+		hw.status = status
+	}
+}
+
+func (hw *headWriter) Write(p []byte) (int, error) {
+	if hw.status == 0 {
+		hw.status = http.StatusOK
+	}
+	hw.n += len(p)
+	return len(p), nil
+}
+
+// send menulis status ke writer asli sekali. withLength=false (Flush,
+// response streaming) = panjang body belum diketahui.
+func (hw *headWriter) send(withLength bool) {
+	if hw.sent {
+		return
+	}
+	hw.sent = true
+	if hw.status == 0 {
+		hw.status = http.StatusOK
+	}
+	h := hw.ResponseWriter.Header()
+	if withLength && hw.n > 0 && h.Get("Content-Length") == "" {
+		h.Set("Content-Length", strconv.Itoa(hw.n))
+	}
+	hw.ResponseWriter.WriteHeader(hw.status)
+}
+
+func (hw *headWriter) Flush() {
+	hw.send(false)
+	_ = http.NewResponseController(hw.ResponseWriter).Flush()
+}
+
+func (hw *headWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
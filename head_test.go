@@ -0,0 +1,95 @@
+// File: /head_test.go
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// perRequestHeaders = header yang memang beda tiap request
+var perRequestHeaders = []string{"Date", "X-Request-Id", "X-Correlation-Id"}
+
+// assertHeadMatchesGet: HEAD path = header GET path (kecuali skip dan
+// perRequestHeaders), status sama, body kosong
+func assertHeadMatchesGet(t *testing.T, ts *httptest.Server, path string, skip ...string) {
+	t.Helper()
+	getRes, getBody := doRequest(t, ts, "GET", path, "")
+	headRes, headBody := doRequest(t, ts, "HEAD", path, "")
+
+	if headRes.StatusCode != getRes.StatusCode {
+		t.Errorf("HEAD %s: status %d, GET %d", path, headRes.StatusCode, getRes.StatusCode)
+	}
+	if headBody != "" {
+		t.Errorf("HEAD %s: body %q", path, headBody)
+	}
+	if getBody == "" {
+		t.Errorf("GET %s: empty body", path)
+	}
+	skip = append(skip, perRequestHeaders...)
+	for _, h := range []http.Header{getRes.Header, headRes.Header} {
+		for name := range h {
+			if slices.Contains(skip, name) {
+				continue
+			}
+			if got, want := headRes.Header.Values(name), getRes.Header.Values(name); !slices.Equal(got, want) {
+				t.Errorf("%s: HEAD %s = %q, GET %q", path, name, got, want)
+			}
+		}
+	}
+}
+
+func TestHeadUserMatchesGet(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+	res, _ := doRequest(t, ts, "HEAD", "/users/1", "")
+	if res.StatusCode != http.StatusOK || res.Header.Get("ETag") == "" || res.Header.Get("Content-Length") == "" {
+		t.Fatalf("HEAD /users/1: status %d, headers %v", res.StatusCode, res.Header)
+	}
+	for _, path := range []string{"/users/1", "/users", "/users?limit=1", "/users/1/profile", "/users/99", "/users/abc"} {
+		assertHeadMatchesGet(t, ts, path)
+	}
+
+	// HEAD hanya ada di route GET
+	res, body := doRequest(t, ts, "HEAD", "/sum", "")
+	if res.StatusCode != http.StatusMethodNotAllowed || body != "" {
+		t.Fatalf("HEAD /sum: status %d, body %q", res.StatusCode, body)
+	}
+	if got := res.Header.Get("Allow"); got != "POST, OPTIONS" {
+		t.Fatalf("HEAD /sum: Allow %q", got)
+	}
+}
+
+// setiap route GET di routeTable menjawab HEAD dengan header yang sama
+func TestHeadMatchesGetAllRoutes(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(nil), WithServerClock(clock.Now))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+	queries := map[string]string{"?name=": "?name=Ada", "?since=": "?since=2024-01-01T00:00:00Z"}
+	covered := 0
+	for _, rt := range routeTable {
+		if !slices.Contains(rt.methods, http.MethodGet) {
+			continue
+		}
+		covered++
+		path := concretePath(rt.pattern) + queries[rt.query]
+		t.Run(path, func(t *testing.T) {
+			var skip []string
+			if rt.pattern == "/metrics" {
+				// counter request ikut naik antara GET dan HEAD
+				skip = append(skip, "Content-Length")
+			}
+			assertHeadMatchesGet(t, ts, path, skip...)
+		})
+	}
+	// variasi representasi dan error di route yang sama
+	for _, path := range []string{"/users?format=ndjson", "/users/exists", "/echo", "/examples/nope"} {
+		t.Run(path, func(t *testing.T) { assertHeadMatchesGet(t, ts, path) })
+	}
+	if covered < 15 {
+		t.Fatalf("only %d GET routes in routeTable", covered)
+	}
+}
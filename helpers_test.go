@@ -0,0 +1,283 @@
+// File: /helpers_test.go
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMain(m *testing.M) {
+	// log startup (admin routes disabled, dll) tidak perlu di output test;
+	// test yang memeriksa log memasang handler sendiri
+	setupLogging("text", io.Discard)
+	setLogLevel(levelError)
+	os.Exit(m.Run())
+}
+
+// testEpoch = waktu awal fakeClock di semua test
+var testEpoch = time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+// fakeClock = jam yang hanya maju lewat Advance
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: testEpoch}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// testConfig = DefaultConfig dengan bcrypt cepat; mutate boleh nil
+func testConfig(mutate func(*Config)) Config {
+	cfg := DefaultConfig()
+	cfg.BcryptCost = 4
+	if mutate != nil {
+		mutate(&cfg)
+	}
+	return cfg
+}
+
+const (
+	testAdminUser     = "admin"
+	testAdminPassword = "admin-secret"
+)
+
+// withAdmin mengaktifkan route admin dengan Basic auth testAdminUser/testAdminPassword
+func withAdmin(c *Config) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(testAdminPassword), bcrypt.MinCost)
+	if err != nil {
+		panic(err)
+	}
+	c.AdminUser = testAdminUser
+	c.AdminPasswordHash = string(hash)
+}
+
+// adminHeader = argumen header doRequest untuk Basic auth admin
+func adminHeader() []string {
+	return basicHeader(testAdminUser, testAdminPassword)
+}
+
+// basicHeader = argumen header doRequest untuk Basic auth sembarang user
+func basicHeader(user, pass string) []string {
+	return []string{"Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))}
+}
+
+// newTestServer = NewServer lengkap dengan middleware, dibungkus httptest.Server
+func newTestServer(t *testing.T, cfg Config, opts ...ServerOption) *httptest.Server {
+	t.Helper()
+	srv, err := NewServer(cfg, opts...)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		_ = srv.Close()
+	})
+	return ts
+}
+
+// newTestServerWithRoutes = newTestServer dengan route tambahan khusus test
+// (mis. handler yang panic) di belakang rantai middleware yang sama.
+// Server ikut dikembalikan untuk test Reload.
+func newTestServerWithRoutes(t *testing.T, cfg Config, routes map[string]http.HandlerFunc, opts ...ServerOption) (*Server, *httptest.Server) {
+	t.Helper()
+	srv, err := NewServer(cfg, opts...)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", srv.root)
+	for pattern, h := range routes {
+		mux.HandleFunc(pattern, h)
+	}
+	srv.root = mux
+	chain := srv.buildChain(cfg)
+	srv.chain.Store(&chain)
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		_ = srv.Close()
+	})
+	return srv, ts
+}
+
+// doRequest mengirim request ke ts tanpa mengikuti redirect.
+// header berisi pasangan "Name", "value".
+func doRequest(t *testing.T, ts *httptest.Server, method, path, body string, header ...string) (*http.Response, string) {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, ts.URL+path, r)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return res, string(data)
+}
+
+// withAccessLog menulis access log ke file temp dengan format; hasilnya
+// dibaca lewat waitAccessLog
+func withAccessLog(t *testing.T, format string) (func(*Config), string) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	return func(c *Config) {
+		c.AccessLog = path
+		c.AccessLogFormat = format
+	}, path
+}
+
+// waitAccessLog menunggu sampai file berisi n baris. Access log ditulis setelah
+// handler selesai, jadi bisa sedikit terlambat dari response di client.
+func waitAccessLog(t *testing.T, path string, n int) []string {
+	t.Helper()
+	var lines []string
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		data, _ := os.ReadFile(path)
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(data) > 0 && len(lines) >= n {
+			return lines
+		}
+	}
+	t.Fatalf("access log has %d lines, want %d", len(lines), n)
+	return nil
+}
+
+// logBuffer = log aplikasi (format json) yang ditangkap captureLog
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// Records = tiap baris log sebagai map
+func (b *logBuffer) Records(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line != "" {
+			out = append(out, decodeBody[map[string]any](t, line))
+		}
+	}
+	return out
+}
+
+// captureLog mengarahkan log default ke buffer (level l) sampai test
+// selesai. Logger global, jadi test pemakainya tidak boleh t.Parallel.
+func captureLog(t *testing.T, l logLevel) *logBuffer {
+	b := &logBuffer{}
+	setupLogging("json", b)
+	setLogLevel(l)
+	t.Cleanup(func() {
+		setupLogging("text", io.Discard)
+		setLogLevel(levelError)
+	})
+	return b
+}
+
+// decodeBody mendecode body response JSON ke T (biasanya struct envelope)
+func decodeBody[T any](t *testing.T, body string) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		t.Fatalf("decode %q: %v", body, err)
+	}
+	return v
+}
+
+// userEnvelope = {"data": user} dari endpoint user tunggal
+type userEnvelope struct {
+	Data struct {
+		ID           UserID     `json:"id"`
+		Name         string     `json:"name"`
+		Role         Role       `json:"role"`
+		CreatedAt    time.Time  `json:"createdAt"`
+		UpdatedAt    time.Time  `json:"updatedAt"`
+		DeletedAt    *time.Time `json:"deletedAt"`
+		LastActiveAt time.Time  `json:"lastActiveAt"`
+		Version      int        `json:"version"`
+	} `json:"data"`
+}
+
+// userListEnvelope = body GET /users
+type userListEnvelope struct {
+	Data []struct {
+		ID   UserID `json:"id"`
+		Name string `json:"name"`
+		Role Role   `json:"role"`
+	} `json:"data"`
+	Meta struct {
+		Total int `json:"total"`
+	} `json:"meta"`
+}
+
+// errorResponse = body AppError
+type errorResponse struct {
+	Error         string `json:"error"`
+	Message       string `json:"message"`
+	Details       any    `json:"details"`
+	RequestID     string `json:"requestId"`
+	CorrelationID string `json:"correlationId"`
+}
+
+// asAppError gagal kalau err bukan *AppError
+func asAppError(t *testing.T, err error) *AppError {
+	t.Helper()
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		t.Fatalf("error %v (%T) is not an *AppError", err, err)
+	}
+	return appErr
+}
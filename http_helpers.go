@@ -1,23 +1,85 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
+	"mime"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 func writeJSON(w http.ResponseWriter, status int, payload any) {
+	// encode ke buffer dulu supaya body ditulis sekali jalan,
+	// tidak setengah jadi kalau koneksi diputus (mis. write timeout)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if jsonPretty(w) {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(payload); err != nil {
+		logErrorf("encode response: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":"internal_error","message":"unexpected error"}` + "\n"))
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
 	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// writeData menulis response sukses dalam bentuk standar dataBody
+func writeData(w http.ResponseWriter, status int, data any, meta apiResponse) {
+	writeJSON(w, status, dataBody(data, meta))
+}
+
+// writeCreated = 201 + Location untuk semua endpoint create. location harus
+// URL kanonik resource baru yang sudah ikut BasePath (pakai linkBuilder).
+func writeCreated(w http.ResponseWriter, location string, data any, meta apiResponse) {
+	w.Header().Set("Location", location)
+	writeData(w, http.StatusCreated, data, meta)
+}
 
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(payload)
+// dataBody = bentuk standar response sukses {"data": ..., "meta": {...}}.
+// meta nil ditulis sebagai {} supaya client selalu menemukan kedua key.
+func dataBody(data any, meta apiResponse) apiResponse {
+	if meta == nil {
+		meta = apiResponse{}
+	}
+	return apiResponse{
+		"data": data,
+		"meta": meta,
+	}
 }
 
+// errorJSON juga menulis requestId dan correlationId (dari header response
+// yang sudah dipasang withRequestID/withCorrelationID) supaya client bisa
+// mengutipnya saat lapor error
 func errorJSON(w http.ResponseWriter, status int, code string, message string, details any) {
+	body := errorBody(code, message, details)
+	if id := w.Header().Get(requestIDHeader); id != "" {
+		body["requestId"] = id
+	}
+	if id := w.Header().Get(correlationIDHeader); id != "" {
+		body["correlationId"] = id
+	}
+	// error tidak pernah di-cache, walau handler sudah memanggil setCache
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, body)
+}
+
+// errorBody = bentuk standar {"error","message","details"}
+func errorBody(code string, message string, details any) map[string]any {
 	resp := map[string]any{
 		"error":   code,
 		"message": message,
@@ -25,13 +87,92 @@ func errorJSON(w http.ResponseWriter, status int, code string, message string, d
 	if details != nil {
 		resp["details"] = details
 	}
-	writeJSON(w, status, resp)
+	return resp
 }
 
-func readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
-	// limit 1MB
-	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+// statusClientClosedRequest (499, konvensi nginx) hanya untuk log/metrics
+const statusClientClosedRequest = 499
+
+// writeAppError menulis *AppError yang sudah disanitasi; error lain jadi 500
+// generik. Error yang dibungkus (fmt.Errorf("...: %w", appErr)) atau AppError
+// dengan Err dicatat lengkap di log server; error non-AppError dicatat
+// sebagai ERROR.
+func writeAppError(w http.ResponseWriter, r *http.Request, err error) {
+	// client sudah memutus koneksi: tidak ada yang membaca response
+	if errors.Is(err, context.Canceled) {
+		requestLog(r.Context()).Debug("request canceled by client")
+		errorJSON(w, statusClientClosedRequest, "client_closed_request", "request canceled", nil)
+		return
+	}
+	// deadline -request-timeout: 504 biasanya sudah dikirim requestTimeout
+	if errors.Is(err, context.DeadlineExceeded) {
+		requestLog(r.Context()).Debug("request deadline exceeded")
+		errorJSON(w, http.StatusGatewayTimeout, "request_timeout", "request took too long", nil)
+		return
+	}
+
+	var ae *AppError
+	if !errors.As(err, &ae) {
+		requestLog(r.Context()).Error("unhandled error", "error", err)
+		errorJSON(w, http.StatusInternalServerError, "internal_error", "unexpected error", nil)
+		return
+	}
 
+	if err != error(ae) || ae.Err != nil {
+		requestLog(r.Context()).Warn("request failed", "code", ae.Code, "status", ae.Status, "error", err)
+	}
+	if ae.RetryAfter > 0 {
+		w.Header().Set("Retry-After", retryAfterSeconds(ae.RetryAfter))
+	}
+	errorJSON(w, ae.Status, ae.Code, ae.Message, ae.Details)
+}
+
+// retryAfterSeconds = nilai Retry-After dalam delta-seconds, minimal 1
+func retryAfterSeconds(d time.Duration) string {
+	secs := int64((d + time.Second - 1) / time.Second)
+	return strconv.FormatInt(max(secs, 1), 10)
+}
+
+// requireJSONContentType: body harus application/json (charset hanya utf-8).
+// Request tanpa body (Content-Length 0, atau chunked yang ternyata kosong)
+// dibiarkan lewat, decode yang menentukan body wajib atau tidak.
+func requireJSONContentType(r *http.Request) error {
+	if r.ContentLength == 0 || emptyBody(r) {
+		return nil
+	}
+
+	ct := r.Header.Get("Content-Type")
+	mt, params, err := mime.ParseMediaType(ct)
+	if err == nil && mt == "application/json" {
+		if cs, ok := params["charset"]; !ok || strings.EqualFold(cs, "utf-8") {
+			return nil
+		}
+	}
+	return &AppError{
+		Status:  http.StatusUnsupportedMediaType,
+		Code:    "unsupported_media_type",
+		Message: "Content-Type must be application/json",
+		Details: apiResponse{"contentType": ct},
+	}
+}
+
+// emptyBody: panjang body tidak diketahui (ContentLength -1, mis. chunked)
+// dan byte pertama = EOF. Byte yang sudah diintip dipasang lagi di r.Body.
+func emptyBody(r *http.Request) bool {
+	if r.ContentLength >= 0 || r.Body == nil {
+		return false
+	}
+	br := bufio.NewReader(r.Body)
+	_, err := br.Peek(1)
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{br, r.Body}
+	return errors.Is(err, io.EOF)
+}
+
+// readJSON decode body JSON; batas ukuran body dipasang oleh middleware limitBody
+func readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 
@@ -43,31 +184,109 @@ func readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
 		return errors.New("unexpected extra JSON content")
 	}
-
 	return nil
 }
 
-func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
-	if r.Method == method {
-		return true
+// Validator diimplementasikan request body yang punya aturan validasi sendiri
+type Validator interface {
+	Validate() error
+}
+
+// BodyValidator memeriksa body mentah sebelum decode (mis. JSON Schema).
+// Opsional: validasi di kode (Validator) tetap jalan sesudahnya.
+type BodyValidator interface {
+	ValidateBody(data []byte) error
+}
+
+// decodeJSONWith = decodeJSON dengan BodyValidator (nil = sama dengan decodeJSON)
+func decodeJSONWith[T any](w http.ResponseWriter, r *http.Request, bv BodyValidator) (T, error) {
+	if bv == nil {
+		return decodeJSON[T](w, r)
+	}
+	if err := requireJSONContentType(r); err != nil {
+		var zero T
+		return zero, err
 	}
 
-	errorJSON(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", map[string]any{
-		"method": r.Method,
-		"allow":  method,
-	})
-	return false
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		var zero T
+		return zero, &AppError{
+			Status:  http.StatusBadRequest,
+			Code:    "invalid_json",
+			Message: err.Error(),
+		}
+	}
+	if err := bv.ValidateBody(data); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	return decodeJSON[T](w, r)
+}
+
+// decodeJSON = readJSON + Validate dalam satu panggilan.
+// Error selalu berupa *AppError sehingga bisa langsung ke writeAppError.
+func decodeJSON[T any](w http.ResponseWriter, r *http.Request) (T, error) {
+	if err := requireJSONContentType(r); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	var v T
+	if err := readJSON(w, r, &v); err != nil {
+		var zero T
+		return zero, &AppError{
+			Status:  http.StatusBadRequest,
+			Code:    "invalid_json",
+			Message: err.Error(),
+		}
+	}
+
+	if vv, ok := any(v).(Validator); ok {
+		if err := vv.Validate(); err != nil {
+			var zero T
+			return zero, err
+		}
+	}
+	return v, nil
+}
+
+// validationError = AppError 400 standar dengan daftar field yang salah
+func validationError(message string, details []string) *AppError {
+	e := NewValidation(details)
+	e.Message = message
+	return e
+}
+
+// requireMethod = requireMethods dengan satu method: Allow, OPTIONS dan
+// details.allow (array) sama persis
+func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	return requireMethods(w, r, method)
 }
 
+// requireMethods: allowed = daftar method yang didaftarkan untuk path ini.
+// GET berarti HEAD juga (headAsGet biasanya sudah mengubah HEAD jadi GET).
+// OPTIONS selalu dijawab di sini (204 + Allow), method lain di luar daftar = 405.
+// false = response sudah ditulis, handler langsung return.
 func requireMethods(w http.ResponseWriter, r *http.Request, allowed ...string) bool {
 	for _, m := range allowed {
 		if r.Method == m {
 			return true
 		}
 	}
+	if r.Method == http.MethodHead && slices.Contains(allowed, http.MethodGet) {
+		return true
+	}
+	allowed = allowList(allowed)
 
 	// header Allow (best practice HTTP)
 	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return false
+	}
 
 	errorJSON(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", map[string]any{
 		"method": r.Method,
@@ -75,3 +294,30 @@ func requireMethods(w http.ResponseWriter, r *http.Request, allowed ...string) b
 	})
 	return false
 }
+
+// batas panjang path yang di-echo ke response body / log
+const maxEchoedPathBytes = 256
+
+const truncatedMarker = "...(truncated)"
+
+// truncatePath memotong path panjang supaya tidak memenuhi log / response
+func truncatePath(p string) string {
+	if len(p) <= maxEchoedPathBytes {
+		return p
+	}
+
+	cut := maxEchoedPathBytes
+	// jangan memotong di tengah karakter UTF-8
+	for cut > 0 && !utf8.RuneStart(p[cut]) {
+		cut--
+	}
+	return p[:cut] + truncatedMarker
+}
+
+// notFound = 404 JSON standar untuk path tanpa route; juga catch-all "/"
+// di router, jadi tidak ada path yang jatuh ke 404 teks bawaan net/http
+func notFound(w http.ResponseWriter, r *http.Request) {
+	errorJSON(w, http.StatusNotFound, "not_found", "resource not found", apiResponse{
+		"path": truncatePath(r.URL.Path),
+	})
+}
@@ -0,0 +1,392 @@
+// File: /http_helpers_test.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPayload = body dengan Validate sendiri, seperti request body handler
+type testPayload struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func (p testPayload) Validate() error {
+	var details []string
+	if strings.TrimSpace(p.Name) == "" {
+		details = append(details, "name is required")
+	}
+	if p.Count < 0 {
+		details = append(details, "count must not be negative")
+	}
+	if len(details) > 0 {
+		return validationError("invalid body", details)
+	}
+	return nil
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{name: "valid", contentType: "application/json", body: `{"name":"a","count":2}`},
+		{name: "valid with utf-8 charset", contentType: "application/json; charset=UTF-8", body: `{"name":"a"}`},
+		{name: "fails Validate", contentType: "application/json", body: `{"name":" ","count":-1}`, wantStatus: 400, wantCode: "validation_failed", wantMessage: "invalid body"},
+		{name: "unknown field", contentType: "application/json", body: `{"name":"a","extra":1}`, wantStatus: 400, wantCode: "invalid_json", wantMessage: `json: unknown field "extra"`},
+		{name: "wrong type", contentType: "application/json", body: `{"name":1}`, wantStatus: 400, wantCode: "invalid_json"},
+		{name: "trailing content", contentType: "application/json", body: `{"name":"a"} {}`, wantStatus: 400, wantCode: "invalid_json", wantMessage: "unexpected extra JSON content"},
+		{name: "malformed", contentType: "application/json", body: `{"name":`, wantStatus: 400, wantCode: "invalid_json"},
+		{name: "empty body", contentType: "application/json", body: ``, wantStatus: 400, wantCode: "invalid_json", wantMessage: "EOF"},
+		{name: "text/plain", contentType: "text/plain", body: `{"name":"a"}`, wantStatus: 415, wantCode: "unsupported_media_type"},
+		{name: "latin1 charset", contentType: "application/json; charset=latin1", body: `{"name":"a"}`, wantStatus: 415, wantCode: "unsupported_media_type"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/x", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", tt.contentType)
+		got, err := decodeJSON[testPayload](httptest.NewRecorder(), r)
+
+		if tt.wantStatus == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			} else if got.Name != "a" {
+				t.Errorf("%s: decoded %+v", tt.name, got)
+			}
+			continue
+		}
+		appErr := asAppError(t, err)
+		if appErr.Status != tt.wantStatus || appErr.Code != tt.wantCode {
+			t.Errorf("%s: got %d %s, want %d %s", tt.name, appErr.Status, appErr.Code, tt.wantStatus, tt.wantCode)
+		}
+		if tt.wantMessage != "" && appErr.Message != tt.wantMessage {
+			t.Errorf("%s: message %q, want %q", tt.name, appErr.Message, tt.wantMessage)
+		}
+		if got != (testPayload{}) {
+			t.Errorf("%s: error returned non-zero value %+v", tt.name, got)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/x", strings.NewReader(`{"name":"","count":-1}`))
+	r.Header.Set("Content-Type", "application/json")
+	_, err := decodeJSON[testPayload](httptest.NewRecorder(), r)
+	details, _ := asAppError(t, err).Details.([]string)
+	if strings.Join(details, "; ") != "name is required; count must not be negative" {
+		t.Fatalf("validation details = %v", asAppError(t, err).Details)
+	}
+}
+
+// topKeys = key level atas object JSON, terurut
+func topKeys(t *testing.T, body string) []string {
+	t.Helper()
+	m := decodeBody[map[string]json.RawMessage](t, body)
+	return slices.Sorted(maps.Keys(m))
+}
+
+func TestResponseEnvelope(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	_, created := doRequest(t, ts, "POST", "/users", `{"name":"Alice Doe"}`)
+	_, single := doRequest(t, ts, "GET", "/users/1", "")
+	_, list := doRequest(t, ts, "GET", "/users?limit=1", "")
+	_, failed := doRequest(t, ts, "GET", "/users/99", "")
+
+	for name, body := range map[string]string{"create": created, "single": single, "list": list} {
+		if got := topKeys(t, body); !slices.Equal(got, []string{"data", "meta"}) {
+			t.Errorf("%s envelope keys = %v, want [data meta]", name, got)
+		}
+	}
+
+	// resource tunggal: data object, meta kosong
+	env := decodeBody[struct {
+		Data map[string]any `json:"data"`
+		Meta map[string]any `json:"meta"`
+	}](t, single)
+	if env.Data["name"] != "Alice Doe" || len(env.Meta) != 0 {
+		t.Errorf("single = %s", single)
+	}
+
+	// list: data array, meta berisi pagination
+	listEnv := decodeBody[struct {
+		Data []map[string]any           `json:"data"`
+		Meta map[string]json.RawMessage `json:"meta"`
+	}](t, list)
+	if len(listEnv.Data) != 1 {
+		t.Errorf("list data = %v", listEnv.Data)
+	}
+	if got := slices.Sorted(maps.Keys(listEnv.Meta)); !slices.Equal(got, []string{"_links", "count", "limit", "offset", "total"}) {
+		t.Errorf("list meta keys = %v", got)
+	}
+
+	// error tidak memakai envelope data/meta
+	if got := topKeys(t, failed); !slices.Equal(got, []string{"correlationId", "error", "message", "requestId"}) {
+		t.Errorf("error keys = %v", got)
+	}
+}
+
+// findLog = record pertama dengan msg tersebut, nil kalau tidak ada
+func findLog(records []map[string]any, msg string) map[string]any {
+	for _, rec := range records {
+		if rec["msg"] == msg {
+			return rec
+		}
+	}
+	return nil
+}
+
+// TestWriteAppErrorLogsCause: penyebab asli masuk log server (dengan
+// request_id), client hanya menerima AppError yang sudah disanitasi
+func TestWriteAppErrorLogsCause(t *testing.T) {
+	cause := errors.New("dial tcp 10.0.0.5:5432: password=hunter2 rejected")
+	tests := []struct {
+		name      string
+		err       error
+		wantCode  string
+		wantMsg   string
+		wantLevel string
+		wantLog   string
+	}{
+		{
+			name:      "plain error",
+			err:       fmt.Errorf("load user: %w", cause),
+			wantCode:  "internal_error",
+			wantMsg:   "unexpected error",
+			wantLevel: "ERROR",
+			wantLog:   "unhandled error",
+		},
+		{
+			name:      "AppError with cause",
+			err:       &AppError{Status: http.StatusServiceUnavailable, Code: "store_unavailable", Message: "store unavailable", Err: cause},
+			wantCode:  "store_unavailable",
+			wantMsg:   "store unavailable",
+			wantLevel: "WARN",
+			wantLog:   "request failed",
+		},
+		{
+			name:      "wrapped AppError",
+			err:       fmt.Errorf("update: %w", NewNotFound("user not found")),
+			wantCode:  "not_found",
+			wantMsg:   "user not found",
+			wantLevel: "WARN",
+			wantLog:   "request failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t, levelWarn)
+			h := withRequestID(requestLogger(nil, nil, false, 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeAppError(w, r, tt.err)
+			})))
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/users/1", nil)
+			req.Header.Set(requestIDHeader, "req-551")
+			h.ServeHTTP(rec, req)
+
+			body := rec.Body.String()
+			got := decodeBody[errorResponse](t, body)
+			if got.Error != tt.wantCode || got.Message != tt.wantMsg || got.RequestID != "req-551" {
+				t.Fatalf("body %s", body)
+			}
+			if strings.Contains(body, "hunter2") || strings.Contains(body, "10.0.0.5") || strings.Contains(body, "load user") {
+				t.Fatalf("cause leaked to client: %s", body)
+			}
+
+			line := findLog(logs.Records(t), tt.wantLog)
+			if line == nil {
+				t.Fatalf("no %q log line in %v", tt.wantLog, logs.Records(t))
+			}
+			if line["level"] != tt.wantLevel || line["request_id"] != "req-551" {
+				t.Errorf("log line %v", line)
+			}
+			if msg, _ := line["error"].(string); !strings.Contains(msg, tt.err.Error()) {
+				t.Errorf("logged error %q, want the full chain %q", msg, tt.err.Error())
+			}
+		})
+	}
+}
+
+// AppError tanpa penyebab = error biasa ke client, tidak perlu log
+func TestWriteAppErrorWithoutCauseIsQuiet(t *testing.T) {
+	logs := captureLog(t, levelDebug)
+	rec := httptest.NewRecorder()
+	writeAppError(rec, httptest.NewRequest("GET", "/x", nil), NewNotFound("user not found"))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status %d", rec.Code)
+	}
+	if line := findLog(logs.Records(t), "request failed"); line != nil {
+		t.Errorf("unexpected log line %v", line)
+	}
+}
+
+func TestWriteAppErrorRetryAfter(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       string // "" = tanpa header
+	}{
+		{0, ""},
+		{-time.Second, ""},
+		{time.Nanosecond, "1"},
+		{time.Second, "1"},
+		{1500 * time.Millisecond, "2"},
+		{30 * time.Second, "30"},
+		{2 * time.Minute, "120"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		err := &AppError{Status: http.StatusServiceUnavailable, Code: "not_ready", Message: "not ready", RetryAfter: tt.retryAfter}
+		writeAppError(rec, httptest.NewRequest("GET", "/x", nil), err)
+
+		got, ok := rec.Result().Header["Retry-After"]
+		switch {
+		case tt.want == "" && ok:
+			t.Errorf("RetryAfter %v: unexpected Retry-After %q", tt.retryAfter, got)
+		case tt.want != "" && (len(got) != 1 || got[0] != tt.want):
+			t.Errorf("RetryAfter %v: Retry-After %q, want %q", tt.retryAfter, got, tt.want)
+		}
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("RetryAfter %v: status %d", tt.retryAfter, rec.Code)
+		}
+	}
+}
+
+func TestRequireJSONContentType(t *testing.T) {
+	tests := []struct {
+		name, contentType, body string
+		contentLength           int64
+		want415                 bool
+	}{
+		{"json", "application/json", `{}`, 2, false},
+		{"text/plain", "text/plain", `{}`, 2, true},
+		{"missing", "", `{}`, 2, true},
+		{"no body", "", ``, 0, false},
+		// chunked: panjang tidak diketahui, body kosong = sama dengan Content-Length 0
+		{"chunked empty", "", ``, -1, false},
+		{"chunked empty text/plain", "text/plain", ``, -1, false},
+		{"chunked text/plain", "text/plain", `{}`, -1, true},
+		{"chunked json", "application/json", `{}`, -1, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/x", strings.NewReader(tt.body))
+		r.ContentLength = tt.contentLength
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		err := requireJSONContentType(r)
+		if tt.want415 {
+			if appErr := asAppError(t, err); appErr.Status != http.StatusUnsupportedMediaType || appErr.Code != "unsupported_media_type" {
+				t.Errorf("%s: error %+v", tt.name, appErr)
+			}
+		} else if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		// byte yang diintip tidak hilang
+		var rest strings.Builder
+		if _, err := io.Copy(&rest, r.Body); err != nil || rest.String() != tt.body {
+			t.Errorf("%s: body after check = %q, %v; want %q", tt.name, rest.String(), err, tt.body)
+		}
+	}
+}
+
+// endpoint tulis menjawab 415 untuk text/plain; body chunked kosong
+// diperlakukan sama dengan body kosong biasa
+func TestWriteEndpointsRejectTextPlain(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+	for _, tt := range []struct{ method, path string }{
+		{"POST", "/users"},
+		{"PUT", "/users/1"},
+		{"PATCH", "/users/1"},
+		{"PATCH", "/users/1/profile"},
+		{"POST", "/sum"},
+	} {
+		res, body := doRequest(t, ts, tt.method, tt.path, `{"name":"Grace"}`, "Content-Type", "text/plain")
+		got := decodeBody[errorResponse](t, body)
+		if res.StatusCode != http.StatusUnsupportedMediaType || got.Error != "unsupported_media_type" {
+			t.Errorf("%s %s: status %d: %s", tt.method, tt.path, res.StatusCode, body)
+		}
+		if details, _ := got.Details.(map[string]any); details["contentType"] != "text/plain" {
+			t.Errorf("%s %s: details %v", tt.method, tt.path, got.Details)
+		}
+	}
+
+	send := func(chunked bool) (int, string) {
+		req, err := http.NewRequest("POST", ts.URL+"/sum", strings.NewReader(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunked {
+			req.TransferEncoding = []string{"chunked"}
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		data, _ := io.ReadAll(res.Body)
+		return res.StatusCode, decodeBody[errorResponse](t, string(data)).Error
+	}
+	plainStatus, plainCode := send(false)
+	chunkedStatus, chunkedCode := send(true)
+	if plainStatus != http.StatusBadRequest || chunkedStatus != plainStatus || chunkedCode != plainCode {
+		t.Fatalf("empty body: %d %s, chunked empty body: %d %s", plainStatus, plainCode, chunkedStatus, chunkedCode)
+	}
+}
+
+// requireMethod dan requireMethods menulis 405 yang persis sama: Allow di
+// header dan details.allow selalu array
+func TestRequireMethodExactJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		call      func(w http.ResponseWriter, r *http.Request) bool
+		wantOK    bool
+		wantCode  int
+		wantAllow string
+		wantBody  string
+	}{
+		{"requireMethod", "GET", func(w http.ResponseWriter, r *http.Request) bool {
+			return requireMethod(w, r, http.MethodPost)
+		}, false, http.StatusMethodNotAllowed, "POST, OPTIONS",
+			`{"details":{"allow":["POST","OPTIONS"],"method":"GET"},"error":"method_not_allowed","message":"method not allowed"}` + "\n"},
+		{"requireMethods", "DELETE", func(w http.ResponseWriter, r *http.Request) bool {
+			return requireMethods(w, r, http.MethodGet, http.MethodPost)
+		}, false, http.StatusMethodNotAllowed, "GET, POST, HEAD, OPTIONS",
+			`{"details":{"allow":["GET","POST","HEAD","OPTIONS"],"method":"DELETE"},"error":"method_not_allowed","message":"method not allowed"}` + "\n"},
+		{"requireMethod OPTIONS", "OPTIONS", func(w http.ResponseWriter, r *http.Request) bool {
+			return requireMethod(w, r, http.MethodGet)
+		}, false, http.StatusNoContent, "GET, HEAD, OPTIONS", ""},
+		{"requireMethod HEAD on GET", "HEAD", func(w http.ResponseWriter, r *http.Request) bool {
+			return requireMethod(w, r, http.MethodGet)
+		}, true, http.StatusOK, "", ""},
+		{"requireMethods allowed", "POST", func(w http.ResponseWriter, r *http.Request) bool {
+			return requireMethods(w, r, http.MethodGet, http.MethodPost)
+		}, true, http.StatusOK, "", ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		ok := tt.call(rec, httptest.NewRequest(tt.method, "/x", nil))
+		if ok != tt.wantOK || rec.Code != tt.wantCode {
+			t.Errorf("%s: ok %v status %d, want %v %d", tt.name, ok, rec.Code, tt.wantOK, tt.wantCode)
+		}
+		if got := rec.Header().Get("Allow"); got != tt.wantAllow {
+			t.Errorf("%s: Allow %q, want %q", tt.name, got, tt.wantAllow)
+		}
+		if got := rec.Body.String(); got != tt.wantBody {
+			t.Errorf("%s: body\n%s\nwant\n%s", tt.name, got, tt.wantBody)
+		}
+	}
+}
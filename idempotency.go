@@ -0,0 +1,234 @@
+// File: /idempotency.go
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	// idempotencyReplayedHeader = ditambahkan di response yang diputar ulang dari cache
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	// idempotencySweepEvery = jarak minimal antar pembersihan entry kedaluwarsa
+	idempotencySweepEvery = time.Minute
+)
+
+// idempotencyEntry = hasil request pertama untuk satu key. done ditutup
+// setelah response tersimpan; request lain dengan key sama menunggu di situ.
+type idempotencyEntry struct {
+	bodyHash [32]byte
+	done     chan struct{}
+
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// idempotencyCache menyimpan response POST per Idempotency-Key selama ttl.
+// Key dipisah per client (label API key), jadi client lain tidak bisa
+// memutar ulang response milik client lain.
+type idempotencyCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+// newIdempotencyCache mengembalikan nil kalau ttl <= 0 (header diabaikan).
+// now = jam server, jadi kedaluwarsa ikut WithServerClock.
+func newIdempotencyCache(ttl time.Duration, now func() time.Time) *idempotencyCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &idempotencyCache{ttl: ttl, now: now, entries: map[string]*idempotencyEntry{}}
+}
+
+// start mencari atau membuat entry untuk key. owner = true berarti
+// pemanggil adalah request pertama dan wajib memanggil finish / abandon.
+func (c *idempotencyCache) start(key string, bodyHash [32]byte) (e *idempotencyEntry, owner bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.Sub(c.lastSweep) >= idempotencySweepEvery {
+		for k, e := range c.entries {
+			if isDone(e) && now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if e, ok := c.entries[key]; ok && !(isDone(e) && now.After(e.expires)) {
+		return e, false
+	}
+	e = &idempotencyEntry{bodyHash: bodyHash, done: make(chan struct{})}
+	c.entries[key] = e
+	return e, true
+}
+
+// finish menyimpan response request pertama dan membangunkan yang menunggu
+func (c *idempotencyCache) finish(e *idempotencyEntry, rec *idempotencyRecorder) {
+	c.mu.Lock()
+	e.status = rec.status
+	e.header = rec.header
+	e.body = rec.body.Bytes()
+	e.expires = c.now().Add(c.ttl)
+	c.mu.Unlock()
+	close(e.done)
+}
+
+// abandon: response tidak disimpan (5xx, panic), key boleh dicoba ulang.
+// Request yang sedang menunggu dapat status 0 dan ikut mencoba ulang.
+func (c *idempotencyCache) abandon(key string, e *idempotencyEntry) {
+	c.mu.Lock()
+	if c.entries[key] == e {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	close(e.done)
+}
+
+func isDone(e *idempotencyEntry) bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// serve menjalankan next paling banyak sekali per (client, Idempotency-Key).
+// Tanpa header (atau cache nil) next dipanggil langsung. Body sama = response
+// pertama diputar ulang; body beda = 409 idempotency_conflict.
+func (c *idempotencyCache) serve(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key := r.Header.Get(idempotencyHeader)
+	if c == nil || key == "" {
+		next(w, r)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		writeAppError(w, r, validationError("invalid header", []string{
+			idempotencyHeader + " must be at most 255 characters",
+		}))
+		return
+	}
+
+	var body []byte
+	if r.Body != nil {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				errorJSON(w, http.StatusRequestEntityTooLarge, "payload_too_large", "request body too large", nil)
+				return
+			}
+			writeAppError(w, r, &AppError{Status: http.StatusBadRequest, Code: "invalid_body", Message: "could not read request body", Err: err})
+			return
+		}
+		body = data
+		r.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	hash := sha256.Sum256(body)
+	scoped := apiKeyLabelFromContext(r.Context()) + "\x00" + key
+
+	for {
+		e, owner := c.start(scoped, hash)
+		if owner {
+			c.run(w, r, scoped, e, next)
+			return
+		}
+		if e.bodyHash != hash {
+			errorJSON(w, http.StatusConflict, "idempotency_conflict", idempotencyHeader+" was already used with a different request body", nil)
+			return
+		}
+
+		// request pertama masih berjalan: tunggu hasilnya
+		select {
+		case <-e.done:
+		case <-r.Context().Done():
+			writeAppError(w, r, r.Context().Err())
+			return
+		}
+		if e.status == 0 {
+			continue // request pertama gagal tanpa disimpan, coba jadi owner
+		}
+		replay(w, e)
+		return
+	}
+}
+
+// run memanggil next dengan recorder lalu menyimpan hasilnya (kecuali 5xx).
+// Recorder mulai dengan header w (X-Request-Id dipakai errorJSON), tapi yang
+// disimpan hanya header yang ditambah/diubah handler.
+func (c *idempotencyCache) run(w http.ResponseWriter, r *http.Request, key string, e *idempotencyEntry, next http.HandlerFunc) {
+	before := w.Header().Clone()
+	rec := &idempotencyRecorder{header: before.Clone(), pretty: jsonPretty(w)}
+	stored := false
+	defer func() {
+		if !stored {
+			c.abandon(key, e)
+		}
+	}()
+
+	next(rec, r)
+
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	maps.DeleteFunc(rec.header, func(k string, v []string) bool {
+		return slices.Equal(before[k], v)
+	})
+	maps.Copy(w.Header(), rec.header)
+	w.WriteHeader(rec.status)
+	_, _ = w.Write(rec.body.Bytes())
+
+	if rec.status < http.StatusInternalServerError {
+		c.finish(e, rec)
+		stored = true
+	}
+}
+
+func replay(w http.ResponseWriter, e *idempotencyEntry) {
+	maps.Copy(w.Header(), e.header)
+	w.Header().Set(idempotencyReplayedHeader, "true")
+	w.WriteHeader(e.status)
+	_, _ = w.Write(e.body)
+}
+
+// idempotencyRecorder menampung seluruh response handler (response POST kecil)
+type idempotencyRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	pretty bool
+}
+
+func (rec *idempotencyRecorder) Header() http.Header { return rec.header }
+
+func (rec *idempotencyRecorder) prettyJSON() bool { return rec.pretty }
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *idempotencyRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.body.Write(b)
+}
@@ -0,0 +1,127 @@
+// File: /idempotency_test.go
+package main
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKeyReplay(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(func(c *Config) { c.IdempotencyTTL = time.Hour }), WithServerClock(clock.Now))
+	key := []string{idempotencyHeader, "retry-1"}
+
+	first, firstBody := doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`, key...)
+	if first.StatusCode != http.StatusCreated || first.Header.Get(idempotencyReplayedHeader) != "" {
+		t.Fatalf("first: status %d, headers %v: %s", first.StatusCode, first.Header, firstBody)
+	}
+
+	// key dan body sama: response pertama diputar ulang, tidak ada user baru
+	again, againBody := doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`, key...)
+	if again.StatusCode != http.StatusCreated || againBody != firstBody || again.Header.Get(idempotencyReplayedHeader) != "true" {
+		t.Fatalf("replay: status %d, headers %v: %s", again.StatusCode, again.Header, againBody)
+	}
+	if got, want := again.Header.Get("Location"), first.Header.Get("Location"); got != want {
+		t.Fatalf("replay Location %q, want %q", got, want)
+	}
+
+	// key sama, body beda = 409
+	res, body := doRequest(t, ts, "POST", "/users", `{"name":"Grace"}`, key...)
+	if res.StatusCode != http.StatusConflict || decodeBody[errorResponse](t, body).Error != "idempotency_conflict" {
+		t.Fatalf("different body: status %d: %s", res.StatusCode, body)
+	}
+
+	// key lain = request baru
+	res, body = doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`, idempotencyHeader, "retry-2")
+	if res.StatusCode != http.StatusCreated || decodeBody[userEnvelope](t, body).Data.ID != "2" {
+		t.Fatalf("other key: status %d: %s", res.StatusCode, body)
+	}
+
+	// masih dalam TTL: tetap diputar ulang
+	clock.Advance(time.Hour - time.Second)
+	if res, body = doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`, key...); body != firstBody {
+		t.Fatalf("before expiry: status %d: %s", res.StatusCode, body)
+	}
+
+	// lewat TTL: key boleh dipakai lagi, termasuk dengan body lain
+	clock.Advance(2 * time.Second)
+	res, body = doRequest(t, ts, "POST", "/users", `{"name":"Grace"}`, key...)
+	if res.StatusCode != http.StatusCreated || res.Header.Get(idempotencyReplayedHeader) != "" || decodeBody[userEnvelope](t, body).Data.ID != "3" {
+		t.Fatalf("after expiry: status %d: %s", res.StatusCode, body)
+	}
+
+	if _, body = doRequest(t, ts, "GET", "/users", ""); decodeBody[userListEnvelope](t, body).Meta.Total != 3 {
+		t.Fatalf("users: %s", body)
+	}
+}
+
+// response 4xx ikut disimpan; key terlalu panjang ditolak
+func TestIdempotencyKeyErrors(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+
+	res, body := doRequest(t, ts, "POST", "/users", `{"name":""}`, idempotencyHeader, "bad-1")
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid: status %d: %s", res.StatusCode, body)
+	}
+	if res, _ = doRequest(t, ts, "POST", "/users", `{"name":""}`, idempotencyHeader, "bad-1"); res.StatusCode != http.StatusBadRequest || res.Header.Get(idempotencyReplayedHeader) != "true" {
+		t.Fatalf("invalid replay: status %d, headers %v", res.StatusCode, res.Header)
+	}
+
+	res, body = doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`, idempotencyHeader, strings.Repeat("k", maxIdempotencyKeyLength+1))
+	if res.StatusCode != http.StatusBadRequest || decodeBody[errorResponse](t, body).Error != "validation_failed" {
+		t.Fatalf("long key: status %d: %s", res.StatusCode, body)
+	}
+}
+
+// -idempotency-ttl=0: header diabaikan, tiap request membuat user
+func TestIdempotencyKeyDisabled(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.IdempotencyTTL = 0 }))
+	for i := range 2 {
+		res, body := doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`, idempotencyHeader, "retry-1")
+		if res.StatusCode != http.StatusCreated || res.Header.Get(idempotencyReplayedHeader) != "" {
+			t.Fatalf("request %d: status %d, headers %v: %s", i, res.StatusCode, res.Header, body)
+		}
+	}
+	if _, body := doRequest(t, ts, "GET", "/users", ""); decodeBody[userListEnvelope](t, body).Meta.Total != 2 {
+		t.Fatalf("users: %s", body)
+	}
+}
+
+// request pertama yang bersamaan dengan key sama hanya membuat satu user
+func TestIdempotencyKeyConcurrent(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+
+	const n = 20
+	bodies := make([]string, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("POST", ts.URL+"/users", strings.NewReader(`{"name":"Ada"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(idempotencyHeader, "same")
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return
+			}
+			defer res.Body.Close()
+			data, _ := io.ReadAll(res.Body)
+			bodies[i] = string(data)
+		}()
+	}
+	wg.Wait()
+
+	for i, body := range bodies {
+		if body != bodies[0] {
+			t.Fatalf("response %d differs: %s vs %s", i, body, bodies[0])
+		}
+	}
+	if _, body := doRequest(t, ts, "GET", "/users", ""); decodeBody[userListEnvelope](t, body).Meta.Total != 1 {
+		t.Fatalf("users: %s", body)
+	}
+}
@@ -0,0 +1,91 @@
+// File: /inflight.go
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// inflightRetryAfter = saran Retry-After untuk 503 server_busy
+const inflightRetryAfter = time.Second
+
+// inflightLimiter = semaphore buffered channel untuk -max-inflight
+type inflightLimiter struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+	rejected     atomic.Uint64
+}
+
+// newInflightLimiter mengembalikan nil kalau max <= 0 (tanpa batas)
+func newInflightLimiter(max int, queueTimeout time.Duration) *inflightLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &inflightLimiter{
+		slots:        make(chan struct{}, max),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// InFlight = jumlah slot yang sedang dipakai
+func (l *inflightLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+func (l *inflightLimiter) Max() int {
+	return cap(l.slots)
+}
+
+func (l *inflightLimiter) Rejected() uint64 {
+	return l.rejected.Load()
+}
+
+// acquire menunggu slot paling lama queueTimeout (atau sampai client pergi)
+func (l *inflightLimiter) acquire(r *http.Request) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if l.queueTimeout <= 0 {
+		return false
+	}
+
+	t := time.NewTimer(l.queueTimeout)
+	defer t.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-t.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// limitInflight: slot dilepas lewat defer, jadi tetap kembali walau handler panic
+func limitInflight(l *inflightLimiter, next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.acquire(r) {
+			if err := r.Context().Err(); err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			l.rejected.Add(1)
+			writeAppError(w, r, &AppError{
+				Status:     http.StatusServiceUnavailable,
+				Code:       "server_busy",
+				Message:    "too many requests in flight, try again later",
+				RetryAfter: inflightRetryAfter,
+			})
+			return
+		}
+		defer func() { <-l.slots }()
+
+		next.ServeHTTP(w, r)
+	})
+}
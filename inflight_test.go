@@ -0,0 +1,106 @@
+// File: /inflight_test.go
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingRoutes: /test/block menahan slot sampai release ditutup,
+// entered menerima sinyal begitu handler mulai jalan
+func blockingRoutes() (routes map[string]http.HandlerFunc, entered chan struct{}, release chan struct{}) {
+	entered = make(chan struct{}, 8)
+	release = make(chan struct{})
+	routes = map[string]http.HandlerFunc{
+		"/test/block": func(w http.ResponseWriter, r *http.Request) {
+			entered <- struct{}{}
+			<-release
+		},
+		"/test/panic": func(http.ResponseWriter, *http.Request) { panic("boom") },
+	}
+	return routes, entered, release
+}
+
+func TestInflightSlotReleasedOnPanic(t *testing.T) {
+	routes, _, _ := blockingRoutes()
+	_, ts := newTestServerWithRoutes(t, testConfig(func(c *Config) {
+		c.MaxInflight = 1
+		c.InflightQueueTimeout = 0
+	}), routes)
+
+	// tanpa antrean: slot yang bocor = request berikutnya langsung 503
+	for i := range 3 {
+		if res, body := doRequest(t, ts, "GET", "/test/panic", ""); res.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("panic %d: status %d: %s", i, res.StatusCode, body)
+		}
+		if res, body := doRequest(t, ts, "GET", "/health", ""); res.StatusCode != http.StatusOK {
+			t.Fatalf("after panic %d: status %d: %s", i, res.StatusCode, body)
+		}
+	}
+	_, metrics := doRequest(t, ts, "GET", "/metrics", "")
+	if !strings.Contains(metrics, "http_requests_in_flight 1\n") || !strings.Contains(metrics, "http_requests_rejected_total 0\n") {
+		t.Fatalf("metrics after panics:\n%s", metrics)
+	}
+}
+
+func TestInflightRejectsWhenFull(t *testing.T) {
+	routes, entered, release := blockingRoutes()
+	_, ts := newTestServerWithRoutes(t, testConfig(func(c *Config) {
+		c.MaxInflight = 1
+		c.InflightQueueTimeout = 20 * time.Millisecond
+	}), routes)
+
+	done := make(chan int)
+	go func() {
+		res, err := http.Get(ts.URL + "/test/block")
+		if err != nil {
+			done <- 0
+			return
+		}
+		res.Body.Close()
+		done <- res.StatusCode
+	}()
+	<-entered
+
+	start := time.Now()
+	res, body := doRequest(t, ts, "GET", "/health", "")
+	got := decodeBody[errorResponse](t, body)
+	if res.StatusCode != http.StatusServiceUnavailable || got.Error != "server_busy" || res.Header.Get("Retry-After") != "1" {
+		t.Fatalf("status %d, Retry-After %q: %s", res.StatusCode, res.Header.Get("Retry-After"), body)
+	}
+	if waited := time.Since(start); waited < 20*time.Millisecond {
+		t.Errorf("rejected after %v, want to queue for 20ms first", waited)
+	}
+
+	close(release)
+	if status := <-done; status != http.StatusOK {
+		t.Fatalf("blocked request: status %d", status)
+	}
+	_, metrics := doRequest(t, ts, "GET", "/metrics", "")
+	if !strings.Contains(metrics, "http_requests_rejected_total 1\n") {
+		t.Fatalf("metrics:\n%s", metrics)
+	}
+}
+
+// request yang antre mendapat slot begitu slot dilepas sebelum queue timeout
+func TestInflightQueueWaitsForSlot(t *testing.T) {
+	routes, entered, release := blockingRoutes()
+	_, ts := newTestServerWithRoutes(t, testConfig(func(c *Config) {
+		c.MaxInflight = 1
+		c.InflightQueueTimeout = 2 * time.Second
+	}), routes)
+
+	go func() {
+		if res, err := http.Get(ts.URL + "/test/block"); err == nil {
+			res.Body.Close()
+		}
+	}()
+	<-entered
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+	if res, body := doRequest(t, ts, "GET", "/health", ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("queued request: status %d: %s", res.StatusCode, body)
+	}
+}
@@ -0,0 +1,116 @@
+// File: /ip_filter.go
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ipFilter = allowlist/denylist CIDR untuk satu grup route (mis. /admin/*).
+// Deny dicek dulu; allow kosong = semua IP yang tidak di-deny boleh.
+type ipFilter struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// newIPFilter mengembalikan nil kalau kedua list kosong (tanpa filter)
+func newIPFilter(allow, deny []string) (*ipFilter, error) {
+	a, err := parsePrefixes(allow)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parsePrefixes(deny)
+	if err != nil {
+		return nil, err
+	}
+	if len(a) == 0 && len(d) == 0 {
+		return nil, nil
+	}
+	return &ipFilter{allow: a, deny: d}, nil
+}
+
+// parsePrefixes menerima CIDR ("10.0.0.0/8", "2001:db8::/32") atau satu IP
+func parsePrefixes(list []string) ([]netip.Prefix, error) {
+	out := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IP or CIDR %q", s)
+			}
+			addr = addr.Unmap().WithZone("")
+			out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP or CIDR %q", s)
+		}
+		if p.Addr().Is4In6() {
+			if p.Bits() < 96 {
+				return nil, fmt.Errorf("invalid IP or CIDR %q: IPv4-mapped prefix shorter than /96", s)
+			}
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		out = append(out, p.Masked())
+	}
+	return out, nil
+}
+
+// allowed: addr tidak valid hanya boleh kalau tidak ada allowlist.
+// Zone dibuang dulu: Prefix.Contains selalu false untuk alamat ber-zone,
+// jadi fe80::1%eth0 akan lolos dari deny fe80::/10.
+func (f *ipFilter) allowed(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return len(f.allow) == 0
+	}
+	addr = addr.Unmap().WithZone("")
+
+	for _, p := range f.deny {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, p := range f.allow {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerAddr = IP koneksi langsung. Server tidak punya opsi trusted proxy
+// (access log juga memakai RemoteAddr), jadi X-Forwarded-For
+// tidak dibaca: header itu bisa dipalsukan client mana pun.
+func peerAddr(r *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, _ := netip.ParseAddr(host)
+	return addr
+}
+
+// wrap memasang filter di depan next; f nil = next apa adanya.
+// Koneksi Unix socket tidak punya IP dan selalu boleh (akses diatur permission file).
+func (f *ipFilter) wrap(next http.Handler) http.Handler {
+	if f == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if clientAddr(r) != "unix" && !f.allowed(peerAddr(r)) {
+			requestLog(r.Context()).Warn("blocked by ip filter", "remote_addr", r.RemoteAddr)
+			errorJSON(w, http.StatusForbidden, "forbidden", "access from this address is not allowed", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
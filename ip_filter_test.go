@@ -0,0 +1,116 @@
+// File: /ip_filter_test.go
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIPFilterBoundaries(t *testing.T) {
+	f, err := newIPFilter(
+		[]string{"10.0.0.0/24", "2001:db8::/64", "192.168.1.7", "::ffff:172.16.0.0/112"},
+		[]string{"10.0.0.128/25", "fe80::/10"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.0.0.0", true},
+		{"10.0.0.127", true},
+		// deny menang atas allow yang lebih luas
+		{"10.0.0.128", false},
+		{"10.0.0.255", false},
+		{"10.0.1.0", false},
+		{"9.255.255.255", false},
+		{"192.168.1.7", true},
+		{"192.168.1.8", false},
+		{"2001:db8::", true},
+		{"2001:db8::ffff:ffff:ffff:ffff", true},
+		{"2001:db8:0:1::", false},
+		// prefix IPv4-mapped di config = prefix IPv4 biasa, dan sebaliknya
+		{"172.16.255.255", true},
+		{"172.17.0.0", false},
+		{"::ffff:10.0.0.1", true},
+		{"::ffff:10.0.0.200", false},
+		// alamat ber-zone tetap kena deny
+		{"fe80::1", false},
+		{"fe80::1%eth0", false},
+	}
+	for _, tt := range tests {
+		if got := f.allowed(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("allowed(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+	if f.allowed(netip.Addr{}) {
+		t.Error("invalid address allowed despite an allowlist")
+	}
+}
+
+// Prefix.Contains selalu false untuk alamat ber-zone, jadi zone harus dibuang
+func TestIPFilterZonedPeer(t *testing.T) {
+	deny, err := newIPFilter(nil, []string{"fe80::/10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deny.allowed(netip.MustParseAddr("fe80::1%eth0")) {
+		t.Fatal("zoned peer slipped past the denylist")
+	}
+
+	allow, err := newIPFilter([]string{"fe80::1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !allow.allowed(netip.MustParseAddr("fe80::1%eth0")) || allow.allowed(netip.MustParseAddr("fe80::2%eth0")) {
+		t.Fatal("zoned peer not matched against a single-IP allow entry")
+	}
+}
+
+func TestNewIPFilterErrors(t *testing.T) {
+	if f, err := newIPFilter(nil, nil); f != nil || err != nil {
+		t.Fatalf("empty lists = %v, %v; want nil filter", f, err)
+	}
+	for _, bad := range []string{"10.0.0.0/33", "not-an-ip", "::ffff:10.0.0.0/95"} {
+		if _, err := newIPFilter([]string{bad}, nil); err == nil {
+			t.Errorf("%s: no error", bad)
+		}
+	}
+}
+
+// wrap memakai IP koneksi langsung, X-Forwarded-For diabaikan
+func TestIPFilterWrap(t *testing.T) {
+	f, err := newIPFilter(nil, []string{"10.0.0.0/8", "fe80::/10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := f.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		remoteAddr, forwarded string
+		want                  int
+	}{
+		{"127.0.0.1:5000", "", http.StatusOK},
+		{"10.1.2.3:5000", "127.0.0.1", http.StatusForbidden},
+		{"127.0.0.1:5000", "10.1.2.3", http.StatusOK},
+		{"[fe80::1%eth0]:5000", "", http.StatusForbidden},
+		// Unix socket tidak punya IP
+		{"@", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/admin/fixtures", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.remoteAddr, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// File: /json_style.go
+package main
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// prettyJSONer = writer yang tahu apakah response JSON harus di-indent.
+// Writer pembungkus yang tidak punya Unwrap (timeoutWriter, idempotencyRecorder)
+// meneruskan jawabannya sendiri.
+type prettyJSONer interface {
+	prettyJSON() bool
+}
+
+// jsonStyle memilih format JSON per request: default dari -pretty,
+// ?pretty=true / ?pretty=false menimpanya. Nilai lain diabaikan.
+func jsonStyle(pretty bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := pretty
+		if v := r.URL.Query().Get("pretty"); v != "" {
+			if b, err := strconv.ParseBool(v); err == nil {
+				p = b
+			}
+		}
+		next.ServeHTTP(&jsonStyleWriter{ResponseWriter: w, pretty: p}, r)
+	})
+}
+
+type jsonStyleWriter struct {
+	http.ResponseWriter
+	pretty bool
+}
+
+func (jw *jsonStyleWriter) prettyJSON() bool { return jw.pretty }
+
+func (jw *jsonStyleWriter) Flush() {
+	_ = http.NewResponseController(jw.ResponseWriter).Flush()
+}
+
+func (jw *jsonStyleWriter) Unwrap() http.ResponseWriter {
+	return jw.ResponseWriter
+}
+
+// jsonPretty mencari pilihan jsonStyle di rantai writer w.
+// Tanpa jsonStyle (mis. httptest recorder batch) = compact.
+func jsonPretty(w http.ResponseWriter) bool {
+	for w != nil {
+		if p, ok := w.(prettyJSONer); ok {
+			return p.prettyJSON()
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return false
+		}
+		w = u.Unwrap()
+	}
+	return false
+}
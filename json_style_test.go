@@ -0,0 +1,70 @@
+// File: /json_style_test.go
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	compactTime = `{"data":{"time":"2024-01-02T03:04:05Z"},"meta":{}}` + "\n"
+	prettyTime  = "{\n  \"data\": {\n    \"time\": \"2024-01-02T03:04:05Z\"\n  },\n  \"meta\": {}\n}\n"
+)
+
+// -pretty = default, ?pretty= menimpa ke dua arah, nilai tak dikenal diabaikan
+func TestJSONStyle(t *testing.T) {
+	tests := []struct {
+		pretty bool
+		query  string
+		want   string
+	}{
+		{false, "", compactTime},
+		{true, "", prettyTime},
+		{false, "?pretty=true", prettyTime},
+		{false, "?pretty=1", prettyTime},
+		{true, "?pretty=false", compactTime},
+		{true, "?pretty=0", compactTime},
+		{false, "?pretty=maybe", compactTime},
+		{true, "?pretty=maybe", prettyTime},
+	}
+	for _, tt := range tests {
+		ts := newTestServer(t, testConfig(func(c *Config) { c.Pretty = tt.pretty }), WithServerClock(newFakeClock().Now))
+		res, body := doRequest(t, ts, "GET", "/time"+tt.query, "")
+		if body != tt.want {
+			t.Errorf("-pretty=%t GET /time%s = %q, want %q", tt.pretty, tt.query, body, tt.want)
+		}
+		if got := res.Header.Get("Content-Length"); got != strconv.Itoa(len(tt.want)) {
+			t.Errorf("-pretty=%t GET /time%s: Content-Length %s", tt.pretty, tt.query, got)
+		}
+	}
+}
+
+// error dari middleware, response lewat timeout dan idempotency ikut format yang sama
+func TestJSONStyleAcrossWriters(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.RequestTimeout = time.Minute }))
+	requests := []struct {
+		method, path, body string
+		key                string
+	}{
+		{"GET", "/users/abc", "", ""},
+		{"GET", "/nope", "", ""},
+		{"POST", "/users", `{"name":"Ada"}`, "k1"},
+		{"POST", "/users", `{"name":"Ada"}`, "k1"}, // replay
+	}
+	for _, pretty := range []bool{false, true} {
+		for _, req := range requests {
+			path := req.path + "?pretty=" + strconv.FormatBool(pretty)
+			var header []string
+			if req.key != "" {
+				// replay memutar ulang byte yang disimpan, jadi key per format
+				header = []string{idempotencyHeader, req.key + path}
+			}
+			_, body := doRequest(t, ts, req.method, path, req.body, header...)
+			if got := strings.HasPrefix(body, "{\n  \""); got != pretty {
+				t.Errorf("%s %s: pretty %t: %q", req.method, path, got, body)
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// File: /jsonschema.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// validator JSON Schema mini, cukup untuk subset yang dipakai di openapi.json:
+// $ref, type, required, properties, additionalProperties (bool), items,
+// oneOf, enum, nullable, minimum, format (date-time, uuid)
+type schemaValidator struct {
+	// root dokumen untuk resolve "$ref": "#/..."
+	root map[string]any
+}
+
+// validateValue mengembalikan daftar pelanggaran, kosong = valid.
+// value harus hasil json.Unmarshal ke any.
+func (v schemaValidator) validateValue(schema map[string]any, value any) []string {
+	var errs []string
+	v.validate(schema, value, "$", &errs)
+	return errs
+}
+
+// schemaBodyValidator = BodyValidator dari schema components/schemas openapi.json
+type schemaBodyValidator struct {
+	v      schemaValidator
+	schema map[string]any
+}
+
+// newSchemaBodyValidator mengambil #/components/schemas/<name> dari spec
+func newSchemaBodyValidator(spec []byte, name string) (*schemaBodyValidator, error) {
+	var root map[string]any
+	if err := json.Unmarshal(spec, &root); err != nil {
+		return nil, fmt.Errorf("openapi.json: %w", err)
+	}
+	v := schemaValidator{root: root}
+	schema, err := v.resolve("#/components/schemas/" + name)
+	if err != nil {
+		return nil, err
+	}
+	return &schemaBodyValidator{v: v, schema: schema}, nil
+}
+
+// ValidateBody: JSON rusak dibiarkan lolos supaya decoder yang melaporkan invalid_json
+func (s *schemaBodyValidator) ValidateBody(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	if errs := s.v.validateValue(s.schema, value); len(errs) > 0 {
+		return validationError("request body does not match schema", errs)
+	}
+	return nil
+}
+
+func (v schemaValidator) resolve(ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+
+	var node any = v.root
+	for _, part := range strings.Split(ref[2:], "/") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		node, ok = m[part]
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+
+	m, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("$ref %q is not an object", ref)
+	}
+	return m, nil
+}
+
+func (v schemaValidator) validate(schema map[string]any, value any, path string, errs *[]string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := v.resolve(ref)
+		if err != nil {
+			*errs = append(*errs, path+": "+err.Error())
+			return
+		}
+		v.validate(resolved, value, path, errs)
+		return
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return
+		}
+		if _, typed := schema["type"]; typed {
+			*errs = append(*errs, path+": must not be null")
+		}
+		return
+	}
+
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		matches := 0
+		for _, alt := range oneOf {
+			altSchema, _ := alt.(map[string]any)
+			var altErrs []string
+			v.validate(altSchema, value, path, &altErrs)
+			if len(altErrs) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			*errs = append(*errs, path+": must match exactly one schema in oneOf")
+		}
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if e == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*errs = append(*errs, fmt.Sprintf("%s: must be one of %v", path, enum))
+		}
+	}
+
+	typ, _ := schema["type"].(string)
+	switch typ {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			*errs = append(*errs, path+": must be an object")
+			return
+		}
+		v.validateObject(schema, obj, path, errs)
+
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			*errs = append(*errs, path+": must be an array")
+			return
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range arr {
+			if items != nil {
+				v.validate(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			*errs = append(*errs, path+": must be a string")
+			return
+		}
+		switch schema["format"] {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				*errs = append(*errs, path+": must be an RFC3339 date-time")
+			}
+		case "uuid":
+			if !isUUID(s) {
+				*errs = append(*errs, path+": must be a UUID")
+			}
+		}
+
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			*errs = append(*errs, path+": must be a "+typ)
+			return
+		}
+		if typ == "integer" && n != math.Trunc(n) {
+			*errs = append(*errs, path+": must be an integer")
+			return
+		}
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			*errs = append(*errs, fmt.Sprintf("%s: must be >= %v", path, min))
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*errs = append(*errs, path+": must be a boolean")
+		}
+	}
+}
+
+func (v schemaValidator) validateObject(schema map[string]any, obj map[string]any, path string, errs *[]string) {
+	props, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := obj[name]; !ok {
+				*errs = append(*errs, path+"."+name+": is required")
+			}
+		}
+	}
+
+	// urutkan key supaya pesan error stabil
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		propSchema, ok := props[k].(map[string]any)
+		if !ok {
+			if additional, isBool := schema["additionalProperties"].(bool); isBool && !additional {
+				*errs = append(*errs, path+"."+k+": unknown field")
+			}
+			continue
+		}
+		v.validate(propSchema, obj[k], path+"."+k, errs)
+	}
+}
@@ -0,0 +1,69 @@
+// File: /jsonschema_test.go
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaBodyValidatorCreateUser(t *testing.T) {
+	v, err := newSchemaBodyValidator(openAPISpec, "CreateUserRequest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, body  string
+		wantDetails []string
+	}{
+		{"valid", `{"name":"Ada"}`, nil},
+		{"valid with role", `{"name":"Ada","role":"admin","password":"long enough"}`, nil},
+		// JSON rusak dilaporkan decoder (invalid_json), bukan schema
+		{"malformed", `{"name":`, nil},
+		{"missing name", `{}`, []string{"$.name: is required"}},
+		{"name wrong type", `{"name":42}`, []string{"$.name: must be a string"}},
+		{"name null", `{"name":null}`, []string{"$.name: must not be null"}},
+		{"extra fields", `{"name":"Ada","email":"a@x","age":3}`, []string{"$.age: unknown field", "$.email: unknown field"}},
+		{"bad role", `{"name":"Ada","role":"root"}`, []string{"$.role: must be one of [admin user]"}},
+		{"password wrong type", `{"name":"Ada","password":12345678}`, []string{"$.password: must be a string"}},
+		{"several at once", `{"role":1,"x":true}`, []string{"$.name: is required", "$.role: must be one of [admin user]", "$.role: must be a string", "$.x: unknown field"}},
+		{"not an object", `["Ada"]`, []string{"$: must be an object"}},
+	}
+	for _, tt := range tests {
+		err := v.ValidateBody([]byte(tt.body))
+		if tt.wantDetails == nil {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		appErr := asAppError(t, err)
+		if appErr.Status != http.StatusBadRequest || appErr.Code != "validation_failed" || !reflect.DeepEqual(appErr.Details, tt.wantDetails) {
+			t.Errorf("%s: %d %s %#v, want details %#v", tt.name, appErr.Status, appErr.Code, appErr.Details, tt.wantDetails)
+		}
+	}
+}
+
+// -schema-validation: pelanggaran schema = 400 dengan path JSON di details,
+// tanpa flag validasi di kode yang menjawab
+func TestSchemaValidationFlag(t *testing.T) {
+	body := `{"name":7,"nickname":"x"}`
+
+	ts := newTestServer(t, testConfig(func(c *Config) { c.SchemaValidation = true }))
+	res, resBody := doRequest(t, ts, "POST", "/users", body)
+	got := decodeBody[errorResponse](t, resBody)
+	want := []any{"$.name: must be a string", "$.nickname: unknown field"}
+	if res.StatusCode != http.StatusBadRequest || got.Message != "request body does not match schema" || !reflect.DeepEqual(got.Details, want) {
+		t.Fatalf("with schema validation: status %d: %s", res.StatusCode, resBody)
+	}
+	if res, resBody := doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`); res.StatusCode != http.StatusCreated {
+		t.Fatalf("valid body: status %d: %s", res.StatusCode, resBody)
+	}
+
+	ts = newTestServer(t, testConfig(nil))
+	res, resBody = doRequest(t, ts, "POST", "/users", body)
+	if res.StatusCode != http.StatusBadRequest || decodeBody[errorResponse](t, resBody).Message == "request body does not match schema" {
+		t.Fatalf("without schema validation: status %d: %s", res.StatusCode, resBody)
+	}
+}
@@ -0,0 +1,92 @@
+// File: /links.go
+package main
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// link = satu entry _links (gaya HAL)
+type link struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+// linkBuilder membuat path link dengan prefix BasePath dari config
+// (mis. "/api" kalau service dipasang di belakang reverse proxy).
+type linkBuilder struct {
+	base string
+}
+
+func newLinkBuilder(basePath string) linkBuilder {
+	return linkBuilder{base: strings.TrimRight(basePath, "/")}
+}
+
+// path menggabungkan segmen; segmen di-escape kecuali placeholder {x}
+func (b linkBuilder) path(segments ...string) string {
+	var sb strings.Builder
+	sb.WriteString(b.base)
+	for _, s := range segments {
+		sb.WriteByte('/')
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			sb.WriteString(s)
+		} else {
+			sb.WriteString(url.PathEscape(s))
+		}
+	}
+	return sb.String()
+}
+
+// userResource = User + _links untuk response satu user
+type userResource struct {
+	User
+	Links map[string]link `json:"_links"`
+}
+
+func (b linkBuilder) user(u User) userResource {
+	id := u.ID.String()
+	return userResource{
+		User: u,
+		Links: map[string]link{
+			"self":    {Href: b.path("users", id)},
+			"profile": {Href: b.path("users", id, "profile")},
+			"orders":  {Href: b.path("users", id, "orders", "{orderId}"), Templated: true},
+		},
+	}
+}
+
+// page mengembalikan link self/next/prev untuk list yang dipaginasi.
+// Query lain (q, sort, filter) tetap dibawa, hanya offset yang berubah.
+// limit 0 = semua item dalam satu halaman, jadi tanpa next/prev.
+func (b linkBuilder) page(segments []string, query url.Values, opts ListOptions, total int) map[string]link {
+	at := func(offset int) link {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		if offset > 0 {
+			q.Set("offset", strconv.Itoa(offset))
+		} else {
+			q.Del("offset")
+		}
+
+		href := b.path(segments...)
+		if enc := q.Encode(); enc != "" {
+			href += "?" + enc
+		}
+		return link{Href: href}
+	}
+
+	links := map[string]link{"self": at(opts.Offset)}
+	if opts.Limit <= 0 {
+		return links
+	}
+	if opts.Offset+opts.Limit < total {
+		links["next"] = at(opts.Offset + opts.Limit)
+	}
+	if opts.Offset > 0 {
+		links["prev"] = at(max(opts.Offset-opts.Limit, 0))
+	}
+	return links
+}
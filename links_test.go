@@ -0,0 +1,109 @@
+// File: /links_test.go
+package main
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestUserLinks(t *testing.T) {
+	for base, prefix := range map[string]string{"": "", "/api/": "/api"} {
+		got := newLinkBuilder(base).user(User{ID: "42"}).Links
+		want := map[string]link{
+			"self":    {Href: prefix + "/users/42"},
+			"profile": {Href: prefix + "/users/42/profile"},
+			"orders":  {Href: prefix + "/users/42/orders/{orderId}", Templated: true},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("base %q: links = %+v, want %+v", base, got, want)
+		}
+	}
+}
+
+func TestPageLinks(t *testing.T) {
+	b := newLinkBuilder("/api")
+	query := url.Values{"sort": {"-name"}, "limit": {"2"}, "offset": {"2"}}
+
+	tests := []struct {
+		name  string
+		opts  ListOptions
+		total int
+		want  map[string]link
+	}{
+		{
+			name: "middle page", opts: ListOptions{Limit: 2, Offset: 2}, total: 5,
+			want: map[string]link{
+				"self": {Href: "/api/users?limit=2&offset=2&sort=-name"},
+				"next": {Href: "/api/users?limit=2&offset=4&sort=-name"},
+				"prev": {Href: "/api/users?limit=2&sort=-name"},
+			},
+		},
+		{
+			name: "last page", opts: ListOptions{Limit: 2, Offset: 4}, total: 5,
+			want: map[string]link{
+				"self": {Href: "/api/users?limit=2&offset=4&sort=-name"},
+				"prev": {Href: "/api/users?limit=2&offset=2&sort=-name"},
+			},
+		},
+		{
+			name: "prev clamps at zero", opts: ListOptions{Limit: 2, Offset: 1}, total: 5,
+			want: map[string]link{
+				"self": {Href: "/api/users?limit=2&offset=1&sort=-name"},
+				"next": {Href: "/api/users?limit=2&offset=3&sort=-name"},
+				"prev": {Href: "/api/users?limit=2&sort=-name"},
+			},
+		},
+		{
+			name: "no limit", opts: ListOptions{}, total: 5,
+			want: map[string]link{"self": {Href: "/api/users?limit=2&sort=-name"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := b.page([]string{"users"}, query, tt.opts, tt.total); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: links = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+	if query.Get("offset") != "2" {
+		t.Fatal("page modified the request query")
+	}
+}
+
+func TestLinksInResponses(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.BasePath = "/api" }))
+	for range 3 {
+		doRequest(t, ts, "POST", "/users", `{"name":"Alice Doe"}`)
+	}
+
+	type links map[string]link
+	_, body := doRequest(t, ts, "GET", "/users/2", "")
+	single := decodeBody[struct {
+		Data struct {
+			Links links `json:"_links"`
+		} `json:"data"`
+	}](t, body)
+	if single.Data.Links["self"].Href != "/api/users/2" || !single.Data.Links["orders"].Templated {
+		t.Fatalf("single user links = %+v", single.Data.Links)
+	}
+
+	_, body = doRequest(t, ts, "GET", "/users?limit=1&offset=1", "")
+	list := decodeBody[struct {
+		Data []struct {
+			Links links `json:"_links"`
+		} `json:"data"`
+		Meta struct {
+			Links links `json:"_links"`
+		} `json:"meta"`
+	}](t, body)
+	if len(list.Data) != 1 || list.Data[0].Links["self"].Href != "/api/users/2" {
+		t.Fatalf("list item links = %s", body)
+	}
+	want := links{
+		"self": {Href: "/api/users?limit=1&offset=1"},
+		"next": {Href: "/api/users?limit=1&offset=2"},
+		"prev": {Href: "/api/users?limit=1"},
+	}
+	if !reflect.DeepEqual(list.Meta.Links, want) {
+		t.Fatalf("list links = %+v, want %+v", list.Meta.Links, want)
+	}
+}
@@ -0,0 +1,193 @@
+// File: /list_options.go
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const maxListLimit = 100
+
+// ListOptions = opsi list yang sama untuk semua resource:
+// ?q=, ?sort=field / ?sort=-field, ?limit=, ?offset= dan filter per field.
+type ListOptions struct {
+	Q      string
+	Sort   string
+	Desc   bool
+	Limit  int // 0 = tanpa batas (semua item)
+	Offset int
+
+	// Filters: nama filter -> nilai (dibandingkan case-insensitive)
+	Filters map[string]string
+}
+
+// ParseListOptions membaca query string. Semua kesalahan dikumpulkan
+// menjadi satu AppError 400 supaya client bisa memperbaiki sekaligus.
+func ParseListOptions(r *http.Request, allowedSorts []string, allowedFilters []string) (ListOptions, error) {
+	q := r.URL.Query()
+	opts := ListOptions{
+		Q:       strings.TrimSpace(q.Get("q")),
+		Filters: map[string]string{},
+	}
+	var details []string
+
+	if raw := strings.TrimSpace(q.Get("sort")); raw != "" {
+		field := raw
+		if strings.HasPrefix(raw, "-") {
+			opts.Desc = true
+			field = raw[1:]
+		}
+		if !slices.Contains(allowedSorts, field) {
+			details = append(details, "sort must be one of "+strings.Join(allowedSorts, ", ")+" (prefix with - for descending)")
+		}
+		opts.Sort = field
+	}
+
+	if raw := strings.TrimSpace(q.Get("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxListLimit {
+			details = append(details, "limit must be an integer between 1 and "+strconv.Itoa(maxListLimit))
+		}
+		opts.Limit = n
+	}
+
+	if raw := strings.TrimSpace(q.Get("offset")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			details = append(details, "offset must be a non-negative integer")
+		}
+		opts.Offset = n
+	}
+
+	for _, name := range allowedFilters {
+		if v := strings.TrimSpace(q.Get(name)); v != "" {
+			opts.Filters[name] = v
+		}
+	}
+
+	if len(details) > 0 {
+		return ListOptions{}, &AppError{
+			Status:  http.StatusBadRequest,
+			Code:    "validation_failed",
+			Message: "invalid query parameter",
+			Details: details,
+		}
+	}
+	return opts, nil
+}
+
+// listFields menjelaskan cara sebuah resource dicari, difilter dan diurutkan
+type listFields[T any] struct {
+	// text = isi yang dicari oleh ?q=
+	text func(T) string
+	// less per nama field sort; "" = urutan default
+	sorts map[string]func(a, b T) bool
+	// filters per nama filter, nilai field yang dibandingkan
+	filters map[string]func(T) string
+}
+
+func (f listFields[T]) sortNames() []string {
+	names := make([]string, 0, len(f.sorts))
+	for name := range f.sorts {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (f listFields[T]) filterNames() []string {
+	names := make([]string, 0, len(f.filters))
+	for name := range f.filters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Page = satu halaman hasil list, dipakai semua resource supaya meta
+// (count/total/limit/offset) selalu sama bentuknya.
+type Page[T any] struct {
+	Items []T
+	// Total = jumlah item sebelum pagination
+	Total  int
+	Limit  int // 0 = tanpa batas
+	Offset int
+}
+
+// meta = bagian pagination dari field meta response list
+func (p Page[T]) meta() apiResponse {
+	return apiResponse{
+		"count":  len(p.Items),
+		"total":  p.Total,
+		"limit":  p.Limit,
+		"offset": p.Offset,
+	}
+}
+
+// paginate memotong items sesuai limit/offset. limit 0 = semua sisa item,
+// offset di luar panjang = halaman kosong, limit atau offset negatif = error
+// (ParseListOptions sudah menolaknya, jadi di sini berarti bug pemanggil).
+func paginate[T any](items []T, limit, offset int) (Page[T], error) {
+	if limit < 0 || offset < 0 {
+		return Page[T]{}, fmt.Errorf("paginate: negative limit %d or offset %d", limit, offset)
+	}
+	page := Page[T]{Total: len(items), Limit: limit, Offset: offset}
+
+	if offset >= len(items) {
+		page.Items = []T{}
+		return page, nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	page.Items = items
+	return page, nil
+}
+
+// ApplyListOptions untuk store in-memory: filter -> sort -> paginate.
+// Page.Total = jumlah item setelah filter, sebelum pagination.
+func ApplyListOptions[T any](items []T, opts ListOptions, f listFields[T]) (Page[T], error) {
+	q := strings.ToLower(opts.Q)
+
+	out := make([]T, 0, len(items))
+	for _, it := range items {
+		if q != "" && f.text != nil && !strings.Contains(strings.ToLower(f.text(it)), q) {
+			continue
+		}
+
+		match := true
+		for name, want := range opts.Filters {
+			get, ok := f.filters[name]
+			if ok && !strings.EqualFold(get(it), want) {
+				match = false
+				break
+			}
+		}
+		if match {
+			out = append(out, it)
+		}
+	}
+
+	if less, ok := f.sorts[opts.Sort]; ok {
+		slices.SortStableFunc(out, func(a, b T) int {
+			switch {
+			case less(a, b):
+				return -1
+			case less(b, a):
+				return 1
+			}
+			return 0
+		})
+		if opts.Desc {
+			slices.Reverse(out)
+		}
+	}
+
+	return paginate(out, opts.Limit, opts.Offset)
+}
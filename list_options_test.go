@@ -0,0 +1,216 @@
+// File: /list_options_test.go
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseListOptions(t *testing.T) {
+	sorts, filters := []string{"id", "name"}, []string{"name"}
+	parse := func(query string) (ListOptions, error) {
+		return ParseListOptions(httptest.NewRequest("GET", "/users?"+query, nil), sorts, filters)
+	}
+
+	opts, err := parse("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (ListOptions{Filters: map[string]string{}}); !reflect.DeepEqual(opts, want) {
+		t.Fatalf("defaults = %+v", opts)
+	}
+
+	opts, err = parse("q=%20ali%20&sort=-name&limit=100&offset=5&name=Bob&role=admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ListOptions{Q: "ali", Sort: "name", Desc: true, Limit: 100, Offset: 5, Filters: map[string]string{"name": "Bob"}}
+	if !reflect.DeepEqual(opts, want) {
+		t.Fatalf("opts = %+v, want %+v", opts, want)
+	}
+
+	// semua kesalahan digabung jadi satu 400
+	_, err = parse("sort=email&limit=101&offset=-1")
+	appErr := asAppError(t, err)
+	wantDetails := []string{
+		"sort must be one of id, name (prefix with - for descending)",
+		"limit must be an integer between 1 and 100",
+		"offset must be a non-negative integer",
+	}
+	if appErr.Status != http.StatusBadRequest || appErr.Code != "validation_failed" || !reflect.DeepEqual(appErr.Details, wantDetails) {
+		t.Fatalf("error = %+v", appErr)
+	}
+	for _, q := range []string{"limit=0", "limit=abc", "offset=x"} {
+		if _, err := parse(q); err == nil {
+			t.Errorf("%s: no error", q)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name          string
+		items         []int
+		limit, offset int
+		want          Page[int]
+	}{
+		{"window", items, 2, 1, Page[int]{Items: []int{2, 3}, Total: 5, Limit: 2, Offset: 1}},
+		{"last partial page", items, 2, 4, Page[int]{Items: []int{5}, Total: 5, Limit: 2, Offset: 4}},
+		{"limit past end", items, 50, 0, Page[int]{Items: items, Total: 5, Limit: 50}},
+		{"offset at end", items, 2, 5, Page[int]{Items: []int{}, Total: 5, Limit: 2, Offset: 5}},
+		{"offset past end", items, 2, 10, Page[int]{Items: []int{}, Total: 5, Limit: 2, Offset: 10}},
+		// limit 0 = semua sisa item
+		{"zero limit", items, 0, 0, Page[int]{Items: items, Total: 5}},
+		{"zero limit with offset", items, 0, 3, Page[int]{Items: []int{4, 5}, Total: 5, Offset: 3}},
+		{"empty", nil, 0, 0, Page[int]{Items: []int{}}},
+	}
+	for _, tt := range tests {
+		got, err := paginate(tt.items, tt.limit, tt.offset)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: paginate = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, lo := range [][2]int{{-1, 0}, {2, -1}} {
+		if _, err := paginate(items, lo[0], lo[1]); err == nil {
+			t.Errorf("paginate(limit %d, offset %d): no error", lo[0], lo[1])
+		}
+	}
+}
+
+// TestUsersListBehavior mengunci perilaku GET /users setelah pindah ke ListOptions
+func TestUsersListBehavior(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	for _, name := range []string{"Citra Lestari", "alice doe", "Budi Santoso"} {
+		doRequest(t, ts, "POST", "/users", `{"name":"`+name+`"}`)
+	}
+
+	type listEnvelope struct {
+		Data []struct {
+			ID   UserID `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+		Meta struct {
+			Count, Total, Limit, Offset int
+		} `json:"meta"`
+	}
+	ids := func(body string) string {
+		var out []string
+		for _, u := range decodeBody[listEnvelope](t, body).Data {
+			out = append(out, string(u.ID))
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		query, wantIDs    string
+		count, total, lim int
+	}{
+		// default: semua user, urut id, tanpa limit
+		{"", "1,2,3", 3, 3, 0},
+		{"?limit=2", "1,2", 2, 3, 2},
+		{"?limit=2&offset=2", "3", 1, 3, 2},
+		{"?offset=10", "", 0, 3, 0},
+		{"?sort=name", "2,3,1", 3, 3, 0},
+		{"?sort=-name", "1,3,2", 3, 3, 0},
+		{"?q=SAN", "3", 1, 1, 0},
+		{"?name=ALICE%20DOE", "2", 1, 1, 0},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", "/users"+tt.query, "")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("GET /users%s: status %d: %s", tt.query, res.StatusCode, body)
+		}
+		if got := ids(body); got != tt.wantIDs {
+			t.Errorf("GET /users%s ids = %q, want %q", tt.query, got, tt.wantIDs)
+		}
+		m := decodeBody[listEnvelope](t, body).Meta
+		if m.Count != tt.count || m.Total != tt.total || m.Limit != tt.lim {
+			t.Errorf("GET /users%s meta = %+v", tt.query, m)
+		}
+	}
+
+	res, body := doRequest(t, ts, "GET", "/users?limit=101", "")
+	got := decodeBody[errorResponse](t, body)
+	if res.StatusCode != http.StatusBadRequest || got.Message != "invalid query parameter" ||
+		!reflect.DeepEqual(got.Details, []any{"limit must be an integer between 1 and 100"}) {
+		t.Fatalf("limit over cap: status %d: %s", res.StatusCode, body)
+	}
+}
+
+func TestListUsersCreatedRange(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(nil), WithServerClock(clock.Now))
+	for _, name := range []string{"a", "b", "c"} {
+		doRequest(t, ts, "POST", "/users", `{"name":"`+name+`"}`)
+		clock.Advance(time.Hour)
+	}
+	at := func(h int) string { return testEpoch.Add(time.Duration(h) * time.Hour).Format(time.RFC3339) }
+
+	tests := []struct {
+		name      string
+		query     string
+		wantNames []string
+		wantTotal int
+	}{
+		{"inclusive range", "createdAfter=" + at(1) + "&createdBefore=" + at(2), []string{"b", "c"}, 2},
+		{"after only", "createdAfter=" + at(2), []string{"c"}, 1},
+		{"before only", "createdBefore=" + at(0), []string{"a"}, 1},
+		{"between creations", "createdAfter=" + testEpoch.Add(10*time.Minute).Format(time.RFC3339) + "&createdBefore=" + testEpoch.Add(50*time.Minute).Format(time.RFC3339), nil, 0},
+		{"after > before", "createdAfter=" + at(2) + "&createdBefore=" + at(0), nil, 0},
+		// filter sebelum pagination: total = hasil filter
+		{"with limit", "createdAfter=" + at(0) + "&sort=name&limit=1&offset=1", []string{"b"}, 3},
+		// zona waktu lain tetap dibandingkan sebagai instant
+		{"offset zone", "createdAfter=" + url.QueryEscape(testEpoch.Add(time.Hour).In(time.FixedZone("WIB", 7*3600)).Format(time.RFC3339)), []string{"b", "c"}, 2},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", "/users?"+tt.query, "")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", tt.name, res.StatusCode, body)
+		}
+		list := decodeBody[userListEnvelope](t, body)
+		var names []string
+		for _, u := range list.Data {
+			names = append(names, u.Name)
+		}
+		slices.Sort(names)
+		if !slices.Equal(names, tt.wantNames) || list.Meta.Total != tt.wantTotal {
+			t.Errorf("%s: names %v total %d, want %v total %d", tt.name, names, list.Meta.Total, tt.wantNames, tt.wantTotal)
+		}
+	}
+}
+
+func TestListUsersCreatedRangeMalformed(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	tests := []struct {
+		query       string
+		wantDetails int
+	}{
+		{"createdAfter=yesterday", 1},
+		{"createdBefore=2024-01-02", 1},
+		{"createdAfter=2024-13-01T00:00:00Z&createdBefore=1704164645", 2},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", "/users?"+tt.query, "")
+		got := decodeBody[errorResponse](t, body)
+		details, _ := got.Details.([]any)
+		if res.StatusCode != http.StatusBadRequest || got.Error != "validation_failed" || len(details) != tt.wantDetails {
+			t.Errorf("%s: status %d: %s", tt.query, res.StatusCode, body)
+			continue
+		}
+		if d, _ := details[0].(string); !strings.Contains(d, "RFC3339") {
+			t.Errorf("%s: details %v", tt.query, details)
+		}
+	}
+}
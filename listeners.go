@@ -0,0 +1,85 @@
+// File: /listeners.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout = waktu maksimal menunggu request yang sedang jalan saat shutdown
+const shutdownTimeout = 10 * time.Second
+
+// listenAll bind semua alamat sekaligus. Kalau ada yang gagal, listener
+// yang sudah terbuka ditutup lagi dan semua error dikembalikan bersama.
+func listenAll(addrs []string) ([]net.Listener, error) {
+	var (
+		listeners []net.Listener
+		errs      []error
+	)
+	for _, addr := range addrs {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listen %s: %w", addr, err))
+			continue
+		}
+		listeners = append(listeners, ln)
+	}
+
+	if len(errs) > 0 {
+		for _, ln := range listeners {
+			_ = ln.Close()
+		}
+		return nil, errors.Join(errs...)
+	}
+	return listeners, nil
+}
+
+// serveListeners melayani srv di semua listener (HTTPS kalau srv.TLSConfig
+// diisi) sampai SIGINT/SIGTERM atau sampai satu listener error. Setelah itu
+// shutdown dengan rapi (semua listener ikut ditutup) lalu cleanup dipanggil.
+func serveListeners(srv *http.Server, listeners []net.Listener, cleanup func()) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// dicek sekali di awal: Serve untuk HTTP/2 bisa mengisi srv.TLSConfig sendiri
+	useTLS := srv.TLSConfig != nil
+
+	errCh := make(chan error, len(listeners))
+	for _, ln := range listeners {
+		go func() {
+			var err error
+			if useTLS {
+				// cert sudah ada di TLSConfig, jadi nama file dikosongkan
+				err = srv.ServeTLS(ln, "", "")
+			} else {
+				err = srv.Serve(ln)
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errCh <- fmt.Errorf("serve %s: %w", ln.Addr(), err)
+			}
+		}()
+	}
+
+	var serveErr error
+	select {
+	case serveErr = <-errCh:
+	case <-ctx.Done():
+		logInfof("shutting down")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := errors.Join(serveErr, srv.Shutdown(shutdownCtx))
+	if cleanup != nil {
+		cleanup()
+	}
+	return err
+}
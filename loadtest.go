@@ -0,0 +1,363 @@
+// File: /loadtest.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-beginner-restapi/client"
+)
+
+// loadTestConfig = flag subcommand "loadtest"
+type loadTestConfig struct {
+	Target      string
+	RPS         int
+	Duration    time.Duration
+	Mix         map[string]int // nama operasi -> bobot
+	Concurrency int
+	MaxP99      time.Duration // 0 = tanpa SLO
+	MaxErrors   float64       // rasio error maksimal, < 0 = tanpa SLO
+}
+
+// loadTestOp = satu operasi loadtest lewat package client. status = status
+// sukses endpoint-nya (client hanya mengembalikan status untuk error).
+type loadTestOp struct {
+	status int
+	// run mengembalikan ID user yang dibuat (hanya write)
+	run func(ctx context.Context, lt *loadTester) (client.UserID, error)
+}
+
+// operasi yang dijalankan loadtest, body sama dengan contoh di openapi.json
+var loadTestOps = map[string]loadTestOp{
+	"read": {http.StatusOK, func(ctx context.Context, lt *loadTester) (client.UserID, error) {
+		if id, ok := lt.randomID(); ok {
+			_, err := lt.api.GetUser(ctx, id)
+			return "", err
+		}
+		_, err := lt.api.ListUsers(ctx, client.ListOptions{Limit: 10})
+		return "", err
+	}},
+	"list": {http.StatusOK, func(ctx context.Context, lt *loadTester) (client.UserID, error) {
+		_, err := lt.api.ListUsers(ctx, client.ListOptions{Limit: 10, Sort: "-createdAt"})
+		return "", err
+	}},
+	"write": {http.StatusCreated, func(ctx context.Context, lt *loadTester) (client.UserID, error) {
+		u, err := lt.api.CreateUser(ctx, "load "+strconv.Itoa(rand.Intn(1_000_000)))
+		return u.ID, err
+	}},
+}
+
+// runLoadTest dipanggil dari main untuk "./app loadtest ...".
+// Exit code: 0 = ok, 1 = SLO dilanggar, 2 = argumen salah.
+func runLoadTest(args []string, stdout, stderr io.Writer) int {
+	cfg, err := parseLoadTestFlags(args, stderr)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintln(stderr, "loadtest:", err)
+		return 2
+	}
+
+	report := newLoadTester(cfg).run()
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(report)
+
+	if len(report.Violations) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func parseLoadTestFlags(args []string, stderr io.Writer) (loadTestConfig, error) {
+	cfg := loadTestConfig{}
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.Target, "target", "http://localhost:8080", "base URL of the server under test")
+	fs.IntVar(&cfg.RPS, "rps", 100, "requests per second to send")
+	fs.DurationVar(&cfg.Duration, "duration", 10*time.Second, "how long to send requests")
+	mix := fs.String("mix", "read:80,write:20", "weighted operation mix, operations: "+strings.Join(loadTestOpNames(), ", "))
+	fs.IntVar(&cfg.Concurrency, "concurrency", 64, "max requests in flight; ticks beyond this are counted as dropped")
+	fs.DurationVar(&cfg.MaxP99, "max-p99", 0, "fail (exit 1) when p99 latency is above this, 0 = no check")
+	fs.Float64Var(&cfg.MaxErrors, "max-error-rate", -1, "fail (exit 1) when the ratio of failed requests is above this (0..1), negative = no check")
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	cfg.Target = strings.TrimRight(cfg.Target, "/")
+
+	var errs []string
+	if cfg.RPS <= 0 {
+		errs = append(errs, "rps must be positive")
+	}
+	if cfg.Duration <= 0 {
+		errs = append(errs, "duration must be positive")
+	}
+	if cfg.Concurrency <= 0 {
+		errs = append(errs, "concurrency must be positive")
+	}
+	m, err := parseLoadMix(*mix)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	cfg.Mix = m
+
+	if len(errs) > 0 {
+		return cfg, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return cfg, nil
+}
+
+// parseLoadMix membaca "read:80,write:20"
+func parseLoadMix(s string) (map[string]int, error) {
+	mix := map[string]int{}
+	for _, part := range splitList(s) {
+		name, weight, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("mix entry %q must be name:weight", part)
+		}
+		if _, known := loadTestOps[name]; !known {
+			return nil, fmt.Errorf("unknown mix operation %q (want one of %s)", name, strings.Join(loadTestOpNames(), ", "))
+		}
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("mix weight for %q must be a non-negative integer", name)
+		}
+		mix[name] += w
+	}
+
+	total := 0
+	for _, w := range mix {
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("mix must have at least one positive weight")
+	}
+	return mix, nil
+}
+
+func loadTestOpNames() []string {
+	names := make([]string, 0, len(loadTestOps))
+	for name := range loadTestOps {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// loadTestReport = output JSON loadtest
+type loadTestReport struct {
+	Target      string         `json:"target"`
+	Duration    string         `json:"duration"`
+	TargetRPS   int            `json:"targetRps"`
+	AchievedRPS float64        `json:"achievedRps"`
+	Requests    int            `json:"requests"`
+	Dropped     int            `json:"dropped"`
+	Failed      int            `json:"failed"`
+	LatencyMS   map[string]any `json:"latencyMs"`
+	// Statuses: "200", "201", ... ; Errors: kode "error" dari body atau "transport"
+	Statuses   map[string]int `json:"statuses"`
+	Errors     map[string]int `json:"errors"`
+	Operations map[string]int `json:"operations"`
+	Violations []string       `json:"violations,omitempty"`
+}
+
+type loadTester struct {
+	cfg loadTestConfig
+	api *client.Client
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	statuses  map[string]int
+	errors    map[string]int
+	ops       map[string]int
+	failed    int
+	ids       []client.UserID // ID hasil write, dipakai oleh read
+}
+
+func newLoadTester(cfg loadTestConfig) *loadTester {
+	return &loadTester{
+		cfg:      cfg,
+		api:      client.New(cfg.Target, &http.Client{Timeout: 10 * time.Second}),
+		statuses: map[string]int{},
+		errors:   map[string]int{},
+		ops:      map[string]int{},
+	}
+}
+
+func (lt *loadTester) randomID() (client.UserID, bool) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	if len(lt.ids) == 0 {
+		return "", false
+	}
+	return lt.ids[rand.Intn(len(lt.ids))], true
+}
+
+// pickOp memilih operasi secara acak sesuai bobot mix
+func (lt *loadTester) pickOp() string {
+	names := slices.Sorted(maps.Keys(lt.cfg.Mix))
+	total := 0
+	for _, name := range names {
+		total += lt.cfg.Mix[name]
+	}
+	n := rand.Intn(total)
+	for _, name := range names {
+		if n < lt.cfg.Mix[name] {
+			return name
+		}
+		n -= lt.cfg.Mix[name]
+	}
+	return names[len(names)-1]
+}
+
+func (lt *loadTester) run() loadTestReport {
+	ticker := time.NewTicker(time.Second / time.Duration(lt.cfg.RPS))
+	defer ticker.Stop()
+
+	sem := make(chan struct{}, lt.cfg.Concurrency)
+	var wg sync.WaitGroup
+	dropped := 0
+
+	start := time.Now()
+	deadline := start.Add(lt.cfg.Duration)
+	for now := range ticker.C {
+		if now.After(deadline) {
+			break
+		}
+		select {
+		case sem <- struct{}{}:
+		default:
+			dropped++
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			lt.do(lt.pickOp())
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	return lt.report(elapsed, dropped)
+}
+
+// do menjalankan satu request dan mencatat hasilnya
+func (lt *loadTester) do(op string) {
+	start := time.Now()
+	createdID, err := loadTestOps[op].run(context.Background(), lt)
+	latency := time.Since(start)
+
+	var apiErr *client.AppError
+	switch {
+	case errors.As(err, &apiErr):
+		errCode := apiErr.Code
+		// body bukan envelope error (mis. dari proxy)
+		if errCode == "http_error" {
+			errCode = "http_" + strconv.Itoa(apiErr.Status)
+		}
+		lt.record(op, latency, errCode, apiErr.Status, "")
+	case err != nil:
+		lt.record(op, latency, "transport", 0, "")
+	default:
+		lt.record(op, latency, "", loadTestOps[op].status, createdID)
+	}
+}
+
+func (lt *loadTester) record(op string, latency time.Duration, errCode string, status int, createdID client.UserID) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	lt.ops[op]++
+	if latency > 0 {
+		lt.latencies = append(lt.latencies, latency)
+	}
+	if status > 0 {
+		lt.statuses[strconv.Itoa(status)]++
+	}
+	if errCode != "" {
+		lt.errors[errCode]++
+		lt.failed++
+	}
+	if createdID != "" {
+		lt.ids = append(lt.ids, createdID)
+	}
+}
+
+func (lt *loadTester) report(elapsed time.Duration, dropped int) loadTestReport {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	slices.Sort(lt.latencies)
+	requests := 0
+	for _, n := range lt.ops {
+		requests += n
+	}
+
+	p99 := percentile(lt.latencies, 99)
+	r := loadTestReport{
+		Target:      lt.cfg.Target,
+		Duration:    elapsed.Round(time.Millisecond).String(),
+		TargetRPS:   lt.cfg.RPS,
+		AchievedRPS: float64(requests) / elapsed.Seconds(),
+		Requests:    requests,
+		Dropped:     dropped,
+		Failed:      lt.failed,
+		LatencyMS: map[string]any{
+			"p50": ms(percentile(lt.latencies, 50)),
+			"p90": ms(percentile(lt.latencies, 90)),
+			"p99": ms(p99),
+			"max": ms(percentile(lt.latencies, 100)),
+		},
+		Statuses:   lt.statuses,
+		Errors:     lt.errors,
+		Operations: lt.ops,
+	}
+
+	if lt.cfg.MaxP99 > 0 && p99 > lt.cfg.MaxP99 {
+		r.Violations = append(r.Violations, fmt.Sprintf("p99 %s is above max-p99 %s", p99, lt.cfg.MaxP99))
+	}
+	if lt.cfg.MaxErrors >= 0 && requests > 0 {
+		if rate := float64(lt.failed) / float64(requests); rate > lt.cfg.MaxErrors {
+			r.Violations = append(r.Violations, fmt.Sprintf("error rate %.4f is above max-error-rate %.4f", rate, lt.cfg.MaxErrors))
+		}
+	}
+	return r
+}
+
+// percentile dari slice yang sudah terurut (nearest-rank)
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (p*len(sorted) + 99) / 100
+	if i < 1 {
+		i = 1
+	}
+	return sorted[min(i, len(sorted))-1]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// loadTestMain = entry point subcommand, dipisah supaya main tetap pendek
+func loadTestMain() {
+	os.Exit(runLoadTest(os.Args[2:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,122 @@
+// File: /loadtest_test.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLoadTestAgainstServer menjalankan load kecil ke server lengkap, supaya
+// subcommand dan endpoint yang dipakainya tidak diam-diam rusak
+func TestLoadTestAgainstServer(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+
+	var stdout, stderr bytes.Buffer
+	code := runLoadTest([]string{
+		"-target", ts.URL + "/",
+		"-rps", "200",
+		"-duration", "150ms",
+		"-mix", "read:1,write:1,list:1",
+		"-max-error-rate", "0",
+	}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit %d, stderr %q, report %s", code, stderr.String(), stdout.String())
+	}
+
+	report := decodeBody[loadTestReport](t, stdout.String())
+	if report.Requests == 0 || report.Failed != 0 || report.Target != ts.URL {
+		t.Fatalf("report = %+v", report)
+	}
+	for _, op := range []string{"read", "write", "list"} {
+		if report.Operations[op] == 0 {
+			t.Errorf("no %s requests in %v", op, report.Operations)
+		}
+	}
+	if report.Statuses["201"] != report.Operations["write"] {
+		t.Errorf("statuses %v, %d writes", report.Statuses, report.Operations["write"])
+	}
+}
+
+// error dari client: kode dari envelope error, body lain jadi http_<status>
+func TestLoadTestErrorCodes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		errorJSON(w, http.StatusServiceUnavailable, "overloaded", "try again later", nil)
+	}))
+	defer ts.Close()
+
+	var stdout bytes.Buffer
+	code := runLoadTest([]string{"-target", ts.URL, "-rps", "200", "-duration", "100ms", "-mix", "read:1,write:1"}, &stdout, &bytes.Buffer{})
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stdout.String())
+	}
+	report := decodeBody[loadTestReport](t, stdout.String())
+	if report.Failed != report.Requests || report.Errors["overloaded"] != report.Operations["read"] || report.Errors["http_502"] != report.Operations["write"] {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.Statuses["503"] != report.Operations["read"] || report.Statuses["502"] != report.Operations["write"] {
+		t.Fatalf("statuses %v, operations %v", report.Statuses, report.Operations)
+	}
+}
+
+func TestLoadTestSLOViolation(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+
+	var stdout bytes.Buffer
+	// 1ns tidak pernah terpenuhi
+	code := runLoadTest([]string{"-target", ts.URL, "-rps", "50", "-duration", "60ms", "-max-p99", "1ns"}, &stdout, &bytes.Buffer{})
+	if code != 1 {
+		t.Fatalf("exit %d, want 1: %s", code, stdout.String())
+	}
+	var report loadTestReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Violations) != 1 || !strings.Contains(report.Violations[0], "above max-p99 1ns") {
+		t.Fatalf("violations = %v", report.Violations)
+	}
+}
+
+func TestParseLoadTestFlagsErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-rps", "0"}, "rps must be positive"},
+		{[]string{"-mix", "read"}, `mix entry "read" must be name:weight`},
+		{[]string{"-mix", "delete:5"}, `unknown mix operation "delete"`},
+		{[]string{"-mix", "read:0"}, "at least one positive weight"},
+		{[]string{"-duration", "0s", "-concurrency", "0"}, "duration must be positive; concurrency must be positive"},
+	}
+	for _, tt := range tests {
+		_, err := parseLoadTestFlags(tt.args, &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: error %v, want %q", tt.args, err, tt.want)
+		}
+	}
+
+	cfg, err := parseLoadTestFlags([]string{"-mix", "read:3,read:2,write:1"}, &bytes.Buffer{})
+	if err != nil || cfg.Mix["read"] != 5 || cfg.Duration != 10*time.Second {
+		t.Fatalf("cfg %+v, err %v", cfg, err)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for p, want := range map[int]time.Duration{50: 5, 90: 9, 99: 10, 100: 10, 1: 1} {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("p%d = %d, want %d", p, got, want)
+		}
+	}
+	if percentile(nil, 99) != 0 {
+		t.Error("percentile of no samples")
+	}
+}
@@ -2,173 +2,155 @@
 package main
 
 import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"flag"
-	"fmt"
-	"log"
+	"net"
 	"net/http"
+	"os"
 	"strings"
-	"time"
 )
 
 type apiResponse map[string]any
 
-// middleware logger (simple, beginner friendly)
-func requestLogger(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		log.Printf("IN  %s %s", r.Method, r.URL.RequestURI())
-
-		next.ServeHTTP(w, r)
-
-		log.Printf("OUT %s %s (%s)", r.Method, r.URL.RequestURI(), time.Since(start))
-	})
-}
+// version diisi saat build: go build -ldflags "-X main.version=1.2.3"
+var version = "dev"
 
 func main() {
-	port := flag.Int("port", 8080, "HTTP port for REST server")
-	flag.Parse()
-
-	mux := http.NewServeMux()
-
-	store := NewUserStore()
-	userService := NewUserService(store)
-	userHandler := NewUsersHandler(userService)
-
-	mux.HandleFunc("/users", userHandler.HandleUsers)
-	mux.HandleFunc("/users/", userHandler.HandleUserRoutes)
-
-	// GET /
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/" {
-			writeJSON(w, http.StatusNotFound, apiResponse{
-				"error": "not_found",
-				"path":  r.URL.Path,
-			})
-			return
-		}
+	// subcommand: ./app loadtest -target=... (lihat loadtest.go)
+	if len(os.Args) > 1 && os.Args[1] == "loadtest" {
+		loadTestMain()
+		return
+	}
 
-		writeJSON(w, http.StatusOK, apiResponse{
-			"service": "golang-beginner-rest",
-			"routes": []string{
-				"GET /health",
-				"GET /time",
-			},
-		})
-	})
-
-	// GET /health
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		if !requireMethod(w, r, http.MethodPost) {
-			return
+	cfg, err := LoadConfig(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		logFatal(err)
+	}
+	level, _ := parseLogLevel(cfg.LogLevel) // sudah dicek Validate
+	setLogLevel(level)
+	setupLogging(cfg.LogFormat, os.Stderr)
+
+	if cfg.PrintConfig {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(cfg.Effective()); err != nil {
+			logFatal(err)
 		}
+		return
+	}
 
-		writeJSON(w, http.StatusOK, apiResponse{
-			"status": "ok",
-		})
-	})
-
-	// GET /time
-	mux.HandleFunc("/time", func(w http.ResponseWriter, r *http.Request) {
-		if !requireMethod(w, r, http.MethodPost) {
-			return
-		}
+	srv, err := NewServer(cfg)
+	if err != nil {
+		logFatal(err)
+	}
 
-		writeJSON(w, http.StatusOK, apiResponse{
-			"time": time.Now().UTC().Format(time.RFC3339),
-		})
-	})
+	go watchReload(srv, os.Args[1:])
 
-	// GET echo with query params
-	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
-		if !requireMethod(w, r, http.MethodPost) {
-			return
+	// setelah shutdown: flush dan tutup access log
+	closeServer := func() {
+		if err := srv.Close(); err != nil {
+			logErrorf("close server: %v", err)
 		}
+	}
 
-		qName := strings.TrimSpace(r.URL.Query().Get("name"))
+	httpServer := srv.HTTPServer()
 
-		if qName == "" {
-			writeJSON(w, http.StatusBadRequest, apiResponse{
-				"error": "name_required",
-				"path":  r.URL.Path,
-			})
-			return
+	if cfg.UnixSocket != "" {
+		if cfg.Port != DefaultConfig().Port {
+			logWarnf("port %d is ignored because -unix-socket is set", cfg.Port)
 		}
 
-		writeJSON(w, http.StatusOK, apiResponse{
-			"name": qName,
-		})
-
-	})
-
-	// POST /sum
-	// File: /main.go (di dalam main())
-	mux.HandleFunc("/sum", func(w http.ResponseWriter, r *http.Request) {
-		if !requireMethod(w, r, http.MethodPost) {
-			return
+		ln, err := listenUnix(cfg.UnixSocket, cfg.SocketFileMode())
+		if err != nil {
+			logFatal(err)
 		}
+		logInfof("REST server listening on unix:%s (mode %s)", cfg.UnixSocket, cfg.SocketMode)
 
-		type sumRequest struct {
-			A *int `json:"a"`
-			B *int `json:"b"`
+		cleanup := func() {
+			removeSocket(cfg.UnixSocket)
+			closeServer()
 		}
-		var req sumRequest
-
-		if err := readJSON(w, r, &req); err != nil {
-			errorJSON(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
-			return
+		if err := serveListeners(httpServer, []net.Listener{ln}, cleanup); err != nil {
+			logFatal(err)
 		}
+		return
+	}
 
-		if req.A == nil || req.B == nil {
-			errorJSON(w, http.StatusBadRequest, "validation_failed", "missing required fields", []string{
-				"a is required",
-				"b is required",
-			})
-			return
+	if cfg.AutocertEnabled() {
+		m, err := newAutocertManager(cfg.AutocertDomains(), cfg.AutocertCache)
+		if err != nil {
+			logFatal(err)
 		}
-
-		writeJSON(w, http.StatusOK, map[string]any{
-			"result": *req.A + *req.B,
-		})
-	})
-
-	// POST /mul
-	// File: /main.go (di dalam main())
-	mux.HandleFunc("/mul", func(w http.ResponseWriter, r *http.Request) {
-		if !requireMethod(w, r, http.MethodPost) {
-			return
+		httpServer.TLSConfig = m.TLSConfig()
+		httpServer.TLSConfig.MinVersion = tls.VersionTLS12
+
+		// :80 melayani HTTP-01 challenge, request lain di-redirect ke HTTPS
+		challenge := &http.Server{
+			Addr:              ":80",
+			Handler:           m.HTTPHandler(httpsRedirectHandler(443)),
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
 		}
+		go func() {
+			logInfof("ACME HTTP-01 challenge listening on http://%s", challenge.Addr)
+			if err := challenge.ListenAndServe(); err != nil {
+				logFatal(err)
+			}
+		}()
+
+		logInfof("TLS mode: autocert (Let's Encrypt) for %s, cache %s", strings.Join(cfg.AutocertDomains(), ", "), cfg.AutocertCache)
+	}
 
-		type mulRequest struct {
-			A *int `json:"a"`
-			B *int `json:"b"`
+	if cfg.TLSEnabled() {
+		tlsConfig, err := serverTLSConfig(cfg)
+		if err != nil {
+			logFatal(err)
 		}
-		var req mulRequest
-
-		if err := readJSON(w, r, &req); err != nil {
-			errorJSON(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
-			return
+		if cfg.TLSClientCA != "" {
+			logInfof("mTLS: client certificates from %s (optional: %t)", cfg.TLSClientCA, cfg.TLSClientOptional)
 		}
+		httpServer.TLSConfig = tlsConfig
+		logInfof("TLS mode: certificate files %s, %s", cfg.TLSCert, cfg.TLSKey)
+	}
 
-		if req.A == nil || req.B == nil {
-			errorJSON(w, http.StatusBadRequest, "validation_failed", "missing required fields", []string{
-				"a is required",
-				"b is required",
-			})
-			return
-		}
+	addrs := cfg.ListenAddrs()
+	if cfg.AutocertEnabled() {
+		addrs = []string{srv.Addr()}
+	}
 
-		writeJSON(w, http.StatusOK, map[string]any{
-			"result": *req.A * *req.B,
-		})
-	})
+	// bind semua alamat dulu; kalau satu gagal, proses berhenti
+	listeners, err := listenAll(addrs)
+	if err != nil {
+		logFatal(err)
+	}
 
-	addr := fmt.Sprintf(":%d", *port)
-	log.Printf("REST server listening on %s", addr)
+	scheme := "http"
+	if httpServer.TLSConfig != nil {
+		scheme = "https"
+	}
+	for _, ln := range listeners {
+		logInfof("REST server listening on %s://%s", scheme, ln.Addr())
+	}
 
-	// pasang logger middleware untuk semua request
-	handler := requestLogger(mux)
+	if cfg.RedirectHTTP != "" {
+		redirect := &http.Server{
+			Addr:              cfg.RedirectHTTP,
+			Handler:           httpsRedirectHandler(cfg.RedirectPort()),
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		}
+		go func() {
+			logInfof("HTTP -> HTTPS redirect listening on http://%s", redirect.Addr)
+			if err := redirect.ListenAndServe(); err != nil {
+				logFatal(err)
+			}
+		}()
+	}
 
-	if err := http.ListenAndServe(addr, handler); err != nil {
-		log.Fatal(err)
+	if err := serveListeners(httpServer, listeners, closeServer); err != nil {
+		logFatal(err)
 	}
 }
@@ -0,0 +1,56 @@
+// File: /method_override.go
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const methodOverrideHeader = "X-HTTP-Method-Override"
+
+// overridableMethods = method yang boleh dikirim lewat X-HTTP-Method-Override
+var overridableMethods = []string{http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// methodOverride (-allow-method-override): POST dengan X-HTTP-Method-Override
+// PUT/PATCH/DELETE diteruskan ke router sebagai method itu. Header di request
+// non-POST atau dengan method lain = 400 invalid_override.
+// Dipasang setelah auth dan X-Signature (keduanya melihat POST asli); access
+// log juga tetap mencatat POST karena requestLogger memegang request asli.
+// Header tidak ikut ditandatangani, jadi request bertanda tangan dengan
+// override juga 400: POST yang tertangkap tidak bisa diputar ulang sebagai
+// DELETE/PUT. Client yang menandatangani cukup mengirim method aslinya.
+func methodOverride(enabled bool, next http.Handler) http.Handler {
+	if !enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := strings.TrimSpace(r.Header.Get(methodOverrideHeader))
+		if v == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			errorJSON(w, http.StatusBadRequest, "invalid_override", methodOverrideHeader+" is only allowed on POST", map[string]any{
+				"method": r.Method,
+			})
+			return
+		}
+		if authenticatedClient(r.Context()) == signedClient {
+			errorJSON(w, http.StatusBadRequest, "invalid_override", methodOverrideHeader+" is not allowed on signed requests, sign the real method instead", nil)
+			return
+		}
+		m := strings.ToUpper(v)
+		if !slices.Contains(overridableMethods, m) {
+			errorJSON(w, http.StatusBadRequest, "invalid_override", methodOverrideHeader+" must be one of "+strings.Join(overridableMethods, ", "), map[string]any{
+				"override": truncatePath(v),
+			})
+			return
+		}
+
+		requestLog(r.Context()).Info("method override", "override", m)
+		o := r.Clone(r.Context())
+		o.Method = m
+		next.ServeHTTP(w, o)
+	})
+}
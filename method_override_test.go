@@ -0,0 +1,120 @@
+// File: /method_override_test.go
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMethodOverride(t *testing.T) {
+	tests := []struct {
+		name, method, override, body string
+		wantStatus                   int
+		wantCode                     string
+		wantName                     string // "" = user 1 sudah dihapus
+	}{
+		{"PUT", "POST", "PUT", `{"name":"Put Name"}`, http.StatusOK, "", "Put Name"},
+		{"PATCH lower case", "POST", "patch", `{"name":"Patched"}`, http.StatusOK, "", "Patched"},
+		{"DELETE", "POST", "DELETE", "", http.StatusOK, "", ""},
+		{"override to GET", "POST", "GET", "", http.StatusBadRequest, "invalid_override", "Ada"},
+		{"override to POST", "POST", "POST", `{"name":"X"}`, http.StatusBadRequest, "invalid_override", "Ada"},
+		{"unknown override", "POST", "TRACE", "", http.StatusBadRequest, "invalid_override", "Ada"},
+		{"override on GET", "GET", "DELETE", "", http.StatusBadRequest, "invalid_override", "Ada"},
+		{"override on PUT", "PUT", "DELETE", `{"name":"X"}`, http.StatusBadRequest, "invalid_override", "Ada"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, testConfig(func(c *Config) { c.AllowMethodOverride = true }))
+			doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+			res, body := doRequest(t, ts, tt.method, "/users/1", tt.body, methodOverrideHeader, tt.override)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantCode != "" && decodeBody[errorResponse](t, body).Error != tt.wantCode {
+				t.Fatalf("body %s, want error %q", body, tt.wantCode)
+			}
+
+			res, body = doRequest(t, ts, "GET", "/users/1", "")
+			if tt.wantName == "" {
+				if res.StatusCode != http.StatusNotFound {
+					t.Fatalf("after override: status %d, want 404: %s", res.StatusCode, body)
+				}
+				return
+			}
+			if got := decodeBody[userEnvelope](t, body).Data.Name; got != tt.wantName {
+				t.Fatalf("name %q, want %q", got, tt.wantName)
+			}
+		})
+	}
+}
+
+// tanpa -allow-method-override header diabaikan: POST tetap POST
+func TestMethodOverrideDisabled(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+	res, body := doRequest(t, ts, "POST", "/users/1", "", methodOverrideHeader, "DELETE")
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("status %d, want 405: %s", res.StatusCode, body)
+	}
+	if res, _ = doRequest(t, ts, "GET", "/users/1", ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("user gone after ignored override: status %d", res.StatusCode)
+	}
+	// header di GET juga tidak ditolak
+	if res, _ = doRequest(t, ts, "GET", "/users/1", "", methodOverrideHeader, "DELETE"); res.StatusCode != http.StatusOK {
+		t.Fatalf("GET with header: status %d", res.StatusCode)
+	}
+}
+
+// access log mencatat method asli (POST), bukan hasil override
+func TestMethodOverrideAccessLog(t *testing.T) {
+	logCfg, logPath := withAccessLog(t, "json")
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		logCfg(c)
+		c.AllowMethodOverride = true
+	}))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+	if res, body := doRequest(t, ts, "POST", "/users/1", "", methodOverrideHeader, "DELETE"); res.StatusCode != http.StatusOK {
+		t.Fatalf("override: status %d: %s", res.StatusCode, body)
+	}
+
+	lines := waitAccessLog(t, logPath, 2)
+	var entry struct {
+		Method string `json:"method"`
+		Status int    `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Method != "POST" || entry.Status != http.StatusOK {
+		t.Fatalf("access log %s", lines[1])
+	}
+}
+
+// override tidak ikut ditandatangani: POST bertanda tangan yang diputar
+// ulang dengan override ditolak, user tidak terhapus
+func TestMethodOverrideSignedRequest(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		c.AllowMethodOverride = true
+		c.SignatureSecret = testSignatureSecret
+	}))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+	header := append(sign(testSignatureSecret, time.Now(), "POST", "/users/1", ""), methodOverrideHeader, "DELETE")
+	res, body := doRequest(t, ts, "POST", "/users/1", "", header...)
+	if res.StatusCode != http.StatusBadRequest || decodeBody[errorResponse](t, body).Error != "invalid_override" {
+		t.Fatalf("signed override: status %d: %s", res.StatusCode, body)
+	}
+	if res, _ = doRequest(t, ts, "GET", "/users/1", ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("user gone after signed override: status %d", res.StatusCode)
+	}
+
+	// method asli yang ditandatangani tetap jalan
+	res, body = doRequest(t, ts, "DELETE", "/users/1", "", sign(testSignatureSecret, time.Now(), "DELETE", "/users/1", "")...)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("signed DELETE: status %d: %s", res.StatusCode, body)
+	}
+}
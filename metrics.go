@@ -0,0 +1,141 @@
+// File: /metrics.go
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets = default bucket client Prometheus (detik)
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metricMethods: method lain dari client dicatat sebagai OTHER
+// supaya label tidak bisa dibuat sembarangan.
+var metricMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true,
+	http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+	http.MethodOptions: true,
+}
+
+type metricKey struct {
+	method string
+	route  string
+}
+
+type histogram struct {
+	// counts[i] = jumlah observasi <= latencyBuckets[i] (non-kumulatif),
+	// index terakhir = di atas bucket terbesar
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Metrics menyimpan histogram latency per (method, route template).
+// Route diambil dari routePattern, bukan raw path, jadi jumlah series terbatas.
+type Metrics struct {
+	mu    sync.Mutex
+	hists map[metricKey]*histogram
+
+	// limiter = -max-inflight, nil = gauge in-flight tidak ditulis
+	limiter *inflightLimiter
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{hists: map[metricKey]*histogram{}}
+}
+
+// observe aman dipanggil dengan m nil (metrics tidak dipasang)
+func (m *Metrics) observe(method, route string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	if !metricMethods[method] {
+		method = "OTHER"
+	}
+	sec := d.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := metricKey{method: method, route: route}
+	h, ok := m.hists[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets)+1)}
+		m.hists[key] = h
+	}
+	i := sort.SearchFloat64s(latencyBuckets, sec)
+	h.counts[i]++
+	h.sum += sec
+	h.count++
+}
+
+// writeText menulis format text exposition Prometheus (version 0.0.4)
+func (m *Metrics) writeText(b *strings.Builder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]metricKey, 0, len(m.hists))
+	for k := range m.hists {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	const name = "http_request_duration_seconds"
+	b.WriteString("# HELP " + name + " Request latency by route template and method.\n")
+	b.WriteString("# TYPE " + name + " histogram\n")
+	for _, k := range keys {
+		h := m.hists[k]
+		labels := fmt.Sprintf(`method="%s",route="%s"`, promEscape(k.method), promEscape(k.route))
+
+		var cum uint64
+		for i, le := range latencyBuckets {
+			cum += h.counts[i]
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, strconv.FormatFloat(le, 'g', -1, 64), cum)
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+
+	if l := m.limiter; l != nil {
+		b.WriteString("# HELP http_requests_in_flight Requests currently holding a -max-inflight slot.\n")
+		b.WriteString("# TYPE http_requests_in_flight gauge\n")
+		fmt.Fprintf(b, "http_requests_in_flight %d\n", l.InFlight())
+		b.WriteString("# HELP http_requests_in_flight_max Value of -max-inflight.\n")
+		b.WriteString("# TYPE http_requests_in_flight_max gauge\n")
+		fmt.Fprintf(b, "http_requests_in_flight_max %d\n", l.Max())
+		b.WriteString("# HELP http_requests_rejected_total Requests rejected with 503 server_busy.\n")
+		b.WriteString("# TYPE http_requests_rejected_total counter\n")
+		fmt.Fprintf(b, "http_requests_rejected_total %d\n", l.Rejected())
+	}
+}
+
+// promEscape: escape label value sesuai format text (\\, \", \n)
+func promEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// GET /metrics
+func (m *Metrics) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !requireMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	var b strings.Builder
+	m.writeText(&b)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(b.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(b.String()))
+}
@@ -0,0 +1,79 @@
+// File: /metrics_test.go
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsExposition(t *testing.T) {
+	m := NewMetrics()
+	m.observe("GET", "/users/{id}", 3*time.Millisecond)
+	m.observe("GET", "/users/{id}", 5*time.Millisecond) // tepat di batas = masuk bucket itu
+	m.observe("GET", "/users/{id}", 200*time.Millisecond)
+	m.observe("GET", "/users/{id}", 11*time.Second)
+	m.observe("BREW", `/a"b\c`, time.Millisecond)
+
+	var b strings.Builder
+	m.writeText(&b)
+	got := b.String()
+
+	want := `# HELP http_request_duration_seconds Request latency by route template and method.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{method="OTHER",route="/a\"b\\c",le="0.005"} 1
+`
+	if !strings.HasPrefix(got, want) {
+		t.Fatalf("output starts with:\n%s\nwant:\n%s", got[:min(len(got), 300)], want)
+	}
+	for _, line := range []string{
+		`http_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="0.005"} 2`,
+		`http_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="0.1"} 2`,
+		`http_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="0.25"} 3`,
+		`http_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="10"} 3`,
+		`http_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="+Inf"} 4`,
+		`http_request_duration_seconds_sum{method="GET",route="/users/{id}"} 11.208`,
+		`http_request_duration_seconds_count{method="GET",route="/users/{id}"} 4`,
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("missing line %s", line)
+		}
+	}
+	// tanpa limiter tidak ada gauge in-flight
+	if strings.Contains(got, "http_requests_in_flight") {
+		t.Error("in-flight gauge written without a limiter")
+	}
+}
+
+// route template, bukan raw path: id berbeda = satu series
+func TestMetricsUseRouteTemplates(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.MaxInflight = 4 }))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+	for _, path := range []string{"/users/1", "/users/2", "/users/abc", "/no/such/route", "/another/unknown"} {
+		doRequest(t, ts, "GET", path, "")
+	}
+
+	res, body := doRequest(t, ts, "GET", "/metrics", "")
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Fatalf("status %d, Content-Type %q", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	for _, line := range []string{
+		`http_request_duration_seconds_count{method="POST",route="/users"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/users/{id}"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="<unmatched>"} 3`, // id tidak valid belum sampai route
+		`# TYPE http_requests_in_flight gauge`,
+		`http_requests_in_flight 1`, // request /metrics ini sendiri
+		`http_requests_in_flight_max 4`,
+		`http_requests_rejected_total 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing line %s", line)
+		}
+	}
+	for _, raw := range []string{"/users/1", "/users/2", "/no/such/route"} {
+		if strings.Contains(body, `route="`+raw+`"`) {
+			t.Errorf("raw path %s used as a label", raw)
+		}
+	}
+}
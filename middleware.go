@@ -0,0 +1,389 @@
+// File: /middleware.go
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// unmatchedRoute dicatat untuk request yang tidak cocok dengan route mana pun,
+// supaya path dari client (bisa apa saja) tidak jadi label log.
+const unmatchedRoute = "<unmatched>"
+
+// redirectRoute = route untuk request yang di-redirect trimTrailingSlash
+const redirectRoute = "<redirect>"
+
+type routeInfoKey struct{}
+
+// routeInfo diisi handler lewat setRoutePattern, dibaca requestLogger setelah selesai
+type routeInfo struct {
+	pattern string
+	// mux = pattern ServeMux, disalin captureRoute (middleware boleh r.WithContext)
+	mux string
+	// client = label API key yang dipakai (lihat requireAPIKey)
+	client string
+}
+
+func routeInfoFrom(ctx context.Context) *routeInfo {
+	ri, _ := ctx.Value(routeInfoKey{}).(*routeInfo)
+	return ri
+}
+
+// setRoutePattern dipakai handler yang parsing path sendiri (mis. /users/{id})
+// karena pattern mux-nya hanya subtree "/users/".
+func setRoutePattern(r *http.Request, pattern string) {
+	if ri := routeInfoFrom(r.Context()); ri != nil {
+		ri.pattern = pattern
+	}
+}
+
+// captureRoute dipasang tepat di depan router: ServeMux mengisi r.Pattern
+// di request yang diterimanya, jadi disalin ke routeInfo setelah selesai.
+func captureRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if ri := routeInfoFrom(r.Context()); ri != nil {
+			ri.mux = r.Pattern
+		}
+	})
+}
+
+// routePattern = pattern dari handler, lalu pattern ServeMux.
+// Subtree ("/users/", "/") yang tidak di-claim handler = unmatched.
+func routePattern(r *http.Request, ri *routeInfo) string {
+	if ri.pattern != "" {
+		return ri.pattern
+	}
+
+	pattern := ri.mux
+	if pattern == "" {
+		pattern = r.Pattern
+	}
+	if _, p, ok := strings.Cut(pattern, " "); ok {
+		pattern = p // buang prefix method "GET /x"
+	}
+	if pattern == "" || (strings.HasSuffix(pattern, "/") && pattern != r.URL.Path) {
+		return unmatchedRoute
+	}
+	return pattern
+}
+
+// requestLogger menulis satu record per request dan memasang logger
+// request-scoped di context (lihat requestLog). Record ditulis ke access
+// kalau diisi, selain itu ke log aplikasi di level debug.
+// Field route = route pattern; path (raw) hanya kalau logRawPath.
+// Request yang lebih lama dari slowThreshold (> 0) juga dicatat sebagai WARN.
+// Latency per route masuk ke metrics (boleh nil).
+func requestLogger(access *accessLogger, metrics *Metrics, logRawPath bool, slowThreshold time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ri := &routeInfo{}
+
+		logger := requestLog(r.Context()).With(requestFields(r, logRawPath)...)
+
+		ctx := context.WithValue(r.Context(), routeInfoKey{}, ri)
+		r = r.WithContext(withLogger(ctx, logger))
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		route := routePattern(r, ri)
+		fields := requestFields(r, logRawPath)
+		if ri.client != "" {
+			logger = logger.With("api_key", ri.client)
+			fields = append(fields, "api_key", ri.client)
+		}
+		elapsed := time.Since(start)
+		metrics.observe(r.Method, route, elapsed)
+		if slowThreshold > 0 && elapsed > slowThreshold {
+			logger.Warn("slow request",
+				"route", route,
+				"status", rec.Status(),
+				"duration_ms", durationMS(elapsed),
+				"threshold_ms", durationMS(slowThreshold),
+			)
+		}
+
+		if access == nil {
+			logger.Debug("request",
+				"route", route,
+				"status", rec.Status(),
+				"duration_ms", durationMS(elapsed),
+				"bytes", rec.bytes,
+			)
+			return
+		}
+
+		uri := route
+		if logRawPath {
+			uri = truncatePath(r.URL.RequestURI())
+		}
+		access.log(r.Context(), fields, accessEntry{
+			Time:       start,
+			RequestID:  requestIDFromContext(r.Context()),
+			RemoteAddr: clientAddr(r),
+			Method:     r.Method,
+			URI:        uri,
+			Proto:      r.Proto,
+			Route:      route,
+			Status:     rec.Status(),
+			Bytes:      rec.bytes,
+			Duration:   elapsed,
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+			Client:     ri.client,
+		})
+	})
+}
+
+type requestIDKey struct{}
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 64
+)
+
+// withRequestID memakai X-Request-ID dari client kalau wajar (<= 64 karakter
+// ASCII printable tanpa spasi), selain itu membuat ID baru. ID disimpan di
+// context (requestIDFromContext) dan dikirim balik di header response.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+type correlationIDKey struct{}
+
+const correlationIDHeader = "X-Correlation-ID"
+
+// withCorrelationID: X-Correlation-ID dari client (aturan sama dengan
+// request ID) atau request ID kalau tidak ada. Dipasang setelah
+// withRequestID; ID ikut dikirim di response dan request keluar
+// (lihat setCorrelationHeader).
+func withCorrelationID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(correlationIDHeader)
+		if !validRequestID(id) {
+			id = requestIDFromContext(r.Context())
+		}
+
+		w.Header().Set(correlationIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), correlationIDKey{}, id)))
+	})
+}
+
+// correlationIDFromContext = correlation ID request ("" di luar request)
+func correlationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// setCorrelationHeader untuk HTTP keluar (webhook, dll.) supaya service
+// lain menerima correlation ID yang sama
+func setCorrelationHeader(ctx context.Context, req *http.Request) {
+	if id := correlationIDFromContext(ctx); id != "" {
+		req.Header.Set(correlationIDHeader, id)
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDFromContext = request_id dari requestLogger ("" di luar request)
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestFields = field yang ada di setiap log line milik request
+func requestFields(r *http.Request, logRawPath bool) []any {
+	fields := []any{
+		"request_id", requestIDFromContext(r.Context()),
+		"correlation_id", correlationIDFromContext(r.Context()),
+		"method", r.Method,
+		"remote_addr", clientAddr(r),
+	}
+	if logRawPath {
+		fields = append(fields, "path", truncatePath(r.URL.RequestURI()))
+	}
+	return fields
+}
+
+// newRequestID = 16 karakter hex acak untuk field request_id
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// statusRecorder mencatat status dan ukuran body response untuk log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Status = status yang ditulis handler (200 kalau handler tidak menulis apa pun)
+func (rec *statusRecorder) Status() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
+
+// Flush meneruskan ke writer asli (kalau mendukung), untuk response streaming
+func (rec *statusRecorder) Flush() {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	_ = http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+// Unwrap supaya http.ResponseController tetap menemukan writer asli.
+// Flush writer pembungkus di repo ini juga lewat ResponseController, jadi
+// pembungkus baru tanpa Flush tidak diam-diam memutus streaming.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// recoverPanics mengubah panic di handler menjadi JSON 500 internal_error
+// (atau hanya log kalau response sudah mulai ditulis). http.ErrAbortHandler
+// di-panic ulang supaya net/http memutus koneksi seperti biasa.
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			requestLog(r.Context()).Error("panic in handler",
+				"panic", fmt.Sprint(v),
+				"stack", string(debug.Stack()),
+				"response_started", rec.status != 0,
+			)
+			if rec.status == 0 {
+				errorJSON(rec, http.StatusInternalServerError, "internal_error", "unexpected error", nil)
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// clientAddr: koneksi lewat Unix socket tidak punya IP (RemoteAddr "" atau "@")
+func clientAddr(r *http.Request) string {
+	if r.RemoteAddr == "" || r.RemoteAddr == "@" {
+		return "unix"
+	}
+	return r.RemoteAddr
+}
+
+// limitURISize menolak path / query yang terlalu panjang dengan 414.
+// Panjang dihitung setelah percent-decoding.
+func limitURISize(maxPath, maxQuery int, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maxPath > 0 && len(r.URL.Path) > maxPath {
+			errorJSON(w, http.StatusRequestURITooLong, "uri_too_long", "request path is too long", apiResponse{
+				"limit": maxPath,
+				"part":  "path",
+			})
+			return
+		}
+
+		if maxQuery > 0 && decodedLen(r.URL.RawQuery) > maxQuery {
+			errorJSON(w, http.StatusRequestURITooLong, "uri_too_long", "query string is too long", apiResponse{
+				"limit": maxQuery,
+				"part":  "query",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pprofPrefix = index net/http/pprof, link di halamannya relatif jadi "/" wajib
+const pprofPrefix = "/debug/pprof/"
+
+// trimTrailingSlash: URL kanonik tidak pernah diakhiri "/" (kecuali root dan pprofPrefix).
+// /users/ dan /users/5/ di-redirect 308 ke /users dan /users/5, query ikut,
+// method dan body dipertahankan. Location diberi prefix basePath seperti _links.
+// "/" di awal juga diringkas jadi satu: //evil.com/ tidak boleh menjadi
+// Location protocol-relative //evil.com (open redirect).
+func trimTrailingSlash(basePath string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" || r.URL.Path == pprofPrefix || !strings.HasSuffix(r.URL.Path, "/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		u := *r.URL
+		u.Path = "/" + strings.Trim(u.Path, "/")
+		if u.RawPath != "" {
+			u.RawPath = "/" + strings.Trim(u.RawPath, "/")
+		}
+		setRoutePattern(r, redirectRoute)
+		http.Redirect(w, r, strings.TrimSuffix(basePath, "/")+u.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// limitBody membatasi ukuran request body untuk semua handler
+func limitBody(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func decodedLen(rawQuery string) int {
+	decoded, err := url.QueryUnescape(rawQuery)
+	if err != nil {
+		return len(rawQuery)
+	}
+	return len(decoded)
+}
@@ -0,0 +1,463 @@
+// File: /middleware_test.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitURISize(t *testing.T) {
+	logCfg, logPath := withAccessLog(t, "json")
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		logCfg(c)
+		c.MaxPathBytes = 300
+		c.MaxQueryBytes = 20
+	}))
+
+	atLimit := "/" + strings.Repeat("a", 299)
+	overLimit := atLimit + "a"
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantCode   string
+		wantPart   string
+	}{
+		// di batas = lolos ke router (404 biasa), path di-echo terpotong
+		{name: "path at limit", path: atLimit, wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "path over limit", path: overLimit, wantStatus: http.StatusRequestURITooLong, wantCode: "uri_too_long", wantPart: "path"},
+		{name: "query at limit", path: "/echo?name=" + strings.Repeat("b", 15), wantStatus: http.StatusOK},
+		{name: "query over limit", path: "/echo?name=" + strings.Repeat("b", 16), wantStatus: http.StatusRequestURITooLong, wantCode: "uri_too_long", wantPart: "query"},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", tt.path, "")
+		if res.StatusCode != tt.wantStatus {
+			t.Fatalf("%s: status %d, want %d: %s", tt.name, res.StatusCode, tt.wantStatus, body)
+		}
+		if tt.wantCode == "" {
+			continue
+		}
+		var out struct {
+			Error   string         `json:"error"`
+			Details map[string]any `json:"details"`
+		}
+		if err := json.Unmarshal([]byte(body), &out); err != nil {
+			t.Fatalf("%s: %v: %s", tt.name, err, body)
+		}
+		if out.Error != tt.wantCode {
+			t.Fatalf("%s: error %q, want %q", tt.name, out.Error, tt.wantCode)
+		}
+		if tt.wantPart != "" && out.Details["part"] != tt.wantPart {
+			t.Fatalf("%s: details %v, want part %q", tt.name, out.Details, tt.wantPart)
+		}
+		if tt.wantCode == "not_found" {
+			want := atLimit[:maxEchoedPathBytes] + truncatedMarker
+			if out.Details["path"] != want {
+				t.Fatalf("%s: echoed path %q, want %q", tt.name, out.Details["path"], want)
+			}
+		}
+	}
+
+	lines := waitAccessLog(t, logPath, len(tests))
+	var first struct {
+		URI    string `json:"uri"`
+		Status int    `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Status != http.StatusNotFound || first.URI != atLimit[:maxEchoedPathBytes]+truncatedMarker {
+		t.Fatalf("access log line = %s", lines[0])
+	}
+}
+
+// TestRequestLoggerRecordsStatus: record "request" (level debug, tanpa
+// access log) memuat status dan ukuran response, termasuk 500 dari panic
+// TestTrimTrailingSlash: tiap varian "/" di akhir = 308 ke URL kanonik,
+// root dan index pprof tidak disentuh
+func TestTrimTrailingSlash(t *testing.T) {
+	tests := []struct {
+		name, basePath, method, path string
+		wantLocation                 string
+	}{
+		{"collection", "", "GET", "/users/", "/users"},
+		{"item", "", "GET", "/users/5/", "/users/5"},
+		{"query kept", "", "GET", "/users/?limit=1&sort=name", "/users?limit=1&sort=name"},
+		{"double slash", "", "GET", "/users//", "/users"},
+		{"post keeps method", "", "POST", "/users/", "/users"},
+		// bukan open redirect: "/" di awal diringkas jadi satu
+		{"protocol-relative host", "", "GET", "//evil.com/", "/evil.com"},
+		{"many leading slashes", "", "GET", "///evil.com//", "/evil.com"},
+		{"only slashes", "", "GET", "///", "/"},
+		{"base path", "/api/v1", "GET", "/users/5/", "/api/v1/users/5"},
+		{"base path with host", "/api/v1", "GET", "//evil.com/", "/api/v1/evil.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, testConfig(func(c *Config) { c.BasePath = tt.basePath }))
+			res, body := doRequest(t, ts, tt.method, tt.path, "")
+			if res.StatusCode != http.StatusPermanentRedirect {
+				t.Fatalf("%s %s: status %d, want 308: %s", tt.method, tt.path, res.StatusCode, body)
+			}
+			if got := res.Header.Get("Location"); got != tt.wantLocation {
+				t.Fatalf("%s %s: Location %q, want %q", tt.method, tt.path, got, tt.wantLocation)
+			}
+		})
+	}
+
+	ts := newTestServer(t, testConfig(func(c *Config) { withAdmin(c); c.Pprof = true }))
+	for _, path := range []string{"/", pprofPrefix, "/users", "/users/1"} {
+		if res, _ := doRequest(t, ts, "GET", path, ""); res.StatusCode == http.StatusPermanentRedirect {
+			t.Errorf("GET %s redirected to %q", path, res.Header.Get("Location"))
+		}
+	}
+}
+
+func TestRequestLoggerRecordsStatus(t *testing.T) {
+	logs := captureLog(t, levelDebug)
+	_, ts := newTestServerWithRoutes(t, testConfig(nil), map[string]http.HandlerFunc{
+		"/test/panic": func(http.ResponseWriter, *http.Request) { panic("boom") },
+	})
+
+	tests := []struct {
+		method, path, body string
+		status             int
+	}{
+		{"POST", "/users", `{"name":"Ada"}`, http.StatusCreated},
+		{"GET", "/users/99", "", http.StatusNotFound},
+		{"GET", "/test/panic", "", http.StatusInternalServerError},
+	}
+	for i, tt := range tests {
+		id := fmt.Sprintf("status-%d", i)
+		res, body := doRequest(t, ts, tt.method, tt.path, tt.body, "X-Request-ID", id)
+		if res.StatusCode != tt.status {
+			t.Fatalf("%s %s: status %d, want %d", tt.method, tt.path, res.StatusCode, tt.status)
+		}
+
+		var line map[string]any
+		for _, rec := range logs.Records(t) {
+			if rec["msg"] == "request" && rec["request_id"] == id {
+				line = rec
+			}
+		}
+		if line == nil {
+			t.Fatalf("%s %s: no request log line", tt.method, tt.path)
+		}
+		if line["status"] != float64(tt.status) || line["bytes"] != float64(len(body)) || line["method"] != tt.method {
+			t.Errorf("%s %s: log line %v, want status %d bytes %d", tt.method, tt.path, line, tt.status, len(body))
+		}
+		if _, ok := line["duration_ms"].(float64); !ok {
+			t.Errorf("%s %s: duration_ms missing: %v", tt.method, tt.path, line)
+		}
+	}
+}
+
+// handler yang tidak pernah memanggil WriteHeader tercatat 200
+func TestStatusRecorderDefaultsTo200(t *testing.T) {
+	logs := captureLog(t, levelDebug)
+	h := requestLogger(nil, nil, false, 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/x", nil))
+
+	line := findLog(logs.Records(t), "request")
+	if rec.Code != http.StatusOK || line == nil || line["status"] != float64(200) || line["bytes"] != float64(5) {
+		t.Fatalf("status %d, log line %v", rec.Code, line)
+	}
+}
+
+// Flush lewat requestLogger sampai ke writer asli (streaming NDJSON)
+func TestRequestLoggerForwardsFlush(t *testing.T) {
+	h := requestLogger(nil, nil, false, 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/x", nil))
+	if !rec.Flushed {
+		t.Fatal("response was not flushed")
+	}
+}
+
+// slowRoutes = satu route lambat (sleep) dan satu cepat
+func slowRoutes(sleep time.Duration) map[string]http.HandlerFunc {
+	return map[string]http.HandlerFunc{
+		"/test/slow": func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(sleep)
+			w.WriteHeader(http.StatusAccepted)
+		},
+		"/test/fast": func(w http.ResponseWriter, r *http.Request) {},
+	}
+}
+
+// slowLines = record "slow request" untuk request id
+func slowLines(t *testing.T, logs *logBuffer, id string) []map[string]any {
+	var out []map[string]any
+	for _, rec := range logs.Records(t) {
+		if rec["msg"] == "slow request" && rec["request_id"] == id {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+func TestSlowRequestWarning(t *testing.T) {
+	logs := captureLog(t, levelWarn)
+	cfg := testConfig(func(c *Config) { c.SlowRequestThreshold = 20 * time.Millisecond })
+	srv, ts := newTestServerWithRoutes(t, cfg, slowRoutes(60*time.Millisecond))
+
+	doRequest(t, ts, "GET", "/test/slow", "", "X-Request-ID", "slow-1")
+	doRequest(t, ts, "GET", "/test/fast", "", "X-Request-ID", "fast-1")
+
+	lines := slowLines(t, logs, "slow-1")
+	if len(lines) != 1 {
+		t.Fatalf("got %d slow lines for the slow request: %v", len(lines), logs.Records(t))
+	}
+	line := lines[0]
+	if line["level"] != "WARN" || line["method"] != "GET" || line["route"] != "/test/slow" || line["status"] != float64(http.StatusAccepted) || line["threshold_ms"] != float64(20) {
+		t.Errorf("slow line %v", line)
+	}
+	if ms, _ := line["duration_ms"].(float64); ms < 60 {
+		t.Errorf("duration_ms %v, want >= 60", line["duration_ms"])
+	}
+	if lines := slowLines(t, logs, "fast-1"); len(lines) != 0 {
+		t.Errorf("fast request logged as slow: %v", lines)
+	}
+
+	// reload ke 0 = mati, lalu nyala lagi tanpa restart
+	cfg.SlowRequestThreshold = 0
+	if _, _, err := srv.Reload(cfg); err != nil {
+		t.Fatal(err)
+	}
+	setLogLevel(levelWarn) // Reload memasang level dari cfg
+	doRequest(t, ts, "GET", "/test/slow", "", "X-Request-ID", "slow-2")
+	if lines := slowLines(t, logs, "slow-2"); len(lines) != 0 {
+		t.Errorf("threshold 0 still warns: %v", lines)
+	}
+
+	cfg.SlowRequestThreshold = 20 * time.Millisecond
+	if _, _, err := srv.Reload(cfg); err != nil {
+		t.Fatal(err)
+	}
+	setLogLevel(levelWarn)
+	doRequest(t, ts, "GET", "/test/slow", "", "X-Request-ID", "slow-3")
+	if lines := slowLines(t, logs, "slow-3"); len(lines) != 1 {
+		t.Errorf("reloaded threshold: got %d slow lines", len(lines))
+	}
+}
+
+var hexRequestID = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+func TestRequestIDGeneratedAndSupplied(t *testing.T) {
+	logs := captureLog(t, levelDebug)
+	_, ts := newTestServerWithRoutes(t, testConfig(nil), map[string]http.HandlerFunc{
+		"/test/request-id": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(requestIDFromContext(r.Context())))
+		},
+	})
+
+	// dari client: dipakai apa adanya di header, context, body error dan log
+	supplied := "client-" + strings.Repeat("x", maxRequestIDLength-len("client-"))
+	res, body := doRequest(t, ts, "GET", "/test/request-id", "", "X-Request-ID", supplied)
+	if res.Header.Get("X-Request-ID") != supplied || body != supplied {
+		t.Fatalf("supplied: header %q, handler saw %q", res.Header.Get("X-Request-ID"), body)
+	}
+	res, body = doRequest(t, ts, "GET", "/users/99", "", "X-Request-ID", supplied)
+	if got := decodeBody[errorResponse](t, body); res.Header.Get("X-Request-ID") != supplied || got.RequestID != supplied {
+		t.Fatalf("supplied: 404 body %s", body)
+	}
+
+	// tanpa header: ID baru per request
+	seen := map[string]bool{}
+	for range 3 {
+		res, body = doRequest(t, ts, "GET", "/test/request-id", "")
+		id := res.Header.Get("X-Request-ID")
+		if !hexRequestID.MatchString(id) || body != id || seen[id] {
+			t.Fatalf("generated: header %q, handler saw %q (seen %v)", id, body, seen)
+		}
+		seen[id] = true
+	}
+	res, body = doRequest(t, ts, "GET", "/users/99", "")
+	generated := res.Header.Get("X-Request-ID")
+	if got := decodeBody[errorResponse](t, body); !hexRequestID.MatchString(generated) || got.RequestID != generated {
+		t.Fatalf("generated: header %q, 404 body %s", generated, body)
+	}
+
+	for _, id := range []string{supplied, generated} {
+		found := false
+		for _, rec := range logs.Records(t) {
+			found = found || (rec["msg"] == "request" && rec["request_id"] == id && rec["route"] == "/users/{id}")
+		}
+		if !found {
+			t.Errorf("no request log line with request_id %q", id)
+		}
+	}
+}
+
+// ID yang tidak wajar dari client diganti, bukan dipotong atau di-escape
+func TestRequestIDRejectsInvalid(t *testing.T) {
+	for _, id := range []string{
+		"",
+		strings.Repeat("x", maxRequestIDLength+1),
+		"has space",
+		"tab\tid",
+		"new\nline",
+		"ünïcode",
+		"del\x7f",
+	} {
+		var inner string
+		h := withRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inner = requestIDFromContext(r.Context())
+		}))
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header[requestIDHeader] = []string{id}
+		h.ServeHTTP(rec, req)
+
+		got := rec.Header().Get(requestIDHeader)
+		if !hexRequestID.MatchString(got) || inner != got {
+			t.Errorf("%q: response ID %q, context ID %q", id, got, inner)
+		}
+	}
+}
+
+func TestCorrelationIDRoundTrip(t *testing.T) {
+	// downstream = service lain yang dipanggil handler lewat setCorrelationHeader
+	var downstreamGot string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstreamGot = r.Header.Get(correlationIDHeader)
+	}))
+	defer downstream.Close()
+
+	_, ts := newTestServerWithRoutes(t, testConfig(nil), map[string]http.HandlerFunc{
+		"/test/outbound": func(w http.ResponseWriter, r *http.Request) {
+			req, err := http.NewRequestWithContext(r.Context(), "POST", downstream.URL, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			setCorrelationHeader(r.Context(), req)
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		},
+	})
+
+	const id = "trace-7f3a/checkout"
+	res, _ := doRequest(t, ts, "GET", "/test/outbound", "", "X-Correlation-ID", id, "X-Request-ID", "req-1")
+	if res.Header.Get("X-Correlation-ID") != id || downstreamGot != id {
+		t.Fatalf("response %q, downstream %q, want %q", res.Header.Get("X-Correlation-ID"), downstreamGot, id)
+	}
+
+	res, body := doRequest(t, ts, "GET", "/users/99", "", "X-Correlation-ID", id)
+	got := decodeBody[errorResponse](t, body)
+	if res.StatusCode != http.StatusNotFound || got.CorrelationID != id || got.RequestID == id || got.RequestID == "" {
+		t.Fatalf("404 body %s", body)
+	}
+
+	// tanpa header = request ID, sampai ke downstream juga
+	res, _ = doRequest(t, ts, "GET", "/test/outbound", "", "X-Request-ID", "req-2")
+	if res.Header.Get("X-Correlation-ID") != "req-2" || downstreamGot != "req-2" {
+		t.Fatalf("default: response %q, downstream %q", res.Header.Get("X-Correlation-ID"), downstreamGot)
+	}
+	_, body = doRequest(t, ts, "GET", "/users/99", "", "X-Request-ID", "req-3")
+	if got := decodeBody[errorResponse](t, body); got.CorrelationID != "req-3" {
+		t.Fatalf("default: 404 body %s", body)
+	}
+
+	// ID tidak wajar = request ID
+	res, _ = doRequest(t, ts, "GET", "/health", "", "X-Correlation-ID", strings.Repeat("c", maxRequestIDLength+1), "X-Request-ID", "req-4")
+	if res.Header.Get("X-Correlation-ID") != "req-4" {
+		t.Fatalf("invalid: response %q", res.Header.Get("X-Correlation-ID"))
+	}
+}
+
+// di luar request (tanpa correlation ID) request keluar tidak diberi header
+func TestSetCorrelationHeaderWithoutID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	setCorrelationHeader(context.Background(), req)
+	if _, ok := req.Header[correlationIDHeader]; ok {
+		t.Fatalf("header set: %v", req.Header)
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	logs := captureLog(t, levelError)
+	_, ts := newTestServerWithRoutes(t, testConfig(nil), map[string]http.HandlerFunc{
+		"/test/nil-map": func(http.ResponseWriter, *http.Request) {
+			var m map[string]int
+			m["x"]++
+		},
+		"/test/partial": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"data":[`))
+			panic("halfway")
+		},
+	})
+
+	res, body := doRequest(t, ts, "GET", "/test/nil-map", "", "X-Request-ID", "panic-1")
+	got := decodeBody[errorResponse](t, body)
+	if res.StatusCode != http.StatusInternalServerError || got.Error != "internal_error" || got.Message != "unexpected error" || got.RequestID != "panic-1" {
+		t.Fatalf("status %d: %s", res.StatusCode, body)
+	}
+	if strings.Contains(body, "nil map") {
+		t.Fatalf("panic value leaked to client: %s", body)
+	}
+	line := findLog(logs.Records(t), "panic in handler")
+	if line == nil || !strings.Contains(line["panic"].(string), "nil map") || !strings.Contains(line["stack"].(string), "middleware_test.go") || line["request_id"] != "panic-1" {
+		t.Fatalf("panic log line %v", line)
+	}
+
+	// response sudah mulai: status dan body parsial dibiarkan, hanya log
+	res, body = doRequest(t, ts, "GET", "/test/partial", "")
+	if res.StatusCode != http.StatusOK || body != `{"data":[` {
+		t.Fatalf("partial: status %d: %s", res.StatusCode, body)
+	}
+	var started bool
+	for _, rec := range logs.Records(t) {
+		if rec["msg"] == "panic in handler" && rec["panic"] == "halfway" {
+			started, _ = rec["response_started"].(bool)
+		}
+	}
+	if !started {
+		t.Errorf("partial panic not logged with response_started=true: %v", logs.Records(t))
+	}
+
+	// server tetap melayani request berikutnya
+	for range 3 {
+		if res, body := doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`); res.StatusCode != http.StatusCreated {
+			t.Fatalf("after panic: status %d: %s", res.StatusCode, body)
+		}
+	}
+}
+
+// http.ErrAbortHandler diteruskan ke net/http (koneksi diputus), bukan 500
+func TestRecoverPanicsRepanicsAbort(t *testing.T) {
+	h := recoverPanics(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	rec := httptest.NewRecorder()
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", v)
+		}
+		if rec.Body.Len() != 0 {
+			t.Fatalf("body written: %s", rec.Body)
+		}
+	}()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	t.Fatal("ServeHTTP returned normally")
+}
@@ -0,0 +1,65 @@
+// File: /ndjson.go
+package main
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// ndjsonFlushEvery = jumlah baris per flush, supaya tidak satu syscall per user
+const ndjsonFlushEvery = 100
+
+// wantsNDJSON: ?format=ndjson atau Accept: application/x-ndjson
+func wantsNDJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "ndjson" {
+		return true
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mt == ndjsonContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNDJSON menulis n item (item(i)) sebagai satu objek JSON per baris.
+// Status 200 dikirim sebelum item pertama; error di tengah jalan (client
+// putus, ctx dibatalkan, encode gagal) hanya bisa dicatat di log, lalu
+// stream dihentikan. Write deadline server (writeTimeout, 0 = tanpa batas)
+// diperpanjang tiap chunk: export besar tidak terpotong -write-timeout,
+// tapi client yang berhenti membaca tetap diputus.
+func writeNDJSON(w http.ResponseWriter, r *http.Request, writeTimeout time.Duration, n int, item func(i int) any) {
+	rc := http.NewResponseController(w)
+	extend := func() {
+		if writeTimeout > 0 {
+			_ = rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+		}
+	}
+	extend()
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for i := 0; i < n; i++ {
+		if err := r.Context().Err(); err != nil {
+			requestLog(r.Context()).Warn("ndjson stream stopped", "written", i, "total", n, "err", err)
+			return
+		}
+		if err := enc.Encode(item(i)); err != nil {
+			requestLog(r.Context()).Warn("ndjson stream stopped", "written", i, "total", n, "err", err)
+			return
+		}
+		if (i+1)%ndjsonFlushEvery == 0 {
+			_ = rc.Flush()
+			extend()
+		}
+	}
+	_ = rc.Flush()
+}
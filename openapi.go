@@ -0,0 +1,108 @@
+// File: /openapi.go
+package main
+
+import (
+	_ "embed"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// spec ditulis manual, update openapi.json setiap kali route berubah
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>golang-beginner-rest - API docs</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+type DocsHandler struct {
+	// spec = openapi.json + examples, dibangun sekali saat start
+	spec     []byte
+	examples map[string]exampleDoc
+}
+
+// NewDocsHandler gagal kalau ada example yang tidak cocok dengan schema,
+// supaya dokumentasi tidak ketinggalan saat field berubah.
+func NewDocsHandler() (*DocsHandler, error) {
+	spec, examples, err := buildOpenAPI(openAPISpec, routeExamples)
+	if err != nil {
+		return nil, err
+	}
+	return &DocsHandler{spec: spec, examples: examples}, nil
+}
+
+// GET /openapi.json
+func (h *DocsHandler) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if !requireMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	// spec lebih besar dari buffer net/http: tanpa Content-Length GET
+	// dikirim chunked, beda dengan HEAD
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(h.spec)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(h.spec)
+}
+
+// GET /docs
+func (h *DocsHandler) HandleDocs(w http.ResponseWriter, r *http.Request) {
+	if !requireMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// CSP default (default-src 'none') memblokir Swagger UI
+	if w.Header().Get("Content-Security-Policy") != "" {
+		w.Header().Set("Content-Security-Policy", docsCSP)
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(swaggerUIPage))
+}
+
+// GET /examples, GET /examples/{route-id}
+func (h *DocsHandler) HandleExamples(w http.ResponseWriter, r *http.Request) {
+	if !requireMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/examples"), "/")
+	if id == "" {
+		ids := make([]string, 0, len(h.examples))
+		for id := range h.examples {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		writeData(w, http.StatusOK, ids, apiResponse{
+			"count": len(ids),
+		})
+		return
+	}
+
+	ex, ok := h.examples[id]
+	if ok {
+		setRoutePattern(r, "/examples/{route-id}")
+	}
+	if !ok {
+		notFound(w, r)
+		return
+	}
+	writeData(w, http.StatusOK, ex, nil)
+}
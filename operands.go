@@ -0,0 +1,205 @@
+// File: /operands.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// body untuk /sum dan /mul: {"values": [1, 2.5]} atau bentuk lama {"a": 1, "b": 2}.
+// RawMessage supaya angka bisa integer atau float, dan elemen yang salah
+// jadi validation_failed per field, bukan invalid_json untuk seluruh body.
+type operandsRequest struct {
+	A      json.RawMessage   `json:"a"`
+	B      json.RawMessage   `json:"b"`
+	Values []json.RawMessage `json:"values"`
+}
+
+// operand = satu angka dari body; integer tetap integer supaya hasilnya integer
+type operand struct {
+	isInt bool
+	i     int
+	f     float64
+}
+
+func (o operand) float() float64 {
+	if o.isInt {
+		return float64(o.i)
+	}
+	return o.f
+}
+
+var (
+	// errNotNumber = operand bukan JSON number yang finite
+	errNotNumber = errors.New("not a finite number")
+	// errOperandRange = literal integer di luar jangkauan int
+	errOperandRange = errors.New("integer outside the int range")
+)
+
+// parseOperand menerima JSON number yang finite; string angka ("1") ditolak.
+// Literal integer (tanpa "." atau eksponen) di luar int = errOperandRange,
+// bukan float64 yang diam-diam kehilangan presisi.
+func parseOperand(raw json.RawMessage) (operand, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] == '"' {
+		return operand{}, errNotNumber
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return operand{}, errNotNumber
+	}
+	if !strings.ContainsAny(n.String(), ".eE") {
+		i, err := strconv.Atoi(n.String())
+		if errors.Is(err, strconv.ErrRange) {
+			return operand{}, errOperandRange
+		}
+		if err == nil {
+			return operand{isInt: true, i: i}, nil
+		}
+	}
+
+	f, err := strconv.ParseFloat(n.String(), 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return operand{}, errNotNumber
+	}
+	return operand{f: f}, nil
+}
+
+func isMissing(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) == 0 || string(raw) == "null"
+}
+
+func (req operandsRequest) Validate() error {
+	_, err := req.operands()
+	return err
+}
+
+// operands mem-parse semua angka, error berisi semua field yang salah.
+// Literal integer di luar int = 400 overflow (kalau tidak ada kesalahan lain).
+func (req operandsRequest) operands() ([]operand, error) {
+	var (
+		ops      []operand
+		details  []string
+		overflow []string
+	)
+	message := "missing required fields"
+	// invalid = catat kesalahan parse operand name
+	invalid := func(name string, err error) {
+		if errors.Is(err, errOperandRange) {
+			overflow = append(overflow, name+" is outside the integer range")
+			return
+		}
+		details = append(details, name+" must be a finite number")
+	}
+
+	if req.Values != nil {
+		message = "invalid operands"
+		if !isMissing(req.A) || !isMissing(req.B) {
+			details = append(details, "use either values or a and b, not both")
+		}
+		if len(req.Values) == 0 {
+			details = append(details, "values must contain at least one number")
+		}
+		for i, raw := range req.Values {
+			op, err := parseOperand(raw)
+			if err != nil {
+				invalid(fmt.Sprintf("values[%d]", i), err)
+			}
+			ops = append(ops, op)
+		}
+	} else {
+		for _, f := range []struct {
+			name string
+			raw  json.RawMessage
+		}{{"a", req.A}, {"b", req.B}} {
+			if isMissing(f.raw) {
+				details = append(details, f.name+" is required")
+				continue
+			}
+			op, err := parseOperand(f.raw)
+			if err != nil {
+				message = "invalid operands"
+				invalid(f.name, err)
+			}
+			ops = append(ops, op)
+		}
+	}
+
+	if len(details) > 0 {
+		return nil, validationError(message, append(details, overflow...))
+	}
+	if len(overflow) > 0 {
+		return nil, &AppError{
+			Status:  http.StatusBadRequest,
+			Code:    "overflow",
+			Message: fmt.Sprintf("integer operand is outside the range %d..%d", math.MinInt, math.MaxInt),
+			Details: overflow,
+		}
+	}
+	return ops, nil
+}
+
+// errOverflow = hasil integer di luar jangkauan int (tidak dibiarkan wrap around)
+var errOverflow = &AppError{
+	Status:  http.StatusBadRequest,
+	Code:    "overflow",
+	Message: fmt.Sprintf("integer result is outside the range %d..%d", math.MinInt, math.MaxInt),
+}
+
+// addInt / mulInt: ok=false kalau hasil overflow
+func addInt(a, b int) (int, bool) {
+	c := a + b
+	if (b > 0 && c < a) || (b < 0 && c > a) {
+		return 0, false
+	}
+	return c, true
+}
+
+func mulInt(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, false
+	}
+	return c, true
+}
+
+// reduceOperands menghitung dengan int kalau semua operand integer,
+// selain itu dengan float64. Integer yang overflow = 400 overflow,
+// hasil float yang tidak finite ditolak.
+func reduceOperands(ops []operand, start int, intOp func(a, b int) (int, bool), floatOp func(a, b float64) float64) (any, error) {
+	allInt := true
+	for _, op := range ops {
+		allInt = allInt && op.isInt
+	}
+
+	if allInt {
+		result := start
+		for _, op := range ops {
+			var ok bool
+			if result, ok = intOp(result, op.i); !ok {
+				return nil, errOverflow
+			}
+		}
+		return result, nil
+	}
+
+	result := float64(start)
+	for _, op := range ops {
+		result = floatOp(result, op.float())
+	}
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return nil, validationError("invalid operands", []string{"result is not a finite number"})
+	}
+	return result, nil
+}
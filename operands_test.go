@@ -0,0 +1,141 @@
+// File: /operands_test.go
+package main
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// operandCase = satu POST ke /sum atau /mul dan hasil yang diharapkan.
+// wantResult = body JSON ".data.result" (float64 setelah decode), atau
+// wantDetails kalau 400.
+type operandCase struct {
+	name, path, body string
+	wantStatus       int
+	wantResult       float64
+	wantCode         string
+	wantDetails      []any
+}
+
+func runOperandCases(t *testing.T, cases []operandCase) {
+	t.Helper()
+	ts := newTestServer(t, testConfig(nil))
+	for _, tt := range cases {
+		res, body := doRequest(t, ts, "POST", tt.path, tt.body)
+		if res.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, res.StatusCode, tt.wantStatus, body)
+			continue
+		}
+		if tt.wantStatus == http.StatusOK {
+			got := decodeBody[struct {
+				Data struct {
+					Result float64 `json:"result"`
+				} `json:"data"`
+			}](t, body).Data.Result
+			if got != tt.wantResult {
+				t.Errorf("%s: result %v, want %v", tt.name, got, tt.wantResult)
+			}
+			continue
+		}
+		got := decodeBody[errorResponse](t, body)
+		if got.Error != tt.wantCode {
+			t.Errorf("%s: error %q, want %q: %s", tt.name, got.Error, tt.wantCode, body)
+		}
+		if tt.wantDetails != nil && !reflect.DeepEqual(got.Details, tt.wantDetails) {
+			t.Errorf("%s: details %v, want %v", tt.name, got.Details, tt.wantDetails)
+		}
+	}
+}
+
+func TestSumMulOperandForms(t *testing.T) {
+	runOperandCases(t, []operandCase{
+		{name: "sum array", path: "/sum", body: `{"values":[1,2,3,4]}`, wantStatus: 200, wantResult: 10},
+		{name: "mul array", path: "/mul", body: `{"values":[2,3,4]}`, wantStatus: 200, wantResult: 24},
+		{name: "single value", path: "/mul", body: `{"values":[7]}`, wantStatus: 200, wantResult: 7},
+		{name: "sum legacy", path: "/sum", body: `{"a":2,"b":3}`, wantStatus: 200, wantResult: 5},
+		{name: "mul legacy", path: "/mul", body: `{"a":-2,"b":3}`, wantStatus: 200, wantResult: -6},
+		{name: "empty array", path: "/sum", body: `{"values":[]}`, wantStatus: 400, wantCode: "validation_failed",
+			wantDetails: []any{"values must contain at least one number"}},
+		{name: "mixed forms", path: "/sum", body: `{"a":1,"values":[2]}`, wantStatus: 400, wantCode: "validation_failed",
+			wantDetails: []any{"use either values or a and b, not both"}},
+		{name: "invalid elements", path: "/mul", body: `{"values":[1,"2",null,true]}`, wantStatus: 400, wantCode: "validation_failed",
+			wantDetails: []any{"values[1] must be a finite number", "values[2] must be a finite number", "values[3] must be a finite number"}},
+		{name: "legacy missing b", path: "/sum", body: `{"a":1}`, wantStatus: 400, wantCode: "validation_failed",
+			wantDetails: []any{"b is required"}},
+		{name: "legacy string operand", path: "/sum", body: `{"a":"1","b":2}`, wantStatus: 400, wantCode: "validation_failed",
+			wantDetails: []any{"a must be a finite number"}},
+		{name: "values not an array", path: "/sum", body: `{"values":5}`, wantStatus: 400, wantCode: "invalid_json"},
+	})
+}
+
+func TestSumMulFloats(t *testing.T) {
+	runOperandCases(t, []operandCase{
+		{name: "float sum", path: "/sum", body: `{"values":[0.5,0.25,1]}`, wantStatus: 200, wantResult: 1.75},
+		{name: "float legacy", path: "/mul", body: `{"a":1.5,"b":-2}`, wantStatus: 200, wantResult: -3},
+		{name: "exponent", path: "/mul", body: `{"values":[2.5e3,4]}`, wantStatus: 200, wantResult: 10000},
+		// float ikut jalur float64, bukan overflow integer
+		{name: "large floats", path: "/sum", body: `{"values":[1e308,1e307]}`, wantStatus: 200, wantResult: 1.1e308},
+		{name: "float mixed with huge int", path: "/sum", body: `{"values":[9223372036854775807,0.5]}`, wantStatus: 200, wantResult: 9223372036854775807.5},
+		{name: "result overflows to Inf", path: "/mul", body: `{"values":[1e308,10.0]}`, wantStatus: 400, wantCode: "validation_failed",
+			wantDetails: []any{"result is not a finite number"}},
+		{name: "literal out of range", path: "/sum", body: `{"values":[1e400,1]}`, wantStatus: 400, wantCode: "validation_failed",
+			wantDetails: []any{"values[0] must be a finite number"}},
+		// JSON tidak punya NaN: token NaN = body tidak valid, string "NaN" = bukan angka
+		{name: "NaN token", path: "/sum", body: `{"values":[NaN]}`, wantStatus: 400, wantCode: "invalid_json"},
+		{name: "NaN string", path: "/sum", body: `{"a":"NaN","b":1}`, wantStatus: 400, wantCode: "validation_failed",
+			wantDetails: []any{"a must be a finite number"}},
+	})
+}
+
+func TestParseOperandKeepsIntegers(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    operand
+		wantErr error
+	}{
+		{"3", operand{isInt: true, i: 3}, nil},
+		{"-0", operand{isInt: true, i: 0}, nil},
+		{"3.0", operand{f: 3}, nil},
+		{"1e2", operand{f: 100}, nil},
+		// batas int: masih integer persis
+		{"9223372036854775807", operand{isInt: true, i: math.MaxInt}, nil},
+		{"-9223372036854775808", operand{isInt: true, i: math.MinInt}, nil},
+		// literal integer di luar int = overflow, bukan float yang kehilangan presisi
+		{"9223372036854775808", operand{}, errOperandRange},
+		{"-9223372036854775809", operand{}, errOperandRange},
+		{"100000000000000000000000", operand{}, errOperandRange},
+		// bentuk float tetap float walau besar
+		{"9223372036854775808.0", operand{f: 9223372036854775808}, nil},
+		{"9.3e18", operand{f: 9.3e18}, nil},
+		{"1e999", operand{}, errNotNumber},
+		{`"3"`, operand{}, errNotNumber},
+		{"null", operand{}, errNotNumber},
+	}
+	for _, tt := range tests {
+		got, err := parseOperand([]byte(tt.raw))
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("parseOperand(%s) = %+v, %v; want %+v, %v", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSumMulOperandOverflow(t *testing.T) {
+	runOperandCases(t, []operandCase{
+		{name: "max int", path: "/sum", body: `{"values":[9223372036854775807,0]}`, wantStatus: 200, wantResult: math.MaxInt},
+		{name: "min int", path: "/mul", body: `{"values":[-9223372036854775808,1]}`, wantStatus: 200, wantResult: math.MinInt},
+		{name: "one past max int", path: "/sum", body: `{"values":[9223372036854775808,1]}`, wantStatus: 400, wantCode: "overflow",
+			wantDetails: []any{"values[0] is outside the integer range"}},
+		{name: "one past min int", path: "/sum", body: `{"a":1,"b":-9223372036854775809}`, wantStatus: 400, wantCode: "overflow",
+			wantDetails: []any{"b is outside the integer range"}},
+		// literal overflow tidak dihitung sebagai float walau ada operand float
+		{name: "huge int with float", path: "/sum", body: `{"values":[0.5,18446744073709551616]}`, wantStatus: 400, wantCode: "overflow",
+			wantDetails: []any{"values[1] is outside the integer range"}},
+		// kesalahan lain didahulukan, overflow ikut di details
+		{name: "overflow and invalid", path: "/sum", body: `{"values":["1",9223372036854775808]}`, wantStatus: 400, wantCode: "validation_failed",
+			wantDetails: []any{"values[0] must be a finite number", "values[1] is outside the integer range"}},
+		{name: "result overflow", path: "/sum", body: `{"values":[9223372036854775807,1]}`, wantStatus: 400, wantCode: "overflow"},
+	})
+}
@@ -0,0 +1,42 @@
+// File: /passwords.go
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	// maxPasswordBytes = batas input bcrypt, sisanya akan diabaikan diam-diam
+	maxPasswordBytes = 72
+)
+
+// validatePassword: minPasswordLength karakter, paling banyak maxPasswordBytes byte
+func validatePassword(field, password string) error {
+	var details []string
+	if n := utf8.RuneCountInString(password); n < minPasswordLength {
+		details = append(details, fmt.Sprintf("%s must be at least %d characters", field, minPasswordLength))
+	}
+	if len(password) > maxPasswordBytes {
+		details = append(details, fmt.Sprintf("%s must be at most %d bytes", field, maxPasswordBytes))
+	}
+	if len(details) > 0 {
+		return validationError("invalid field", details)
+	}
+	return nil
+}
+
+func hashPassword(password string, cost int) ([]byte, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+	return hash, nil
+}
+
+// errInvalidPassword = password lama salah di POST /users/{id}/password
+var errInvalidPassword = &AppError{Status: http.StatusForbidden, Code: "invalid_password", Message: "current password is incorrect"}
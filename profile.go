@@ -0,0 +1,75 @@
+// File: /profile.go
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+)
+
+const (
+	maxDisplayNameLength = 100
+	maxBioLength         = 500
+	maxAvatarURLLength   = 2048
+)
+
+// Profile = data tampilan user, disimpan bersama User (tidak ikut JSON User)
+type Profile struct {
+	DisplayName string `json:"displayName"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatarUrl"`
+}
+
+// profileResource = response /users/{id}/profile
+type profileResource struct {
+	ID UserID `json:"id"`
+	Profile
+}
+
+// ProfilePatch = PATCH /users/{id}/profile, field nil = tidak diubah,
+// string kosong = dikosongkan
+type ProfilePatch struct {
+	DisplayName *string `json:"displayName"`
+	Bio         *string `json:"bio"`
+	AvatarURL   *string `json:"avatarUrl"`
+}
+
+func (patch ProfilePatch) Validate() error {
+	var details []string
+	if patch.DisplayName != nil && utf8.RuneCountInString(*patch.DisplayName) > maxDisplayNameLength {
+		details = append(details, fmt.Sprintf("displayName must be at most %d characters", maxDisplayNameLength))
+	}
+	if patch.Bio != nil && utf8.RuneCountInString(*patch.Bio) > maxBioLength {
+		details = append(details, fmt.Sprintf("bio must be at most %d characters", maxBioLength))
+	}
+	if patch.AvatarURL != nil && *patch.AvatarURL != "" {
+		if len(*patch.AvatarURL) > maxAvatarURLLength {
+			details = append(details, fmt.Sprintf("avatarUrl must be at most %d bytes", maxAvatarURLLength))
+		} else if !validAvatarURL(*patch.AvatarURL) {
+			details = append(details, "avatarUrl must be an absolute http or https URL")
+		}
+	}
+	if len(details) > 0 {
+		return validationError("invalid field", details)
+	}
+	return nil
+}
+
+// apply mengembalikan p dengan field patch yang tidak nil
+func (patch ProfilePatch) apply(p Profile) Profile {
+	if patch.DisplayName != nil {
+		p.DisplayName = *patch.DisplayName
+	}
+	if patch.Bio != nil {
+		p.Bio = *patch.Bio
+	}
+	if patch.AvatarURL != nil {
+		p.AvatarURL = *patch.AvatarURL
+	}
+	return p
+}
+
+func validAvatarURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,88 @@
+// File: /profile_test.go
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type profileEnvelope struct {
+	Data profileResource `json:"data"`
+}
+
+// PATCH satu field: field lain di profile dan user tetap
+func TestPatchProfileSingleField(t *testing.T) {
+	full := Profile{DisplayName: "Ada", Bio: "math", AvatarURL: "https://example.com/ada.png"}
+	tests := []struct {
+		name, body string
+		want       Profile
+	}{
+		{"displayName", `{"displayName":"Countess"}`, Profile{DisplayName: "Countess", Bio: "math", AvatarURL: "https://example.com/ada.png"}},
+		{"bio", `{"bio":"engines"}`, Profile{DisplayName: "Ada", Bio: "engines", AvatarURL: "https://example.com/ada.png"}},
+		{"avatarUrl", `{"avatarUrl":"http://example.org/a.jpg"}`, Profile{DisplayName: "Ada", Bio: "math", AvatarURL: "http://example.org/a.jpg"}},
+		// string kosong mengosongkan field itu saja, null = tidak diubah
+		{"clear bio", `{"bio":""}`, Profile{DisplayName: "Ada", AvatarURL: "https://example.com/ada.png"}},
+		{"null bio", `{"bio":null}`, full},
+		{"empty patch", `{}`, full},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, testConfig(nil))
+			doRequest(t, ts, "POST", "/users", `{"name":"Ada Lovelace","role":"admin"}`)
+			if res, body := doRequest(t, ts, "PATCH", "/users/1/profile", `{"displayName":"Ada","bio":"math","avatarUrl":"https://example.com/ada.png"}`); res.StatusCode != http.StatusOK {
+				t.Fatalf("setup: status %d: %s", res.StatusCode, body)
+			}
+
+			res, body := doRequest(t, ts, "PATCH", "/users/1/profile", tt.body)
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("status %d: %s", res.StatusCode, body)
+			}
+			if got := decodeBody[profileEnvelope](t, body).Data; got.ID != "1" || got.Profile != tt.want {
+				t.Fatalf("PATCH response = %+v, want %+v", got, tt.want)
+			}
+			_, body = doRequest(t, ts, "GET", "/users/1/profile", "")
+			if got := decodeBody[profileEnvelope](t, body).Data.Profile; got != tt.want {
+				t.Fatalf("GET after PATCH = %+v, want %+v", got, tt.want)
+			}
+			_, body = doRequest(t, ts, "GET", "/users/1", "")
+			if u := decodeBody[userEnvelope](t, body).Data; u.Name != "Ada Lovelace" || u.Role != RoleAdmin {
+				t.Fatalf("user changed by profile PATCH: %+v", u)
+			}
+		})
+	}
+}
+
+func TestPatchProfileErrors(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+	tests := []struct {
+		name, path, body string
+		wantStatus       int
+		wantDetails      []any
+	}{
+		{"missing user", "/users/99/profile", `{"bio":"x"}`, http.StatusNotFound, nil},
+		{"long displayName", "/users/1/profile", `{"displayName":"` + strings.Repeat("a", maxDisplayNameLength+1) + `"}`, http.StatusBadRequest,
+			[]any{"displayName must be at most 100 characters"}},
+		{"relative avatarUrl", "/users/1/profile", `{"avatarUrl":"/ada.png"}`, http.StatusBadRequest,
+			[]any{"avatarUrl must be an absolute http or https URL"}},
+		{"ftp avatarUrl", "/users/1/profile", `{"avatarUrl":"ftp://example.com/a.png","bio":"` + strings.Repeat("b", maxBioLength+1) + `"}`, http.StatusBadRequest,
+			[]any{"bio must be at most 500 characters", "avatarUrl must be an absolute http or https URL"}},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "PATCH", tt.path, tt.body)
+		if res.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, res.StatusCode, tt.wantStatus, body)
+			continue
+		}
+		if tt.wantDetails != nil && !reflect.DeepEqual(decodeBody[errorResponse](t, body).Details, tt.wantDetails) {
+			t.Errorf("%s: body %s", tt.name, body)
+		}
+	}
+	// PATCH yang ditolak tidak mengubah apa pun
+	if _, body := doRequest(t, ts, "GET", "/users/1/profile", ""); decodeBody[profileEnvelope](t, body).Data.Profile != (Profile{}) {
+		t.Fatalf("profile changed by rejected PATCH: %s", body)
+	}
+}
@@ -0,0 +1,127 @@
+// File: /reload.go
+package main
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"syscall"
+)
+
+// reloadableSetting = setting yang boleh diganti lewat SIGHUP tanpa restart.
+// key = tag json di Config (sama dengan -print-config dan file -config).
+type reloadableSetting struct {
+	key   string
+	apply func(dst *Config, src Config)
+}
+
+var reloadableSettings = []reloadableSetting{
+	{"logRawPath", func(dst *Config, src Config) { dst.LogRawPath = src.LogRawPath }},
+	{"maxPathBytes", func(dst *Config, src Config) { dst.MaxPathBytes = src.MaxPathBytes }},
+	{"maxQueryBytes", func(dst *Config, src Config) { dst.MaxQueryBytes = src.MaxQueryBytes }},
+	{"maxBodyBytes", func(dst *Config, src Config) { dst.MaxBodyBytes = src.MaxBodyBytes }},
+	{"logBodies", func(dst *Config, src Config) { dst.LogBodies = src.LogBodies }},
+	{"requestTimeout", func(dst *Config, src Config) { dst.RequestTimeout = src.RequestTimeout }},
+	{"slowRequestThreshold", func(dst *Config, src Config) { dst.SlowRequestThreshold = src.SlowRequestThreshold }},
+	{"securityHeadersEnabled", func(dst *Config, src Config) { dst.SecurityHeadersEnabled = src.SecurityHeadersEnabled }},
+	{"securityHeaders", func(dst *Config, src Config) { dst.SecurityHeaders = src.SecurityHeaders }},
+	{"serverHeader", func(dst *Config, src Config) { dst.ServerHeader = src.ServerHeader }},
+	{"authReads", func(dst *Config, src Config) { dst.AuthReads = src.AuthReads }},
+	{"authOpenPaths", func(dst *Config, src Config) { dst.AuthOpenPaths = src.AuthOpenPaths }},
+	{"logLevel", func(dst *Config, src Config) { dst.LogLevel = src.LogLevel }},
+}
+
+type configChange struct {
+	Key string
+	Old any
+	New any
+}
+
+func (c configChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Key, c.Old, c.New)
+}
+
+// diffConfig membandingkan dua config lewat Effective, urut nama key
+func diffConfig(old, next Config) []configChange {
+	a, b := old.Effective(), next.Effective()
+
+	var changes []configChange
+	for _, key := range slices.Sorted(maps.Keys(b)) {
+		if !reflect.DeepEqual(a[key], b[key]) {
+			changes = append(changes, configChange{Key: key, Old: a[key], New: b[key]})
+		}
+	}
+	return changes
+}
+
+// Reload menerapkan setting reloadable dari next dengan mengganti chain
+// middleware secara atomik; request yang sedang jalan tetap memakai chain
+// lama. Perubahan lain dikembalikan sebagai restart (butuh restart).
+func (s *Server) Reload(next Config) (applied, restart []configChange, err error) {
+	if err := next.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	merged := *s.live.Load()
+	for _, change := range diffConfig(merged, next) {
+		i := slices.IndexFunc(reloadableSettings, func(r reloadableSetting) bool { return r.key == change.Key })
+		if i < 0 {
+			restart = append(restart, change)
+			continue
+		}
+		reloadableSettings[i].apply(&merged, next)
+		applied = append(applied, change)
+	}
+
+	if len(applied) > 0 {
+		chain := s.buildChain(merged)
+		s.live.Store(&merged)
+		s.chain.Store(&chain)
+		level, _ := parseLogLevel(merged.LogLevel)
+		setLogLevel(level)
+	}
+	return applied, restart, nil
+}
+
+// watchReload: SIGHUP membaca ulang config (file -config, env, flag awal)
+func watchReload(s *Server, args []string) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+
+	for range ch {
+		cfg, err := LoadConfig(args, os.LookupEnv)
+		if err != nil {
+			logErrorf("config reload failed, keeping current config: %v", err)
+			continue
+		}
+
+		applied, restart, err := s.Reload(cfg)
+		if err != nil {
+			logErrorf("config reload failed, keeping current config: %v", err)
+			continue
+		}
+		for _, c := range applied {
+			logInfof("config reload: %s", c)
+		}
+		for _, c := range restart {
+			logWarnf("config reload: %s requires restart, not applied", c)
+		}
+		if len(applied) == 0 && len(restart) == 0 {
+			logInfof("config reload: no changes")
+		}
+	}
+}
+
+// swapHandler meneruskan ke chain terbaru (lihat Reload)
+func (s *Server) swapHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(*s.chain.Load()).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,79 @@
+// File: /roles.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Role = hak akses pemanggil (dari API key atau user pemiliknya) dan field role milik user
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+)
+
+func parseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleAdmin, RoleUser:
+		return r, nil
+	}
+	return "", fmt.Errorf("invalid role %q (want admin or user)", s)
+}
+
+// action = jenis operasi yang dicek authorize
+type action string
+
+const (
+	actionRead    action = "read"
+	actionWrite   action = "modify users" // create, update, patch, restore
+	actionDelete  action = "delete users" // DELETE /users/{id}
+	actionBulk    action = "run batches"  // POST /batch
+	actionSetRole action = "set roles"    // mengisi field role user
+)
+
+// caller = pemanggil request menurut auth: role dan user pemilik API key
+// (user=<id>, "" kalau key layanan atau tanpa key)
+type caller struct {
+	role Role
+	user UserID
+}
+
+// authorize = satu-satunya aturan role. Role kosong = request tanpa API key
+// (auth mati, atau baca anonim yang memang diizinkan requireAPIKey), jadi
+// tidak dibatasi di sini. admin boleh semua, user hanya membaca dan
+// menghapus dirinya sendiri. target = user yang dikenai aksi, "" kalau tidak ada.
+func authorize(c caller, a action, target UserID) error {
+	switch {
+	case c.role == "", c.role == RoleAdmin, a == actionRead:
+		return nil
+	case a == actionDelete && c.user != "" && c.user == target:
+		return nil
+	}
+	return &AppError{
+		Status:  http.StatusForbidden,
+		Code:    "forbidden",
+		Message: fmt.Sprintf("role %s may not %s", c.role, a),
+	}
+}
+
+type roleKey struct{}
+
+// roleFromContext = role pemanggil request ini, "" kalau tanpa key
+func roleFromContext(ctx context.Context) Role {
+	role, _ := ctx.Value(roleKey{}).(Role)
+	return role
+}
+
+// authorizeRequest = authorize untuk pemanggil request r, tanpa target
+func authorizeRequest(r *http.Request, a action) error {
+	return authorizeTarget(r, a, "")
+}
+
+// authorizeTarget = authorize untuk pemanggil request r terhadap user target
+func authorizeTarget(r *http.Request, a action, target UserID) error {
+	ctx := r.Context()
+	return authorize(caller{role: roleFromContext(ctx), user: callerUserID(ctx)}, a, target)
+}
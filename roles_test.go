@@ -0,0 +1,85 @@
+// File: /roles_test.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller caller
+		action action
+		target UserID
+		allow  bool
+	}{
+		{"admin deletes user", caller{role: RoleAdmin}, actionDelete, "2", true},
+		{"user deletes self", caller{role: RoleUser, user: "2"}, actionDelete, "2", true},
+		{"user deletes other", caller{role: RoleUser, user: "2"}, actionDelete, "3", false},
+		// key role=user tanpa user= tidak punya "diri sendiri"
+		{"unbound user deletes", caller{role: RoleUser}, actionDelete, "", false},
+		{"user reads", caller{role: RoleUser, user: "2"}, actionRead, "3", true},
+		{"user updates self", caller{role: RoleUser, user: "2"}, actionWrite, "2", false},
+		{"user sets role", caller{role: RoleUser, user: "2"}, actionSetRole, "", false},
+		{"user runs batch", caller{role: RoleUser}, actionBulk, "", false},
+		{"no key", caller{}, actionDelete, "3", true},
+	}
+	for _, tt := range tests {
+		err := authorize(tt.caller, tt.action, tt.target)
+		if tt.allow {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		var appErr *AppError
+		if !errors.As(err, &appErr) || appErr.Status != http.StatusForbidden || appErr.Code != "forbidden" {
+			t.Errorf("%s: error %v, want 403 forbidden", tt.name, err)
+		}
+	}
+}
+
+// TestRoleFromBoundUser: key user=<id> memakai role user di store, jadi
+// promote/demote langsung berlaku walau key menulis role=admin
+func TestRoleFromBoundUser(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		c.APIKeys = []string{"admin-key ops", "bob-key bob role=admin user=2", "ghost-key ghost user=99"}
+	}))
+	admin := []string{"X-API-Key", "admin-key"}
+	bob := []string{"X-API-Key", "bob-key"}
+	for _, name := range []string{"Alice", "Bob", "Carol", "Dave"} {
+		doRequest(t, ts, "POST", "/users", `{"name":"`+name+`"}`, admin...)
+	}
+
+	// role user: tidak boleh menghapus user lain, boleh menghapus diri sendiri nanti
+	res, body := doRequest(t, ts, "DELETE", "/users/1", "", bob...)
+	if res.StatusCode != http.StatusForbidden || decodeBody[errorResponse](t, body).Error != "forbidden" {
+		t.Fatalf("user deletes other: status %d: %s", res.StatusCode, body)
+	}
+
+	// promote: sekarang admin
+	if res, body := doRequest(t, ts, "PATCH", "/users/2", `{"role":"admin"}`, admin...); res.StatusCode != http.StatusOK {
+		t.Fatalf("promote: status %d: %s", res.StatusCode, body)
+	}
+	if res, body := doRequest(t, ts, "DELETE", "/users/1", "", bob...); res.StatusCode != http.StatusOK {
+		t.Fatalf("admin deletes user: status %d: %s", res.StatusCode, body)
+	}
+
+	// demote: kembali hanya membaca
+	if res, body := doRequest(t, ts, "PATCH", "/users/2", `{"role":"user"}`, admin...); res.StatusCode != http.StatusOK {
+		t.Fatalf("demote: status %d: %s", res.StatusCode, body)
+	}
+	if res, _ := doRequest(t, ts, "DELETE", "/users/3", "", bob...); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("demoted user deletes other: status %d, want 403", res.StatusCode)
+	}
+	if res, body := doRequest(t, ts, "DELETE", "/users/2", "", bob...); res.StatusCode != http.StatusOK {
+		t.Fatalf("user deletes self: status %d: %s", res.StatusCode, body)
+	}
+
+	// user key yang usernya tidak ada = role user
+	if res, _ := doRequest(t, ts, "DELETE", "/users/4", "", "X-API-Key", "ghost-key"); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("key of a missing user: status %d, want 403", res.StatusCode)
+	}
+}
@@ -0,0 +1,162 @@
+// File: /router.go
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+	"time"
+)
+
+// routerDeps = semua yang dibutuhkan newRouter, diisi NewServer
+type routerDeps struct {
+	store    *UserStore
+	users    *UserService
+	audit    *AuditLog
+	fixtures *FixturesHandler
+	metrics  *Metrics
+	// allowReset = daftarkan POST /admin/reset
+	allowReset bool
+	// pprof = daftarkan /debug/pprof/
+	pprof bool
+	// schemaValidation = POST /users dicek terhadap schema openapi.json
+	schemaValidation bool
+	// adminIPs = filter IP untuk /admin/* dan /debug/pprof/ (nil = terbuka)
+	adminIPs *ipFilter
+	// adminAuth = Basic auth route admin; nil = /admin/* dan pprof tidak dipasang
+	adminAuth *adminAuth
+	// basePath = prefix untuk _links
+	basePath string
+	// idempotencyTTL = -idempotency-ttl untuk POST /users
+	idempotencyTTL time.Duration
+	// writeTimeout = -write-timeout, untuk deadline per chunk export NDJSON
+	writeTimeout time.Duration
+	// startedAt = waktu server dibuat, untuk uptime di /status
+	startedAt time.Time
+	// now = jam server (WithServerClock) untuk /time, uptime /status dan
+	// kedaluwarsa Idempotency-Key
+	now func() time.Time
+	// checks = hasil probe untuk /health
+	checks map[string]probeResult
+}
+
+// newRouter mendaftarkan semua route. Hasilnya belum dibungkus middleware
+// (lihat Server.buildChain), jadi bisa dipakai langsung dengan httptest.NewServer.
+func newRouter(d routerDeps) (http.Handler, error) {
+	docsHandler, err := NewDocsHandler()
+	if err != nil {
+		return nil, err
+	}
+	userHandler := NewUsersHandler(d.users, d.basePath)
+	userHandler.idempotency = newIdempotencyCache(d.idempotencyTTL, d.now)
+	userHandler.writeTimeout = d.writeTimeout
+	if d.schemaValidation {
+		v, err := newSchemaBodyValidator(openAPISpec, "CreateUserRequest")
+		if err != nil {
+			return nil, err
+		}
+		userHandler.createValidator = v
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/users", userHandler.HandleUsers)
+	mux.HandleFunc("/users/", userHandler.HandleUserRoutes)
+	// path persis menang atas prefix "/users/", jadi "recent"/"exists" tidak dibaca sebagai id
+	mux.HandleFunc("/users/recent", userHandler.HandleRecentUsers)
+	mux.HandleFunc("/users/exists", userHandler.HandleNameExists)
+	mux.HandleFunc("/users/import", userHandler.HandleImportUsers)
+
+	// route admin: filter IP dulu, lalu Basic auth
+	admin := func(h http.HandlerFunc) http.Handler {
+		return d.adminIPs.wrap(d.adminAuth.wrap(h))
+	}
+	if d.adminAuth != nil {
+		mux.Handle("/admin/fixtures", admin(d.fixtures.HandleFixtures))
+		if d.allowReset {
+			mux.Handle("/admin/reset", admin(d.fixtures.HandleReset))
+		}
+	}
+	mux.HandleFunc("/audit", NewAuditHandler(d.audit).HandleAudit)
+	mux.HandleFunc("/metrics", d.metrics.HandleMetrics)
+
+	mux.HandleFunc("/openapi.json", docsHandler.HandleOpenAPI)
+	mux.HandleFunc("/docs", docsHandler.HandleDocs)
+	mux.HandleFunc("/examples", docsHandler.HandleExamples)
+	mux.HandleFunc("/examples/", docsHandler.HandleExamples)
+
+	registerBasicRoutes(mux, d.checks, d.now)
+	mux.HandleFunc("/status", statusHandler(d.store, d.startedAt, d.now))
+
+	// /batch memanggil mux langsung, request lain lewat gate.
+	// Panic di satu operasi jadi 500 untuk operasi itu, jadi batch di-rollback.
+	gate := &sync.RWMutex{}
+	batchHandler := NewBatchHandler(d.store, d.audit, recoverPanics(mux), gate)
+
+	root := http.NewServeMux()
+	root.HandleFunc("/batch", batchHandler.HandleBatch)
+	root.Handle("/", withGate(gate, mux))
+	if d.pprof && d.adminAuth != nil {
+		// di root, bukan mux: profile 30 detik tidak boleh menahan gate /batch
+		registerPprof(root, admin)
+	}
+	return headAsGet(answerOptions(resolveCaller(d.users, root))), nil
+}
+
+// resolveCaller: request dari API key milik user (user=<id>) memakai role
+// user itu saat ini, bukan role= di key, jadi promote/demote langsung
+// berlaku. User yang tidak ada atau terhapus = RoleUser. Request juga dicatat
+// sebagai aktivitas user sebelum handler jalan, jadi response sudah memuat
+// lastActiveAt terbaru. Di luar mux supaya satu /batch = satu aktivitas.
+func resolveCaller(users *UserService, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := callerUserID(r.Context()); id != "" {
+			role := RoleUser
+			if u, err := users.GetUser(r.Context(), id, false); err == nil {
+				role = u.Role
+			}
+			users.RecordActivity(id)
+			r = r.WithContext(context.WithValue(r.Context(), roleKey{}, role))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GET /status = /health plus waktu start, uptime, versi build dan jumlah user
+func statusHandler(store *UserStore, startedAt time.Time, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireMethods(w, r, http.MethodGet) {
+			return
+		}
+
+		uptime := now().Sub(startedAt).Seconds()
+		writeData(w, http.StatusOK, apiResponse{
+			"startedAt": startedAt.UTC().Format(time.RFC3339),
+			"uptime":    math.Round(uptime*1000) / 1000,
+			"version":   version,
+			"users":     store.Count(),
+		}, nil)
+	}
+}
+
+// registerPprof memasang handler net/http/pprof lewat admin (filter IP + auth).
+// Tanpa -pprof path ini jatuh ke catch-all "/" dan dijawab 404 JSON seperti path lain.
+func registerPprof(mux *http.ServeMux, admin func(http.HandlerFunc) http.Handler) {
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.Handle(pattern, admin(h))
+	}
+	handle("/debug/pprof/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == pprofPrefix {
+			setRoutePattern(r, pprofPrefix)
+		} else {
+			setRoutePattern(r, pprofPrefix+"{profile}")
+		}
+		pprof.Index(w, r)
+	})
+	handle("/debug/pprof/cmdline", pprof.Cmdline)
+	handle("/debug/pprof/profile", pprof.Profile)
+	handle("/debug/pprof/symbol", pprof.Symbol)
+	handle("/debug/pprof/trace", pprof.Trace)
+}
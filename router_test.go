@@ -0,0 +1,182 @@
+// File: /router_test.go
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newRouterServer = httptest.Server langsung di atas newRouter, tanpa
+// middleware Server.buildChain
+func newRouterServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	clock := newFakeClock()
+	svc, store := newTestService(clock)
+	root, err := newRouter(routerDeps{
+		store:     store,
+		users:     svc,
+		audit:     svc.audit,
+		fixtures:  NewFixturesHandler(store),
+		metrics:   NewMetrics(),
+		startedAt: clock.Now(),
+		now:       clock.Now,
+	})
+	if err != nil {
+		t.Fatalf("newRouter: %v", err)
+	}
+	ts := httptest.NewServer(root)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRouterCreateGetListDelete(t *testing.T) {
+	ts := newRouterServer(t)
+
+	res, body := doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", res.StatusCode, body)
+	}
+	created := decodeBody[userEnvelope](t, body).Data
+	if created.ID != "1" || created.Name != "Ada" || !created.CreatedAt.Equal(testEpoch) {
+		t.Fatalf("create: %+v", created)
+	}
+	if res, body = doRequest(t, ts, "POST", "/users", `{"name":"Grace"}`); res.StatusCode != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", res.StatusCode, body)
+	}
+
+	res, body = doRequest(t, ts, "GET", "/users/1", "")
+	if res.StatusCode != http.StatusOK || decodeBody[userEnvelope](t, body).Data.Name != "Ada" {
+		t.Fatalf("get: status %d: %s", res.StatusCode, body)
+	}
+
+	res, body = doRequest(t, ts, "GET", "/users?sort=name", "")
+	page := decodeBody[userListEnvelope](t, body)
+	if res.StatusCode != http.StatusOK || page.Meta.Total != 2 || len(page.Data) != 2 || page.Data[0].Name != "Ada" || page.Data[1].Name != "Grace" {
+		t.Fatalf("list: status %d: %s", res.StatusCode, body)
+	}
+
+	if res, body = doRequest(t, ts, "DELETE", "/users/1", ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("delete: status %d: %s", res.StatusCode, body)
+	}
+	if res, _ = doRequest(t, ts, "GET", "/users/1", ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("get after delete: status %d", res.StatusCode)
+	}
+	_, body = doRequest(t, ts, "GET", "/users", "")
+	if page = decodeBody[userListEnvelope](t, body); page.Meta.Total != 1 || page.Data[0].ID != "2" {
+		t.Fatalf("list after delete: %s", body)
+	}
+}
+
+// tanpa adminAuth route admin tidak didaftarkan sama sekali
+func TestRouterWithoutAdminAuth(t *testing.T) {
+	ts := newRouterServer(t)
+	for _, path := range []string{"/admin/reset", "/admin/fixtures", "/debug/pprof/"} {
+		if res, _ := doRequest(t, ts, "GET", path, ""); res.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: status %d, want 404", path, res.StatusCode)
+		}
+	}
+	// route biasa tidak bergantung pada deps opsional
+	if res, body := doRequest(t, ts, "GET", "/time", ""); res.StatusCode != http.StatusOK {
+		t.Errorf("/time: status %d: %s", res.StatusCode, body)
+	}
+}
+
+func TestPprofFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		mutate     func(*Config)
+		header     []string
+		wantStatus int
+	}{
+		{"enabled", func(c *Config) { withAdmin(c); c.Pprof = true }, adminHeader(), http.StatusOK},
+		{"enabled without credentials", func(c *Config) { withAdmin(c); c.Pprof = true }, nil, http.StatusUnauthorized},
+		{"disabled", withAdmin, adminHeader(), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, testConfig(tt.mutate))
+			for _, path := range []string{"/debug/pprof/", "/debug/pprof/cmdline"} {
+				res, body := doRequest(t, ts, "GET", path, "", tt.header...)
+				if res.StatusCode != tt.wantStatus {
+					t.Fatalf("GET %s: status %d, want %d: %.200s", path, res.StatusCode, tt.wantStatus, body)
+				}
+				// 404 = catch-all JSON biasa, bukan halaman pprof
+				if tt.wantStatus == http.StatusNotFound && decodeBody[errorResponse](t, body).Error != "not_found" {
+					t.Fatalf("GET %s: %s", path, body)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusUptimeAndUsers(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(nil), WithServerClock(clock.Now))
+
+	type statusEnvelope struct {
+		Data struct {
+			StartedAt string  `json:"startedAt"`
+			Uptime    float64 `json:"uptime"`
+			Version   string  `json:"version"`
+			Users     int     `json:"users"`
+		} `json:"data"`
+	}
+	status := func() statusEnvelope {
+		t.Helper()
+		res, body := doRequest(t, ts, "GET", "/status", "")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("status: %d: %s", res.StatusCode, body)
+		}
+		return decodeBody[statusEnvelope](t, body)
+	}
+
+	got := status().Data
+	if got.StartedAt != "2024-01-02T03:04:05Z" || got.Uptime != 0 || got.Users != 0 || got.Version != version {
+		t.Fatalf("initial status = %+v", got)
+	}
+
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+	doRequest(t, ts, "POST", "/users", `{"name":"Grace"}`)
+	clock.Advance(90*time.Second + 1500*time.Microsecond)
+	got = status().Data
+	// uptime dibulatkan ke milidetik, startedAt tidak ikut berubah
+	if got.StartedAt != "2024-01-02T03:04:05Z" || got.Uptime != 90.002 || got.Users != 2 {
+		t.Fatalf("status after 90s = %+v", got)
+	}
+
+	doRequest(t, ts, "DELETE", "/users/1", "")
+	clock.Advance(time.Hour)
+	if got = status().Data; got.Uptime != 3690.002 || got.Users != 1 {
+		t.Fatalf("status after delete = %+v", got)
+	}
+}
+
+// catch-all "/": path tanpa route = envelope error JSON 404, bukan teks net/http
+func TestUnknownPathJSON404(t *testing.T) {
+	servers := map[string]*httptest.Server{
+		"router": newRouterServer(t),
+		"server": newTestServer(t, testConfig(nil)),
+	}
+	tests := []struct{ server, method, path, wantPath string }{
+		{"router", "GET", "/nope", "/nope"},
+		{"router", "POST", "/users/1/nope", "/users/1/nope"},
+		{"server", "GET", "/nope", "/nope"},
+		{"server", "DELETE", "/a/b/c", "/a/b/c"},
+		{"server", "GET", "/users/1/nope", "/users/1/nope"},
+		{"server", "GET", "/nope?x=1", "/nope"},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, servers[tt.server], tt.method, tt.path, "")
+		if res.StatusCode != http.StatusNotFound || res.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("%s %s %s: status %d, type %q: %s", tt.server, tt.method, tt.path, res.StatusCode, res.Header.Get("Content-Type"), body)
+			continue
+		}
+		got := decodeBody[errorResponse](t, body)
+		details, _ := got.Details.(map[string]any)
+		if got.Error != "not_found" || got.Message != "resource not found" || details["path"] != tt.wantPath {
+			t.Errorf("%s %s %s: body %s", tt.server, tt.method, tt.path, body)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// File: /routes.go
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// routeSpec = satu route publik: template path (seperti di openapi.json) dan
+// method yang didukung. HEAD tidak ditulis: selalu ikut GET (lihat headAsGet).
+type routeSpec struct {
+	pattern string
+	methods []string
+	// query = contoh query untuk daftar route di GET /
+	query string
+}
+
+// routeTable = sumber tunggal method per route: dipakai OPTIONS (answerOptions),
+// 405 + Allow di handler (lewat routeMethods) dan daftar route di GET /.
+// Route admin / pprof tidak di sini: dipasang kondisional dan di belakang auth,
+// handler-nya sendiri yang menjawab OPTIONS.
+var routeTable = []routeSpec{
+	{pattern: "/", methods: []string{http.MethodGet}},
+	{pattern: "/health", methods: []string{http.MethodGet}},
+	{pattern: "/status", methods: []string{http.MethodGet}},
+	{pattern: "/time", methods: []string{http.MethodGet}},
+	{pattern: "/echo", methods: []string{http.MethodGet}, query: "?name="},
+	{pattern: "/sum", methods: []string{http.MethodPost}},
+	{pattern: "/mul", methods: []string{http.MethodPost}},
+	{pattern: "/users", methods: []string{http.MethodGet, http.MethodPost}},
+	{pattern: "/users/recent", methods: []string{http.MethodGet}, query: "?since="},
+	{pattern: "/users/exists", methods: []string{http.MethodGet}, query: "?name="},
+	{pattern: "/users/import", methods: []string{http.MethodPost}},
+	{pattern: "/users/{id}", methods: []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete}},
+	{pattern: "/users/{id}/profile", methods: []string{http.MethodGet, http.MethodPatch}},
+	{pattern: "/users/{id}/restore", methods: []string{http.MethodPost}},
+	{pattern: "/users/{id}/password", methods: []string{http.MethodPost}},
+	{pattern: "/users/{id}/orders/{orderId}", methods: []string{http.MethodGet}},
+	{pattern: "/batch", methods: []string{http.MethodPost}},
+	{pattern: "/audit", methods: []string{http.MethodGet}},
+	{pattern: "/metrics", methods: []string{http.MethodGet}},
+	{pattern: "/openapi.json", methods: []string{http.MethodGet}},
+	{pattern: "/docs", methods: []string{http.MethodGet}},
+	{pattern: "/examples", methods: []string{http.MethodGet}},
+	{pattern: "/examples/{route-id}", methods: []string{http.MethodGet}},
+}
+
+// routeMethods = method route pattern di routeTable. Dipakai untuk variabel
+// package, jadi pattern yang salah ketik langsung panic saat start.
+func routeMethods(pattern string) []string {
+	for _, rt := range routeTable {
+		if rt.pattern == pattern {
+			return rt.methods
+		}
+	}
+	panic("routeMethods: unknown route " + pattern)
+}
+
+// matchRoute mencari route untuk path. Segmen literal menang atas {param},
+// jadi /users/recent tidak dibaca sebagai /users/{id}.
+func matchRoute(path string) (routeSpec, bool) {
+	segs := strings.Split(path, "/")
+	best, bestParams := -1, 0
+	for i, rt := range routeTable {
+		tpl := strings.Split(rt.pattern, "/")
+		if len(tpl) != len(segs) {
+			continue
+		}
+		params := 0
+		ok := true
+		for j, t := range tpl {
+			if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+				if segs[j] == "" {
+					ok = false
+					break
+				}
+				params++
+			} else if t != segs[j] {
+				ok = false
+				break
+			}
+		}
+		if ok && (best < 0 || params < bestParams) {
+			best, bestParams = i, params
+		}
+	}
+	if best < 0 {
+		return routeSpec{}, false
+	}
+	return routeTable[best], true
+}
+
+// allowList = isi header Allow untuk methods: method route dulu, lalu HEAD
+// (kalau ada GET) dan OPTIONS di belakang, mis. "GET, POST, HEAD, OPTIONS"
+func allowList(methods []string) []string {
+	allowed := slices.Clone(methods)
+	if slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	return allowed
+}
+
+// answerOptions menjawab OPTIONS untuk route di routeTable dengan 204 + Allow
+// sebelum sampai ke handler (tanpa cek keberadaan resource). Path lain
+// diteruskan: route admin menjawab sendiri, path tak dikenal tetap 404 JSON.
+func answerOptions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rt, ok := matchRoute(r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		setRoutePattern(r, rt.pattern)
+		w.Header().Set("Allow", strings.Join(allowList(rt.methods), ", "))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// routeIndex = daftar route untuk GET /, mis. "GET, POST /users"
+func routeIndex() []string {
+	out := make([]string, 0, len(routeTable))
+	for _, rt := range routeTable {
+		if rt.pattern == "/" {
+			continue
+		}
+		out = append(out, strings.Join(rt.methods, ", ")+" "+rt.pattern+rt.query)
+	}
+	return out
+}
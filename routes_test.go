@@ -0,0 +1,89 @@
+// File: /routes_test.go
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAllowList(t *testing.T) {
+	tests := []struct {
+		methods []string
+		want    string
+	}{
+		{[]string{"GET"}, "GET, HEAD, OPTIONS"},
+		{[]string{"POST"}, "POST, OPTIONS"},
+		{[]string{"GET", "POST"}, "GET, POST, HEAD, OPTIONS"},
+		{[]string{"GET", "PUT", "PATCH", "DELETE"}, "GET, PUT, PATCH, DELETE, HEAD, OPTIONS"},
+		{[]string{"GET", "HEAD", "OPTIONS"}, "GET, HEAD, OPTIONS"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(allowList(tt.methods), ", "); got != tt.want {
+			t.Errorf("allowList(%v) = %q, want %q", tt.methods, got, tt.want)
+		}
+	}
+}
+
+// concretePath mengisi {param} pattern routeTable supaya bisa di-request
+func concretePath(pattern string) string {
+	return strings.NewReplacer("{id}", "1", "{orderId}", "7", "{route-id}", "users.get").Replace(pattern)
+}
+
+// OPTIONS = 204 + Allow dari routeTable; method lain di route yang sama
+// dijawab 405 dengan Allow yang persis sama
+func TestOptionsAndMethodNotAllowedAllow(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+	// nilai persis untuk resource utama, sisanya dihitung dari routeTable
+	want := map[string]string{
+		"/users":      "GET, POST, HEAD, OPTIONS",
+		"/users/{id}": "GET, PUT, PATCH, DELETE, HEAD, OPTIONS",
+		"/sum":        "POST, OPTIONS",
+		"/health":     "GET, HEAD, OPTIONS",
+	}
+	for _, rt := range routeTable {
+		allow := strings.Join(allowList(rt.methods), ", ")
+		if w, ok := want[rt.pattern]; ok && allow != w {
+			t.Errorf("%s: allowList %q, want %q", rt.pattern, allow, w)
+		}
+		path := concretePath(rt.pattern)
+
+		res, body := doRequest(t, ts, "OPTIONS", path, "")
+		if res.StatusCode != http.StatusNoContent || body != "" {
+			t.Errorf("OPTIONS %s: status %d, body %q", path, res.StatusCode, body)
+		}
+		if got := res.Header.Get("Allow"); got != allow {
+			t.Errorf("OPTIONS %s: Allow %q, want %q", path, got, allow)
+		}
+
+		// method pertama yang tidak didukung route ini
+		var method string
+		for _, m := range []string{"PATCH", "PUT", "DELETE", "POST"} {
+			if !slices.Contains(rt.methods, m) {
+				method = m
+				break
+			}
+		}
+		res, body = doRequest(t, ts, method, path, "")
+		if res.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: status %d, want 405: %s", method, path, res.StatusCode, body)
+			continue
+		}
+		if got := res.Header.Get("Allow"); got != allow {
+			t.Errorf("%s %s: Allow %q, want %q", method, path, got, allow)
+		}
+	}
+
+	// OPTIONS tidak mengecek keberadaan resource
+	if res, _ := doRequest(t, ts, "OPTIONS", "/users/99", ""); res.StatusCode != http.StatusNoContent || res.Header.Get("Allow") != want["/users/{id}"] {
+		t.Errorf("OPTIONS /users/99: status %d, Allow %q", res.StatusCode, res.Header.Get("Allow"))
+	}
+	// path tak dikenal tetap 404 JSON, tanpa Allow
+	res, body := doRequest(t, ts, "OPTIONS", "/nope", "")
+	if res.StatusCode != http.StatusNotFound || res.Header.Get("Allow") != "" || decodeBody[errorResponse](t, body).Error != "not_found" {
+		t.Errorf("OPTIONS /nope: status %d, Allow %q: %s", res.StatusCode, res.Header.Get("Allow"), body)
+	}
+}
@@ -0,0 +1,86 @@
+// File: /security_headers_test.go
+package main
+
+import "testing"
+
+// TestSecurityHeadersFullSet: header keamanan persis pada request biasa,
+// HSTS hanya kalau TLS aktif
+func TestSecurityHeadersFullSet(t *testing.T) {
+	base := map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "no-referrer",
+		"Content-Security-Policy":   "default-src 'none'; frame-ancestors 'none'",
+		"Strict-Transport-Security": "",
+		"Server":                    "",
+	}
+	with := func(changes map[string]string) map[string]string {
+		out := map[string]string{}
+		for k, v := range base {
+			out[k] = v
+		}
+		for k, v := range changes {
+			out[k] = v
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		want   map[string]string
+	}{
+		{"defaults over plain HTTP", nil, base},
+		{"tls", func(c *Config) { c.TLSCert, c.TLSKey = "cert.pem", "key.pem" }, with(map[string]string{
+			"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+		})},
+		{"autocert", func(c *Config) { c.AutocertDomain, c.AutocertCache = "example.com", t.TempDir() }, with(map[string]string{
+			"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+		})},
+		{"override and disable", func(c *Config) {
+			c.SecurityHeaders = []string{"Referrer-Policy: same-origin", "X-Frame-Options:", "Permissions-Policy: camera=()"}
+			c.ServerHeader = "users-api"
+		}, with(map[string]string{
+			"Referrer-Policy":    "same-origin",
+			"X-Frame-Options":    "",
+			"Permissions-Policy": "camera=()",
+			"Server":             "users-api",
+		})},
+		{"disabled", func(c *Config) {
+			c.SecurityHeadersEnabled = false
+			c.TLSCert, c.TLSKey = "cert.pem", "key.pem"
+		}, map[string]string{
+			"X-Content-Type-Options":    "",
+			"X-Frame-Options":           "",
+			"Referrer-Policy":           "",
+			"Content-Security-Policy":   "",
+			"Strict-Transport-Security": "",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, testConfig(tt.mutate))
+			// error dari middleware dan response handler sama-sama membawa header
+			for _, path := range []string{"/users", "/nope"} {
+				res, _ := doRequest(t, ts, "GET", path, "")
+				for name, want := range tt.want {
+					if got := res.Header.Get(name); got != want {
+						t.Errorf("GET %s: %s = %q, want %q", path, name, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// /docs mengganti CSP default dengan CSP Swagger UI, header lain tetap
+func TestSecurityHeadersDocsCSP(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	res, _ := doRequest(t, ts, "GET", "/docs", "")
+	if got := res.Header.Get("Content-Security-Policy"); got != docsCSP {
+		t.Errorf("/docs CSP = %q, want %q", got, docsCSP)
+	}
+	if res.Header.Get("X-Frame-Options") != "DENY" || res.Header.Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("/docs headers = %v", res.Header)
+	}
+}
@@ -0,0 +1,48 @@
+// File: /seed.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// seedUsers membuat n user "user-1".."user-n" lalu user dari file (array
+// JSON [{"name": "..."}]). Semua lewat CreateUser supaya validasi sama
+// dengan POST /users. Mengembalikan jumlah user yang dibuat.
+func seedUsers(ctx context.Context, svc *UserService, n int, file string) (int, error) {
+	created := 0
+	for i := 1; i <= n; i++ {
+		if _, err := svc.CreateUser(ctx, "user-"+strconv.Itoa(i), RoleUser, ""); err != nil {
+			return created, fmt.Errorf("seed user %d: %w", i, err)
+		}
+		created++
+	}
+
+	if file == "" {
+		return created, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return created, fmt.Errorf("seed file: %w", err)
+	}
+
+	var users []createUserRequest
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&users); err != nil {
+		return created, fmt.Errorf("seed file %s: want a JSON array of {\"name\": ...}: %w", file, err)
+	}
+
+	for i, u := range users {
+		if _, err := svc.CreateUser(ctx, u.Name, RoleUser, ""); err != nil {
+			return created, fmt.Errorf("seed file %s: entry %d: %w", file, i, err)
+		}
+		created++
+	}
+	return created, nil
+}
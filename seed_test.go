@@ -0,0 +1,85 @@
+// File: /seed_test.go
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func userNames(t *testing.T, body string) []string {
+	t.Helper()
+	var names []string
+	for _, u := range decodeBody[userListEnvelope](t, body).Data {
+		names = append(names, u.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestSeedFlag(t *testing.T) {
+	logs := captureLog(t, levelInfo)
+	cfg, err := LoadConfig([]string{"-seed", "3", "-bcrypt-cost", "4"}, envMap(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := newTestServer(t, cfg)
+
+	_, body := doRequest(t, ts, "GET", "/users", "")
+	if got, want := userNames(t, body), []string{"user-1", "user-2", "user-3"}; !slices.Equal(got, want) {
+		t.Fatalf("users %v, want %v", got, want)
+	}
+	for _, u := range decodeBody[userListEnvelope](t, body).Data {
+		if u.Role != RoleUser {
+			t.Errorf("%s has role %q", u.Name, u.Role)
+		}
+	}
+	if findLog(logs.Records(t), "seeded 3 users") == nil {
+		t.Errorf("no seeded log line in %v", logs.Records(t))
+	}
+}
+
+func TestSeedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"Ada"},{"name":" Grace "}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		c.Seed = 1
+		c.SeedFile = path
+	}))
+
+	_, body := doRequest(t, ts, "GET", "/users", "")
+	if got, want := userNames(t, body), []string{"Ada", "Grace", "user-1"}; !slices.Equal(got, want) {
+		t.Fatalf("users %v, want %v", got, want)
+	}
+}
+
+// seed lewat CreateUser: entri tidak valid menggagalkan start
+func TestSeedFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, content, want string
+	}{
+		{"blank.json", `[{"name":"Ada"},{"name":"  "}]`, "entry 1: validation_failed"},
+		{"object.json", `{"name":"Ada"}`, "want a JSON array"},
+		{"unknown.json", `[{"name":"Ada","admin":true}]`, `unknown field "admin"`},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := NewServer(testConfig(func(c *Config) { c.SeedFile = path }))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	_, err := NewServer(testConfig(func(c *Config) { c.SeedFile = filepath.Join(dir, "missing.json") }))
+	if err == nil || !strings.Contains(err.Error(), "seed file") {
+		t.Errorf("missing file: error %v", err)
+	}
+}
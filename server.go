@@ -0,0 +1,372 @@
+// File: /server.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Server merangkai store, service, handler dan middleware.
+// Tidak memanggil ListenAndServe supaya bisa dipakai dengan httptest.
+type Server struct {
+	cfg     Config
+	store   *UserStore
+	handler http.Handler
+
+	// root = mux tanpa middleware; chain = middleware + root untuk live config.
+	// live/chain diganti atomik oleh Reload (SIGHUP).
+	root     http.Handler
+	live     atomic.Pointer[Config]
+	chain    atomic.Pointer[http.Handler]
+	reloadMu sync.Mutex
+
+	// accessFile/access: access log per request (-access-log, -access-log-format),
+	// access nil = record debug di log aplikasi
+	accessFile *rotatingFile
+	access     *accessLogger
+
+	// metrics tetap sama antar Reload, histogram tidak di-reset
+	metrics *Metrics
+	// apiKeys = key dari -api-key / -api-keys-file, nil = auth mati
+	apiKeys *apiKeySet
+	// inflight = semaphore -max-inflight (nil = tanpa batas), ganti ukuran perlu restart
+	inflight *inflightLimiter
+
+	// storeProbe = hasil probe saat start, status awal health check store
+	storeProbe probeResult
+
+	// now = sumber waktu store, audit log, /time dan /status (default time.Now)
+	now func() time.Time
+}
+
+// ServerOption mengubah setting opsional NewServer (bukan bagian Config
+// karena tidak bisa diisi dari flag / env)
+type ServerOption func(*Server)
+
+// WithServerClock mengganti sumber waktu server, mis. jam tetap di test
+// kontrak supaya timestamp di response selalu sama
+func WithServerClock(now func() time.Time) ServerOption {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+func NewServer(cfg Config, opts ...ServerOption) (*Server, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Server{cfg: cfg, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.store = NewUserStore(cfg.IDMode, WithClock(s.now))
+
+	if cfg.AccessLog != "" {
+		f, err := openRotatingFile(cfg.AccessLog, cfg.AccessLogMaxBytes, cfg.AccessLogKeep)
+		if err != nil {
+			return nil, err
+		}
+		s.accessFile = f
+		s.access = newAccessLogger(cfg.AccessLogFormat, cfg.LogFormat, f)
+	} else if cfg.AccessLogFormat != "" {
+		s.access = newAccessLogger(cfg.AccessLogFormat, cfg.LogFormat, os.Stdout)
+	}
+
+	probe, err := probeStore(cfg.Store, s.store, cfg.StoreProbeTimeout, cfg.StoreProbeRetries)
+	if err != nil {
+		return nil, err
+	}
+	s.storeProbe = probe
+
+	auditLog := NewAuditLog(cfg.AuditLogSize)
+	auditLog.now = s.now
+	userService := NewUserService(s.store, cfg.SoftDelete, auditLog)
+	userService.passwordCost = cfg.BcryptCost
+	fixturesHandler := NewFixturesHandler(s.store)
+
+	if cfg.SeedFixture != "" {
+		if _, err := fixturesHandler.install(cfg.SeedFixture); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Seed > 0 || cfg.SeedFile != "" {
+		n, err := seedUsers(context.Background(), userService, cfg.Seed, cfg.SeedFile)
+		if err != nil {
+			return nil, err
+		}
+		logInfof("seeded %d users", n)
+	}
+
+	s.apiKeys, err = loadAPIKeys(cfg.APIKeys, cfg.APIKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	s.inflight = newInflightLimiter(cfg.MaxInflight, cfg.InflightQueueTimeout)
+	s.metrics = NewMetrics()
+	s.metrics.limiter = s.inflight
+	adminIPs, err := newIPFilter(cfg.AdminAllow, cfg.AdminDeny)
+	if err != nil {
+		return nil, err
+	}
+	adminAuth, err := newAdminAuth(cfg.AdminUser, cfg.AdminPasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	if adminAuth == nil {
+		logInfof("admin routes disabled: set -admin-user and -admin-password-hash to enable /admin/* and pprof")
+	}
+	root, err := newRouter(routerDeps{
+		store:            s.store,
+		users:            userService,
+		audit:            auditLog,
+		fixtures:         fixturesHandler,
+		metrics:          s.metrics,
+		allowReset:       cfg.AllowReset,
+		pprof:            cfg.Pprof,
+		schemaValidation: cfg.SchemaValidation,
+		adminIPs:         adminIPs,
+		adminAuth:        adminAuth,
+		basePath:         cfg.BasePath,
+		idempotencyTTL:   cfg.IdempotencyTTL,
+		writeTimeout:     cfg.WriteTimeout,
+		startedAt:        s.now(),
+		now:              s.now,
+		checks:           map[string]probeResult{"store": s.storeProbe},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.root = root
+	chain := s.buildChain(cfg)
+	s.live.Store(&cfg)
+	s.chain.Store(&chain)
+	s.handler = s.swapHandler()
+
+	return s, nil
+}
+
+// buildChain memasang middleware sesuai cfg di depan root. Urutan dari yang
+// paling dalam (dekat router) ke paling luar; alasan posisi ada di tiap layer.
+func (s *Server) buildChain(cfg Config) http.Handler {
+	// cfg sudah lolos Validate jadi error di sini tidak mungkin
+	headers, _ := securityHeaders(cfg)
+
+	// tepat di depan router: ServeMux mengisi r.Pattern di request miliknya
+	h := captureRoute(s.root)
+	// di dalam limitBody, jadi salinan body untuk log ikut dibatasi
+	h = logBodies(cfg.LogBodies, cfg.LogBodiesRedact, h)
+	// setelah auth dan X-Signature: keduanya (dan access log) melihat POST asli,
+	// request bertanda tangan dengan override ditolak
+	h = methodOverride(cfg.AllowMethodOverride, h)
+	// setelah X-Signature dan sertifikat client: request yang sudah
+	// terautentikasi lewat salah satunya tidak butuh X-API-Key
+	h = requireAPIKey(s.apiKeys, cfg.AuthReads, cfg.AuthOpenPaths, h)
+	// di dalam limitBody: HMAC dihitung dari body yang sudah dibatasi
+	h = verifySignature(cfg.SignatureSecret, h)
+	h = clientCertAuth(cfg.TLSClientCA != "", cfg.TLSClientOptional, h)
+	// sebelum semua layer yang membaca body
+	h = limitBody(cfg.MaxBodyBytes, h)
+	// sebelum auth: AuthOpenPaths dan route selalu melihat path kanonik
+	h = trimTrailingSlash(cfg.BasePath, h)
+	// sebelum redirect: path yang terlalu panjang tidak di-echo di Location
+	h = limitURISize(cfg.MaxPathBytes, cfg.MaxQueryBytes, h)
+	// deadline untuk semua handler di atas; request streaming dilewati
+	h = requestTimeout(cfg.RequestTimeout, h)
+	// di dalam limitInflight: slot dilepas lewat defer walau handler panic,
+	// dan 500 dari panic tetap tercatat requestLogger
+	h = recoverPanics(h)
+	// di dalam requestLogger: 503 dari limiter tetap masuk log dan metrics
+	h = limitInflight(s.inflight, h)
+	h = requestLogger(s.access, s.metrics, cfg.LogRawPath, cfg.SlowRequestThreshold, h)
+	// sebelum requestLogger (field log), setelah withRequestID (default = request ID)
+	h = withCorrelationID(h)
+	h = withRequestID(h)
+	// no-store default dipasang sebelum layer mana pun bisa menulis response
+	h = withCacheControl(cfg.CacheControl, cfg.CacheMaxAge, h)
+	// header keamanan di luar semua layer yang bisa menulis error
+	h = withSecurityHeaders(headers, cfg.ServerHeader, h)
+	// paling luar: error dari middleware mana pun ikut -pretty / ?pretty
+	return jsonStyle(cfg.Pretty, h)
+}
+
+// Close menutup resource milik server (access log), dipanggil setelah shutdown
+func (s *Server) Close() error {
+	if s.accessFile != nil {
+		return s.accessFile.Close()
+	}
+	return nil
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Addr: mode autocert selalu :443 karena Let's Encrypt hanya cek port standar
+func (s *Server) Addr() string {
+	if s.cfg.AutocertEnabled() {
+		return ":443"
+	}
+	return fmt.Sprintf(":%d", s.cfg.Port)
+}
+
+// HTTPServer membuat http.Server dengan timeout dari config
+// (default http.Server tidak punya timeout sama sekali).
+func (s *Server) HTTPServer() *http.Server {
+	return &http.Server{
+		Addr:              s.Addr(),
+		Handler:           s.handler,
+		ReadTimeout:       s.cfg.ReadTimeout,
+		ReadHeaderTimeout: s.cfg.ReadHeaderTimeout,
+		WriteTimeout:      s.cfg.WriteTimeout,
+		IdleTimeout:       s.cfg.IdleTimeout,
+	}
+}
+
+// route sederhana: index, health, time, echo, sum, mul
+// maxEchoNameLength dihitung dalam karakter (rune), bukan byte
+const maxEchoNameLength = 256
+
+// validateEchoName: name sudah di-trim dan tidak kosong
+func validateEchoName(name string) []string {
+	var details []string
+	if !utf8.ValidString(name) {
+		details = append(details, "name must be valid UTF-8")
+	} else if n := utf8.RuneCountInString(name); n > maxEchoNameLength {
+		details = append(details, fmt.Sprintf("name must be at most %d characters, got %d", maxEchoNameLength, n))
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		details = append(details, "name must not contain control characters")
+	}
+	return details
+}
+
+func registerBasicRoutes(mux *http.ServeMux, checks map[string]probeResult, now func() time.Time) {
+	// path lain yang tidak punya route: 404 JSON
+	mux.HandleFunc("/", notFound)
+
+	// GET / ("/{$}" = hanya path "/" persis)
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		setRoutePattern(r, "/")
+		if !requireMethods(w, r, http.MethodGet) {
+			return
+		}
+		writeData(w, http.StatusOK, apiResponse{
+			"service": "golang-beginner-rest",
+			"routes":  routeIndex(),
+		}, nil)
+	})
+
+	// GET /health
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if !requireMethods(w, r, http.MethodGet) {
+			return
+		}
+
+		writeData(w, http.StatusOK, apiResponse{
+			"status": "ok",
+			"checks": checks,
+		}, nil)
+	})
+
+	// GET /time
+	mux.HandleFunc("/time", func(w http.ResponseWriter, r *http.Request) {
+		if !requireMethods(w, r, http.MethodGet) {
+			return
+		}
+
+		writeData(w, http.StatusOK, apiResponse{
+			"time": now().UTC().Format(time.RFC3339),
+		}, nil)
+	})
+
+	// GET echo with query params
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		if !requireMethods(w, r, http.MethodGet) {
+			return
+		}
+
+		// r.URL.Query() diam-diam membuang pasangan yang rusak (mis. %zz)
+		query, err := url.ParseQuery(r.URL.RawQuery)
+		if err != nil {
+			writeAppError(w, r, validationError("invalid query string", []string{
+				"query string must be valid percent-encoding",
+			}))
+			return
+		}
+		qName := strings.TrimSpace(query.Get("name"))
+
+		if qName == "" {
+			writeAppError(w, r, validationError("missing required query parameter", []string{"name is required"}))
+			return
+		}
+		if details := validateEchoName(qName); len(details) > 0 {
+			writeAppError(w, r, validationError("invalid query parameter", details))
+			return
+		}
+
+		writeData(w, http.StatusOK, apiResponse{
+			"name": qName,
+		}, nil)
+
+	})
+
+	// POST /sum
+	mux.HandleFunc("/sum", func(w http.ResponseWriter, r *http.Request) {
+		if !requireMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		req, err := decodeJSON[operandsRequest](w, r)
+		if err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+
+		ops, _ := req.operands()
+		result, err := reduceOperands(ops, 0, addInt,
+			func(a, b float64) float64 { return a + b })
+		if err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+		writeData(w, http.StatusOK, apiResponse{
+			"result": result,
+		}, nil)
+	})
+
+	// POST /mul
+	mux.HandleFunc("/mul", func(w http.ResponseWriter, r *http.Request) {
+		if !requireMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		req, err := decodeJSON[operandsRequest](w, r)
+		if err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+
+		ops, _ := req.operands()
+		result, err := reduceOperands(ops, 1, mulInt,
+			func(a, b float64) float64 { return a * b })
+		if err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+		writeData(w, http.StatusOK, apiResponse{
+			"result": result,
+		}, nil)
+	})
+}
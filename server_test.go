@@ -0,0 +1,120 @@
+// File: /server_test.go
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestServerEndToEnd: create -> get -> delete lewat Handler() lengkap
+// (middleware + router), tanpa ListenAndServe
+func TestServerEndToEnd(t *testing.T) {
+	srv, err := NewServer(testConfig(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	res, body := doRequest(t, ts, "POST", "/users", `{"name":"Alice Doe"}`)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", res.StatusCode, body)
+	}
+	id := string(decodeBody[userEnvelope](t, body).Data.ID)
+
+	res, body = doRequest(t, ts, "GET", "/users/"+id, "")
+	if res.StatusCode != http.StatusOK || decodeBody[userEnvelope](t, body).Data.Name != "Alice Doe" {
+		t.Fatalf("get: status %d: %s", res.StatusCode, body)
+	}
+	// middleware ikut terpasang
+	if res.Header.Get("X-Request-Id") == "" || res.Header.Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("middleware headers missing: %v", res.Header)
+	}
+
+	res, body = doRequest(t, ts, "DELETE", "/users/"+id, "")
+	if res.StatusCode != http.StatusOK || !strings.Contains(body, `"deleted":true`) {
+		t.Fatalf("delete: status %d: %s", res.StatusCode, body)
+	}
+	if res, _ = doRequest(t, ts, "GET", "/users/"+id, ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("get after delete: status %d, want 404", res.StatusCode)
+	}
+}
+
+func TestNewServerRejectsInvalidConfig(t *testing.T) {
+	cfg := testConfig(func(c *Config) { c.MaxPathBytes = 0 })
+	if _, err := NewServer(cfg); err == nil || !strings.Contains(err.Error(), "max path bytes") {
+		t.Fatalf("NewServer error = %v", err)
+	}
+}
+
+func TestEchoValidation(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	atLimit := strings.Repeat("é", maxEchoNameLength) // batas dihitung per karakter, bukan byte
+
+	tests := []struct {
+		name, query string
+		wantStatus  int
+		wantName    string
+		wantDetails []any
+	}{
+		{"valid", "name=Budi", http.StatusOK, "Budi", nil},
+		// trim tetap jalan sebelum validasi
+		{"trimmed", "name=%20%20Budi%20Santoso%20", http.StatusOK, "Budi Santoso", nil},
+		{"at limit", "name=" + url.QueryEscape(atLimit), http.StatusOK, atLimit, nil},
+		{"too long", "name=" + url.QueryEscape(atLimit+"x"), http.StatusBadRequest, "",
+			[]any{"name must be at most 256 characters, got 257"}},
+		{"control char", "name=Bu%00di", http.StatusBadRequest, "",
+			[]any{"name must not contain control characters"}},
+		{"newline", "name=Budi%0AEvil", http.StatusBadRequest, "",
+			[]any{"name must not contain control characters"}},
+		{"invalid utf-8", "name=Bu%FFdi", http.StatusBadRequest, "",
+			[]any{"name must be valid UTF-8"}},
+		{"too long with control char", "name=" + url.QueryEscape(atLimit+"\t"+"x"), http.StatusBadRequest, "",
+			[]any{"name must be at most 256 characters, got 258", "name must not contain control characters"}},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", "/echo?"+tt.query, "")
+		if res.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, res.StatusCode, tt.wantStatus, body)
+			continue
+		}
+		if tt.wantStatus == http.StatusOK {
+			if got := decodeBody[struct {
+				Data struct {
+					Name string `json:"name"`
+				} `json:"data"`
+			}](t, body).Data.Name; got != tt.wantName {
+				t.Errorf("%s: name %q, want %q", tt.name, got, tt.wantName)
+			}
+			continue
+		}
+		got := decodeBody[errorResponse](t, body)
+		if got.Error != "validation_failed" || !reflect.DeepEqual(got.Details, tt.wantDetails) {
+			t.Errorf("%s: %s", tt.name, body)
+		}
+	}
+
+	// kosong setelah trim dan query yang tidak bisa di-decode: error biasa
+	// lengkap dengan message dan requestId
+	for _, q := range []struct{ query, message, detail string }{
+		{"", "missing required query parameter", "name is required"},
+		{"name=%20", "missing required query parameter", "name is required"},
+		{"name=%zz", "invalid query string", "query string must be valid percent-encoding"},
+		{"other=%zz&name=Ann", "invalid query string", "query string must be valid percent-encoding"},
+	} {
+		res, body := doRequest(t, ts, "GET", "/echo?"+q.query, "")
+		got := decodeBody[errorResponse](t, body)
+		if res.StatusCode != http.StatusBadRequest || got.Error != "validation_failed" || got.Message != q.message || got.RequestID == "" || got.CorrelationID == "" {
+			t.Errorf("%q: status %d: %s", q.query, res.StatusCode, body)
+			continue
+		}
+		if !reflect.DeepEqual(got.Details, []any{q.detail}) {
+			t.Errorf("%q: details %v, want %q", q.query, got.Details, q.detail)
+		}
+	}
+}
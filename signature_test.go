@@ -0,0 +1,71 @@
+// File: /signature_test.go
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testSignatureSecret = "s3cret"
+
+// sign = header X-Signature/X-Timestamp seperti yang dihitung client
+func sign(secret string, at time.Time, method, uri, body string) []string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "." + method + "." + uri + "." + body))
+	return []string{signatureHeader, "sha256=" + hex.EncodeToString(mac.Sum(nil)), timestampHeader, ts}
+}
+
+func TestVerifySignature(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		c.SignatureSecret = testSignatureSecret
+		c.APIKeys = []string{"admin-key ops"}
+	}))
+	doRequest(t, ts, "POST", "/users", `{"name":"Alice"}`, "X-API-Key", "admin-key")
+
+	create := `{"name":"Budi"}`
+	now := time.Now()
+	tests := []struct {
+		name         string
+		method, path string
+		body         string
+		header       []string
+		wantStatus   int
+		wantCode     string
+	}{
+		// body dibaca untuk HMAC lalu dipasang lagi, create tetap jalan
+		{"valid", "POST", "/users", create, sign(testSignatureSecret, now, "POST", "/users", create), http.StatusCreated, ""},
+		{"valid with query", "DELETE", "/users/1?x=1", "", sign(testSignatureSecret, now, "DELETE", "/users/1?x=1", ""), http.StatusOK, ""},
+		{"wrong secret", "POST", "/users", create, sign("other", now, "POST", "/users", create), http.StatusUnauthorized, "signature_invalid"},
+		{"body tampered", "POST", "/users", `{"name":"Mallory"}`, sign(testSignatureSecret, now, "POST", "/users", create), http.StatusUnauthorized, "signature_invalid"},
+		// tanda tangan POST body kosong tidak bisa dipakai untuk method / path lain
+		{"method tampered", "DELETE", "/users/2", "", sign(testSignatureSecret, now, "POST", "/users/2", ""), http.StatusUnauthorized, "signature_invalid"},
+		{"path tampered", "POST", "/users/3/restore", "", sign(testSignatureSecret, now, "POST", "/users/2/restore", ""), http.StatusUnauthorized, "signature_invalid"},
+		{"query tampered", "GET", "/users?limit=2", "", sign(testSignatureSecret, now, "GET", "/users?limit=1", ""), http.StatusUnauthorized, "signature_invalid"},
+		{"stale timestamp", "POST", "/users", create, sign(testSignatureSecret, now.Add(-signatureMaxAge-time.Minute), "POST", "/users", create), http.StatusUnauthorized, "timestamp_stale"},
+		{"future timestamp", "POST", "/users", create, sign(testSignatureSecret, now.Add(signatureMaxAge+time.Minute), "POST", "/users", create), http.StatusUnauthorized, "timestamp_stale"},
+		{"missing timestamp", "POST", "/users", create, sign(testSignatureSecret, now, "POST", "/users", create)[:2], http.StatusUnauthorized, "timestamp_stale"},
+		{"not sha256", "POST", "/users", create, []string{signatureHeader, "md5=00", timestampHeader, strconv.FormatInt(now.Unix(), 10)}, http.StatusUnauthorized, "signature_invalid"},
+		// tanpa X-Signature aturan API key biasa berlaku
+		{"unsigned", "POST", "/users", create, nil, http.StatusUnauthorized, "unauthorized"},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, tt.method, tt.path, tt.body, tt.header...)
+		if res.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, res.StatusCode, tt.wantStatus, body)
+			continue
+		}
+		if tt.wantCode != "" {
+			if got := decodeBody[errorResponse](t, body).Error; got != tt.wantCode {
+				t.Errorf("%s: error %q, want %q", tt.name, got, tt.wantCode)
+			}
+		} else if tt.method == "POST" && decodeBody[userEnvelope](t, body).Data.Name != "Budi" {
+			t.Errorf("%s: body %s", tt.name, body)
+		}
+	}
+}
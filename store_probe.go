@@ -0,0 +1,63 @@
+// File: /store_probe.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// storeProbeBackoff = jeda antar percobaan probe yang gagal
+const storeProbeBackoff = 500 * time.Millisecond
+
+// storeProber diimplementasikan setiap backend store.
+// Probe harus round-trip sungguhan (tulis, baca, hapus), bukan sekadar cek config.
+type storeProber interface {
+	Probe(ctx context.Context) error
+}
+
+// probeResult = hasil probe terakhir, ditampilkan di GET /health
+type probeResult struct {
+	Status     string    `json:"status"` // "ok" atau "failed"
+	Backend    string    `json:"backend"`
+	Attempts   int       `json:"attempts"`
+	DurationMS float64   `json:"durationMs"`
+	CheckedAt  time.Time `json:"checkedAt"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// probeStore mencoba sampai 1+retries kali, masing-masing dengan timeout.
+// Error yang dikembalikan menyebut backend, operasi dan penyebab aslinya.
+func probeStore(backend string, p storeProber, timeout time.Duration, retries int) (probeResult, error) {
+	start := time.Now()
+	res := probeResult{Backend: backend}
+
+	var err error
+	for res.Attempts < 1+retries {
+		if res.Attempts > 0 {
+			time.Sleep(storeProbeBackoff)
+		}
+		res.Attempts++
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err = p.Probe(ctx)
+		cancel()
+		if err == nil {
+			break
+		}
+		logWarnf("store probe: %s attempt %d/%d failed: %v", backend, res.Attempts, 1+retries, err)
+	}
+
+	res.DurationMS = float64(time.Since(start).Microseconds()) / 1000
+	res.CheckedAt = time.Now().UTC()
+
+	if err != nil {
+		res.Status = "failed"
+		res.Error = err.Error()
+		return res, fmt.Errorf("store probe: %s backend failed after %d attempts: %w", backend, res.Attempts, err)
+	}
+
+	res.Status = "ok"
+	logInfof("store probe: %s ok in %.3fms (attempts: %d)", backend, res.DurationMS, res.Attempts)
+	return res, nil
+}
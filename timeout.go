@@ -0,0 +1,163 @@
+// File: /timeout.go
+package main
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestTimeout memasang deadline d (> 0) di context request. Kalau deadline
+// lewat sebelum handler menulis apa pun, client langsung dapat 504
+// request_timeout (bentuk errorJSON) dan tulisan handler setelahnya dibuang.
+// Handler tetap harus berhenti sendiri lewat ctx (service cek ctx.Err()).
+// Request streaming (lihat streamingRequest) tidak diberi deadline.
+func requestTimeout(d time.Duration, next http.Handler) http.Handler {
+	if d <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if streamingRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+
+		tw := &timeoutWriter{w: w, h: w.Header().Clone(), ctx: ctx, r: r, d: d}
+		stop := context.AfterFunc(ctx, tw.timeout)
+
+		defer func() {
+			stop()
+			tw.finish()
+		}()
+		next.ServeHTTP(tw, r.WithContext(ctx))
+	})
+}
+
+// streamingRequest: client minta SSE/NDJSON, response boleh berjalan lama
+func streamingRequest(r *http.Request) bool {
+	if wantsNDJSON(r) {
+		return true
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mt == "text/event-stream" {
+			return true
+		}
+	}
+	return false
+}
+
+// timeoutWriter: header handler ditampung di h sampai WriteHeader/Write,
+// supaya 504 dari goroutine timer tidak bercampur dengan header handler.
+// Semua akses ke w dijaga mu.
+type timeoutWriter struct {
+	w http.ResponseWriter
+	h http.Header
+
+	// ctx = context dengan deadline; r dan d untuk log dan body 504
+	ctx context.Context
+	r   *http.Request
+	d   time.Duration
+
+	mu       sync.Mutex
+	started  bool // header handler sudah dikirim ke w
+	timedOut bool // 504 sudah dikirim, tulisan handler dibuang
+	done     bool // handler selesai, timer tidak boleh menulis lagi
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expiredLocked() || tw.started {
+		return
+	}
+	tw.startLocked(status)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expiredLocked() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.started {
+		tw.startLocked(http.StatusOK)
+	}
+	return tw.w.Write(b)
+}
+
+func (tw *timeoutWriter) prettyJSON() bool { return jsonPretty(tw.w) }
+
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expiredLocked() {
+		return
+	}
+	if !tw.started {
+		tw.startLocked(http.StatusOK)
+	}
+	_ = http.NewResponseController(tw.w).Flush()
+}
+
+func (tw *timeoutWriter) startLocked(status int) {
+	dst := tw.w.Header()
+	clear(dst)
+	maps.Copy(dst, tw.h)
+	tw.w.WriteHeader(status)
+	tw.started = true
+}
+
+// timeout dipanggil dari goroutine context.AfterFunc
+func (tw *timeoutWriter) timeout() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if !tw.done {
+		tw.expiredLocked()
+	}
+}
+
+// expiredLocked: deadline lewat dan handler belum menulis = kirim 504
+// (sekali) dan true. Dicek juga di Write/WriteHeader/Flush, karena handler
+// yang bangun dari ctx.Done bisa menulis sebelum goroutine AfterFunc jalan.
+func (tw *timeoutWriter) expiredLocked() bool {
+	if tw.timedOut {
+		return true
+	}
+	if tw.started || !errors.Is(tw.ctx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+	tw.timedOut = true
+
+	requestLog(tw.r.Context()).Warn("request timed out", "timeout_ms", durationMS(tw.d))
+	errorJSON(tw.w, http.StatusGatewayTimeout, "request_timeout", "request took too long", apiResponse{
+		"timeout": tw.d.String(),
+	})
+	_ = http.NewResponseController(tw.w).Flush()
+	return true
+}
+
+// finish: handler yang selesai tanpa menulis setelah deadline tetap dapat
+// 504, bukan 200 kosong
+func (tw *timeoutWriter) finish() {
+	tw.mu.Lock()
+	tw.expiredLocked()
+	tw.done = true
+	tw.mu.Unlock()
+}
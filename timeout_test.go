@@ -0,0 +1,171 @@
+// File: /timeout_test.go
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowHandler menunggu d atau sampai ctx batal, lalu menulis 200
+func slowHandler(d time.Duration, writeErr chan<- error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+		}
+		_, err := io.WriteString(w, "late")
+		if writeErr != nil {
+			writeErr <- err
+		}
+	})
+}
+
+func TestRequestTimeoutAnswers504(t *testing.T) {
+	writeErr := make(chan error, 1)
+	h := requestTimeout(20*time.Millisecond, slowHandler(time.Second, writeErr))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/slow", nil))
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status %d, want 504", rec.Code)
+	}
+	if got := rec.Body.String(); !strings.Contains(got, `"error":"request_timeout"`) || !strings.Contains(got, `"timeout":"20ms"`) {
+		t.Fatalf("body = %s", got)
+	}
+	// tulisan handler setelah 504 dibuang, bukan ditempel di body
+	if err := <-writeErr; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Fatalf("late write error = %v, want ErrHandlerTimeout", err)
+	}
+}
+
+func TestRequestTimeoutLeavesFastHandler(t *testing.T) {
+	h := requestTimeout(time.Second, slowHandler(0, nil))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/fast", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "late" {
+		t.Fatalf("status %d, body %q", rec.Code, rec.Body.String())
+	}
+}
+
+// TestHTTPServerWriteTimeoutCutsOffSlowHandler: tanpa requestTimeout,
+// WriteTimeout dari config memutus koneksi handler yang terlalu lama
+func TestHTTPServerWriteTimeoutCutsOffSlowHandler(t *testing.T) {
+	srv, err := NewServer(testConfig(func(c *Config) {
+		c.WriteTimeout = 50 * time.Millisecond
+		c.RequestTimeout = 0
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+
+	hs := srv.HTTPServer()
+	if hs.ReadTimeout != srv.cfg.ReadTimeout || hs.ReadHeaderTimeout != srv.cfg.ReadHeaderTimeout ||
+		hs.WriteTimeout != 50*time.Millisecond || hs.IdleTimeout != srv.cfg.IdleTimeout {
+		t.Fatalf("HTTPServer timeouts = %v %v %v %v", hs.ReadTimeout, hs.ReadHeaderTimeout, hs.WriteTimeout, hs.IdleTimeout)
+	}
+
+	ts := httptest.NewUnstartedServer(nil)
+	ts.Config = hs
+	ts.Config.Handler = slowHandler(300*time.Millisecond, nil)
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	res, err := http.Get(ts.URL)
+	if err == nil {
+		_, err = io.ReadAll(res.Body)
+		res.Body.Close()
+	}
+	if err == nil {
+		t.Fatalf("slow handler was not cut off (status %d)", res.StatusCode)
+	}
+}
+
+// ndjsonServer = http.Server dengan WriteTimeout writeTimeout yang hanya
+// menjalankan writeNDJSON; done ditutup setelah stream selesai atau berhenti
+func ndjsonServer(t *testing.T, writeTimeout time.Duration, n int, item func(i int) any) (*httptest.Server, chan struct{}) {
+	done := make(chan struct{})
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		writeNDJSON(w, r, writeTimeout, n, item)
+	}))
+	ts.Config.WriteTimeout = writeTimeout
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts, done
+}
+
+// export yang lebih lama dari WriteTimeout tetap utuh selama tiap chunk cepat
+func TestNDJSONExtendsWriteDeadlinePerChunk(t *testing.T) {
+	const n = 4 * ndjsonFlushEvery
+	ts, _ := ndjsonServer(t, 200*time.Millisecond, n, func(i int) any {
+		time.Sleep(time.Millisecond) // satu chunk ~100ms, seluruh stream ~400ms
+		return apiResponse{"i": i}
+	})
+
+	res, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("stream cut off: %v", err)
+	}
+	if lines := strings.Count(string(body), "\n"); lines != n {
+		t.Fatalf("%d lines, want %d", lines, n)
+	}
+}
+
+// client yang berhenti membaca diputus oleh deadline, handler tidak menggantung
+func TestNDJSONCutsOffStalledClient(t *testing.T) {
+	big := strings.Repeat("x", 64<<10)
+	ts, done := ndjsonServer(t, 100*time.Millisecond, 10*ndjsonFlushEvery, func(i int) any {
+		return apiResponse{"pad": big}
+	})
+
+	res, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	// body tidak dibaca: buffer TCP penuh, Write server tertahan sampai deadline
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("writeNDJSON still blocked on a client that stopped reading")
+	}
+}
+
+// handler yang bangun dari ctx.Done lalu langsung menulis atau selesai tanpa
+// menulis tetap kalah dari 504, walau goroutine timer belum sempat jalan
+func TestRequestTimeoutRacesHandlerWake(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"write": func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			_, _ = io.WriteString(w, "late")
+		},
+		"write header": func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			w.WriteHeader(http.StatusCreated)
+		},
+		"no write": func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		},
+	}
+	for name, hf := range handlers {
+		for range 50 {
+			rec := httptest.NewRecorder()
+			requestTimeout(time.Millisecond, hf).ServeHTTP(rec, httptest.NewRequest("GET", "/slow", nil))
+			if rec.Code != http.StatusGatewayTimeout || !strings.Contains(rec.Body.String(), `"error":"request_timeout"`) {
+				t.Fatalf("%s: status %d, body %q", name, rec.Code, rec.Body.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// File: /tls.go
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// loadTLSConfig memastikan file cert/key ada dan bisa di-parse saat start,
+// bukan baru gagal ketika client pertama melakukan handshake.
+func loadTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	for _, f := range []string{certFile, keyFile} {
+		if _, err := os.Stat(f); err != nil {
+			return nil, fmt.Errorf("tls: cannot read %s: %w", f, err)
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: invalid certificate/key pair (%s, %s): %w", certFile, keyFile, err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// serverTLSConfig = loadTLSConfig plus verifikasi sertifikat client kalau
+// -tls-client-ca diisi: wajib, atau hanya kalau dikirim (-tls-client-optional)
+func serverTLSConfig(cfg Config) (*tls.Config, error) {
+	tlsConfig, err := loadTLSConfig(cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TLSClientCA == "" {
+		return tlsConfig, nil
+	}
+	pool, err := loadClientCAs(cfg.TLSClientCA)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	if cfg.TLSClientOptional {
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+// httpsRedirectHandler mengarahkan semua request HTTP ke HTTPS dengan 308
+// (308 mempertahankan method dan body, beda dengan 301/302).
+func httpsRedirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
+
+// newAutocertManager cek dulu direktori cache bisa ditulis, supaya
+// kesalahan konfigurasi ketahuan saat start, bukan saat renew pertama.
+func newAutocertManager(domains []string, cacheDir string) (*autocert.Manager, error) {
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("autocert: no domain configured")
+	}
+	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
+		return nil, fmt.Errorf("autocert: cannot create cache dir %s: %w", cacheDir, err)
+	}
+	f, err := os.CreateTemp(cacheDir, ".write-check-*")
+	if err != nil {
+		return nil, fmt.Errorf("autocert: cache dir %s is not writable: %w", cacheDir, err)
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cacheDir),
+		HostPolicy: autocert.HostWhitelist(domains...),
+	}, nil
+}
+
+// loadClientCAs membaca bundle PEM CA untuk verifikasi sertifikat client (mTLS)
+func loadClientCAs(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("tls client ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("tls client ca: no PEM certificates in %s", file)
+	}
+	return pool, nil
+}
+
+// clientCertName = CommonName sertifikat client, atau SAN pertama kalau CN kosong
+func clientCertName(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if sans := clientCertSANs(cert); len(sans) > 0 {
+		return sans[0]
+	}
+	return ""
+}
+
+// clientCertSANs: DNS, URI (mis. SPIFFE ID), lalu email
+func clientCertSANs(cert *x509.Certificate) []string {
+	sans := slices.Clone(cert.DNSNames)
+	for _, u := range cert.URIs {
+		sans = append(sans, u.String())
+	}
+	return append(sans, cert.EmailAddresses...)
+}
+
+// clientCertAuth (enabled = -tls-client-ca) memasukkan identitas sertifikat
+// client yang sudah diverifikasi handshake ke context dan log. Tanpa
+// optional, TLS sudah menolak koneksi tanpa sertifikat; dengan optional,
+// request tanpa sertifikat hanya boleh membaca (method mutasi = 401).
+func clientCertAuth(enabled, optional bool, next http.Handler) http.Handler {
+	if !enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			if optional && slices.Contains(unsafeMethods, r.Method) {
+				errorJSON(w, http.StatusUnauthorized, "client_cert_required", "a verified client certificate is required for this method", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cert := r.TLS.VerifiedChains[0][0]
+		name := clientCertName(cert)
+		ctx := withAuthenticated(r, "cert:"+name)
+		ctx = withLogger(ctx, requestLog(ctx).With("client_cn", cert.Subject.CommonName, "client_san", clientCertSANs(cert)))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
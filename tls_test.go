@@ -0,0 +1,230 @@
+// File: /tls_test.go
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA = CA sekali pakai untuk menandatangani sertifikat server dan client
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var testSerial int64
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	cert, key, certPEM := issueCert(t, tmpl, nil, nil)
+	return &testCA{cert: cert, key: key, pem: certPEM}
+}
+
+// issue menandatangani sertifikat leaf; hasilnya PEM cert dan key
+func (ca *testCA) issue(t *testing.T, tmpl *x509.Certificate) (certPEM, keyPEM []byte) {
+	t.Helper()
+	_, key, certPEM := issueCert(t, tmpl, ca.cert, ca.key)
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+// issueCert: parent nil = self-signed
+func issueCert(t *testing.T, tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSerial++
+	tmpl.SerialNumber = big.NewInt(testSerial)
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMutualTLSModes(t *testing.T) {
+	ca := newTestCA(t, "test ca")
+	serverCert, serverKey := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	clientPEM, clientKeyPEM := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "alice"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	clientCert, err := tls.X509KeyPair(clientPEM, clientKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// client dengan sertifikat dari CA lain
+	otherPEM, otherKeyPEM := newTestCA(t, "other ca").issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "mallory"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	otherCert, err := tls.X509KeyPair(otherPEM, otherKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := writeTestFile(t, "server.pem", serverCert)
+	keyFile := writeTestFile(t, "server-key.pem", serverKey)
+	caFile := writeTestFile(t, "ca.pem", ca.pem)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	clients := map[string][]tls.Certificate{
+		"no cert":        nil,
+		"valid cert":     {clientCert},
+		"untrusted cert": {otherCert},
+	}
+	// status 0 = handshake ditolak
+	tests := []struct {
+		mode   string
+		mutate func(*Config)
+		want   map[string][2]int // client -> {GET, POST}
+	}{
+		{"required", func(c *Config) { c.TLSClientCA = caFile }, map[string][2]int{
+			"no cert":        {0, 0},
+			"valid cert":     {http.StatusOK, http.StatusCreated},
+			"untrusted cert": {0, 0},
+		}},
+		{"optional", func(c *Config) { c.TLSClientCA, c.TLSClientOptional = caFile, true }, map[string][2]int{
+			"no cert":    {http.StatusOK, http.StatusUnauthorized},
+			"valid cert": {http.StatusOK, http.StatusCreated},
+			// client Go tidak mengirim sertifikat yang CA-nya tidak diminta
+			// server, jadi sama dengan tanpa sertifikat
+			"untrusted cert": {http.StatusOK, http.StatusUnauthorized},
+		}},
+		{"no client ca", nil, map[string][2]int{
+			"no cert":        {http.StatusOK, http.StatusCreated},
+			"valid cert":     {http.StatusOK, http.StatusCreated},
+			"untrusted cert": {http.StatusOK, http.StatusCreated},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := testConfig(func(c *Config) {
+				c.TLSCert, c.TLSKey = certFile, keyFile
+				if tt.mutate != nil {
+					tt.mutate(c)
+				}
+			})
+			tlsConfig, err := serverTLSConfig(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv, err := NewServer(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ts := httptest.NewUnstartedServer(srv.Handler())
+			ts.TLS = tlsConfig
+			ts.StartTLS()
+			t.Cleanup(func() {
+				ts.Close()
+				_ = srv.Close()
+			})
+
+			for client, want := range tt.want {
+				c := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+					RootCAs:      roots,
+					Certificates: clients[client],
+				}}}
+				for i, req := range []struct{ method, body string }{
+					{"GET", ""},
+					{"POST", `{"name":"Ada"}`},
+				} {
+					r, err := http.NewRequest(req.method, ts.URL+"/users", strings.NewReader(req.body))
+					if err != nil {
+						t.Fatal(err)
+					}
+					r.Header.Set("Content-Type", "application/json")
+					res, err := c.Do(r)
+					if want[i] == 0 {
+						if err == nil {
+							res.Body.Close()
+							t.Errorf("%s %s: status %d, want handshake failure", client, req.method, res.StatusCode)
+						}
+						continue
+					}
+					if err != nil {
+						t.Errorf("%s %s: %v", client, req.method, err)
+						continue
+					}
+					res.Body.Close()
+					if res.StatusCode != want[i] {
+						t.Errorf("%s %s: status %d, want %d", client, req.method, res.StatusCode, want[i])
+					}
+				}
+				c.CloseIdleConnections()
+			}
+		})
+	}
+}
+
+func TestServerTLSConfigErrors(t *testing.T) {
+	ca := newTestCA(t, "test ca")
+	certPEM, keyPEM := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "localhost"}})
+	certFile := writeTestFile(t, "server.pem", certPEM)
+	keyFile := writeTestFile(t, "server-key.pem", keyPEM)
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"missing cert", Config{TLSCert: filepath.Join(t.TempDir(), "nope.pem"), TLSKey: keyFile}},
+		{"key is not a key", Config{TLSCert: certFile, TLSKey: certFile}},
+		{"missing client ca", Config{TLSCert: certFile, TLSKey: keyFile, TLSClientCA: filepath.Join(t.TempDir(), "ca.pem")}},
+		{"client ca without PEM", Config{TLSCert: certFile, TLSKey: keyFile, TLSClientCA: writeTestFile(t, "ca.pem", []byte("not pem"))}},
+	}
+	for _, tt := range tests {
+		if _, err := serverTLSConfig(tt.cfg); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
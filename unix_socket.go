@@ -0,0 +1,46 @@
+// File: /unix_socket.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// listenUnix membuat listener di path socket.
+// File socket sisa proses lama dihapus dulu, tapi file biasa tidak disentuh.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("unix socket: %s exists and is not a socket", path)
+		}
+		// masih ada yang listen = bukan stale, jangan direbut
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("unix socket: %s is already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("unix socket: remove stale %s: %w", path, err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("unix socket: %w", err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("unix socket: chmod %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// removeSocket dipanggil setelah shutdown supaya tidak ada socket stale
+func removeSocket(path string) {
+	logInfof("removing %s", path)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logErrorf("unix socket: %v", err)
+	}
+}
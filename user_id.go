@@ -0,0 +1,138 @@
+// File: /user_id.go
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// IDMode menentukan format ID user yang dibuat store
+type IDMode string
+
+const (
+	IDModeInt  IDMode = "int"
+	IDModeUUID IDMode = "uuid"
+)
+
+func parseIDMode(s string) (IDMode, error) {
+	switch m := IDMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case IDModeInt, IDModeUUID:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid id mode %q (want int or uuid)", s)
+}
+
+// UserID bisa berupa angka ("42") atau UUID.
+// ID angka tetap di-encode sebagai JSON number supaya client lama tidak rusak.
+type UserID string
+
+func (id UserID) String() string {
+	return string(id)
+}
+
+// Int mengembalikan nilai angka ID (ok=false untuk UUID)
+func (id UserID) Int() (int, bool) {
+	n, err := strconv.Atoi(string(id))
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+func (id UserID) MarshalJSON() ([]byte, error) {
+	if n, ok := id.Int(); ok {
+		return []byte(strconv.Itoa(n)), nil
+	}
+	return json.Marshal(string(id))
+}
+
+func (id *UserID) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*id = UserID(strconv.Itoa(n))
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("user id must be an integer or a string")
+	}
+	*id = UserID(s)
+	return nil
+}
+
+// lessUserID: ID angka diurutkan secara numerik dan selalu sebelum UUID
+func lessUserID(a, b UserID) bool {
+	an, aok := a.Int()
+	bn, bok := b.Int()
+	switch {
+	case aok && bok:
+		return an < bn
+	case aok != bok:
+		return aok
+	}
+	return a < b
+}
+
+// parseUserID menerima positive integer atau UUID dari path.
+// Error selalu *AppError 400 invalid_path: input berbentuk angka mendapat
+// pesan spesifik dari parsePositiveInt (nol, negatif, terlalu besar).
+func parseUserID(s string) (UserID, error) {
+	s = strings.TrimSpace(s)
+
+	if isUUID(s) {
+		return UserID(strings.ToLower(s)), nil
+	}
+
+	n, err := parsePositiveInt("user id", s)
+	if err == nil {
+		return UserID(strconv.Itoa(n)), nil
+	}
+
+	msg := "user id must be a positive integer or a UUID"
+	if looksNumeric(s) {
+		msg = err.(*AppError).Message
+	}
+	return "", &AppError{Status: http.StatusBadRequest, Code: "invalid_path", Message: msg}
+}
+
+// looksNumeric: tanda +/- opsional lalu hanya digit
+func looksNumeric(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// newUUID membuat UUID v4 acak
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant RFC 4122
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// isUUID cek format 8-4-4-4-12 hex
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+// File: /user_id_test.go
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestParsePositiveInt(t *testing.T) {
+	maxInt := strconv.Itoa(math.MaxInt)
+	tests := []struct {
+		in      string
+		want    int
+		wantMsg string // "" = sukses
+	}{
+		{"1", 1, ""},
+		{" 42 ", 42, ""},
+		{"+7", 7, ""},
+		{"007", 7, ""},
+		{maxInt, math.MaxInt, ""},
+		{"", 0, "limit is required"},
+		{"   ", 0, "limit is required"},
+		{"0", 0, "limit must be a positive integer, got 0"},
+		{"-0", 0, "limit must be a positive integer, got 0"},
+		{"-5", 0, "limit must be a positive integer, got -5"},
+		{"abc", 0, `limit must be a number, got "abc"`},
+		{"1.5", 0, `limit must be a number, got "1.5"`},
+		{"0x10", 0, `limit must be a number, got "0x10"`},
+		{"12abc", 0, `limit must be a number, got "12abc"`},
+		{"99999999999999999999", 0, "limit must be at most " + maxInt},
+		{"+99999999999999999999", 0, "limit must be at most " + maxInt},
+		{"-99999999999999999999", 0, "limit must be a positive integer"},
+	}
+	for _, tt := range tests {
+		n, err := parsePositiveInt("limit", tt.in)
+		if tt.wantMsg == "" {
+			if err != nil || n != tt.want {
+				t.Errorf("parsePositiveInt(%q) = %d, %v; want %d", tt.in, n, err, tt.want)
+			}
+			continue
+		}
+		appErr := asAppError(t, err)
+		if n != 0 || appErr.Status != http.StatusBadRequest || appErr.Code != "validation_failed" || appErr.Message != tt.wantMsg {
+			t.Errorf("parsePositiveInt(%q) = %d, %d %s %q; want %q", tt.in, n, appErr.Status, appErr.Code, appErr.Message, tt.wantMsg)
+		}
+	}
+}
+
+func TestParseUserID(t *testing.T) {
+	const generic = "user id must be a positive integer or a UUID"
+	tests := []struct {
+		in      string
+		want    UserID
+		wantMsg string // "" = sukses
+	}{
+		{"1", "1", ""},
+		{"007", "7", ""},
+		{" 12 ", "12", ""},
+		{"+3", "3", ""},
+		{"0F8FAD5B-D9CB-469F-A165-70867728950E", "0f8fad5b-d9cb-469f-a165-70867728950e", ""},
+		{"", "", generic},
+		// bentuk angka: pesan spesifik dari parsePositiveInt
+		{"0", "", "user id must be a positive integer, got 0"},
+		{"-4", "", "user id must be a positive integer, got -4"},
+		{"99999999999999999999", "", "user id must be at most " + strconv.Itoa(math.MaxInt)},
+		{"-99999999999999999999", "", "user id must be a positive integer"},
+		// selain itu pesan umum
+		{"abc", "", generic},
+		{"1.5", "", generic},
+		{"0x10", "", generic},
+		{"0f8fad5b-d9cb-469f-a165", "", generic},
+	}
+	for _, tt := range tests {
+		id, err := parseUserID(tt.in)
+		if tt.wantMsg == "" {
+			if err != nil || id != tt.want {
+				t.Errorf("parseUserID(%q) = %q, %v; want %q", tt.in, id, err, tt.want)
+			}
+			continue
+		}
+		appErr := asAppError(t, err)
+		if id != "" || appErr.Status != http.StatusBadRequest || appErr.Code != "invalid_path" || appErr.Message != tt.wantMsg {
+			t.Errorf("parseUserID(%q) = %q, %d %s %q; want %q", tt.in, id, appErr.Status, appErr.Code, appErr.Message, tt.wantMsg)
+		}
+	}
+}
@@ -2,57 +2,354 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type UsersHandler struct {
-	svc *UserService
+	svc   *UserService
+	links linkBuilder
+
+	// createValidator: validasi body POST /users terhadap JSON Schema
+	// (-schema-validation), nil = hanya validasi di kode
+	createValidator BodyValidator
+	// idempotency: cache Idempotency-Key POST /users, nil = header diabaikan
+	idempotency *idempotencyCache
+	// writeTimeout = -write-timeout, diperpanjang per chunk export NDJSON
+	writeTimeout time.Duration
+}
+
+func NewUsersHandler(svc *UserService, basePath string) *UsersHandler {
+	return &UsersHandler{svc: svc, links: newLinkBuilder(basePath)}
+}
+
+// method yang didukung per path, dipakai untuk 405 + header Allow.
+// Sumbernya routeTable (routes.go), jadi OPTIONS dan 405 selalu sama.
+var (
+	usersMethods        = routeMethods("/users")
+	userItemMethods     = routeMethods("/users/{id}")
+	userProfileMethods  = routeMethods("/users/{id}/profile")
+	userRestoreMethods  = routeMethods("/users/{id}/restore")
+	userPasswordMethods = routeMethods("/users/{id}/password")
+	userOrderMethods    = routeMethods("/users/{id}/orders/{orderId}")
+	userImportMethods   = routeMethods("/users/import")
+)
+
+// role kosong = RoleUser; mengisi role hanya boleh untuk admin.
+// password opsional, disimpan sebagai hash bcrypt.
+type createUserRequest struct {
+	Name     string `json:"name"`
+	Role     Role   `json:"role"`
+	Password string `json:"password"`
+}
+
+func (req createUserRequest) Validate() error {
+	if _, err := validateUserName(req.Name); err != nil {
+		return err
+	}
+	if req.Password != "" {
+		if err := validatePassword("password", req.Password); err != nil {
+			return err
+		}
+	}
+	if req.Role == "" {
+		return nil
+	}
+	return validateRole(req.Role)
+}
+
+// PUT: role nil = tidak diubah
+type updateUserRequest struct {
+	Name string `json:"name"`
+	Role *Role  `json:"role"`
+}
+
+func (req updateUserRequest) Validate() error {
+	if _, err := validateUserName(req.Name); err != nil {
+		return err
+	}
+	if req.Role == nil {
+		return nil
+	}
+	return validateRole(*req.Role)
+}
+
+// PATCH: field nil = tidak diubah
+type patchUserRequest struct {
+	Name *string `json:"name"`
+	Role *Role   `json:"role"`
+}
+
+func (req patchUserRequest) Validate() error {
+	if req.Name != nil {
+		if _, err := validateUserName(*req.Name); err != nil {
+			return err
+		}
+	}
+	if req.Role == nil {
+		return nil
+	}
+	return validateRole(*req.Role)
+}
+
+// POST /users/{id}/password; currentPassword boleh kosong kalau user belum punya password
+type changePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
 }
 
-func NewUsersHandler(svc *UserService) *UsersHandler {
-	return &UsersHandler{svc: svc}
+func (req changePasswordRequest) Validate() error {
+	if req.NewPassword == "" {
+		return validationError("missing required fields", []string{"newPassword is required"})
+	}
+	return validatePassword("newPassword", req.NewPassword)
+}
+
+func validateRole(role Role) error {
+	if _, err := parseRole(string(role)); err != nil {
+		return validationError("invalid field", []string{"role must be admin or user"})
+	}
+	return nil
+}
+
+// authorizeSetRole: setRole = body mengisi field role
+func authorizeSetRole(r *http.Request, setRole bool) error {
+	if !setRole {
+		return nil
+	}
+	return authorizeRequest(r, actionSetRole)
 }
 
 // /users -> GET list, POST create
 func (h *UsersHandler) HandleUsers(w http.ResponseWriter, r *http.Request) {
-	if !requireMethods(w, r, http.MethodGet, http.MethodPost) {
+	if !requireMethods(w, r, usersMethods...) {
 		return
 	}
 
 	switch r.Method {
 	case http.MethodGet:
-		users := h.svc.ListUsers()
-		writeJSON(w, http.StatusOK, apiResponse{
-			"items": users,
-			"count": len(users),
-		})
-		return
+		includeDeleted, ok := parseIncludeDeleted(w, r)
+		if !ok {
+			return
+		}
 
-	case http.MethodPost:
-		type createUserRequest struct {
-			Name string `json:"name"`
+		// ?ids=1,2,5 = ambil banyak user sekaligus, tanpa filter/pagination
+		if r.URL.Query().Has("ids") {
+			h.getMany(w, r, includeDeleted)
+			return
+		}
+
+		inactiveSince, ok := parseInactiveSince(w, r)
+		if !ok {
+			return
+		}
+		createdAfter, createdBefore, ok := parseCreatedRange(w, r)
+		if !ok {
+			return
 		}
-		var req createUserRequest
 
-		if err := readJSON(w, r, &req); err != nil {
-			errorJSON(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
+		opts, err := ParseListOptions(r, userListFields.sortNames(), userListFields.filterNames())
+		if err != nil {
+			writeAppError(w, r, err)
 			return
 		}
 
-		u, err := h.svc.CreateUser(req.Name)
+		// conditional GET: versi koleksi dibaca sebelum ListUsers. Hasil
+		// inactiveSince ikut berubah seiring waktu, jadi tidak diberi ETag.
+		setCache(w, r, cacheRevalidate)
+		if inactiveSince == 0 {
+			version, modified := h.svc.CollectionVersion()
+			if notModified(w, r, collectionETag(version, wantsNDJSON(r)), modified) {
+				return
+			}
+		}
+
+		page, err := h.svc.ListUsers(r.Context(), userListFilter{
+			IncludeDeleted: includeDeleted,
+			InactiveSince:  inactiveSince,
+			CreatedAfter:   createdAfter,
+			CreatedBefore:  createdBefore,
+		}, opts)
 		if err != nil {
-			h.writeAppError(w, err)
+			writeAppError(w, r, err)
+			return
+		}
+		// NDJSON = export: satu user per baris, tanpa data/meta
+		if wantsNDJSON(r) {
+			writeNDJSON(w, r, h.writeTimeout, len(page.Items), func(i int) any { return h.links.user(page.Items[i]) })
+			return
+		}
+		items := make([]userResource, len(page.Items))
+		for i, u := range page.Items {
+			items[i] = h.links.user(u)
+		}
+		meta := page.meta()
+		meta["_links"] = h.links.page([]string{"users"}, r.URL.Query(), opts, page.Total)
+		writeData(w, http.StatusOK, items, meta)
+		return
+
+	case http.MethodPost:
+		if err := authorizeRequest(r, actionWrite); err != nil {
+			writeAppError(w, r, err)
 			return
 		}
+		h.idempotency.serve(w, r, h.createUser)
+	}
+}
+
+// createUser = POST /users (lewat idempotency.serve)
+func (h *UsersHandler) createUser(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeJSONWith[createUserRequest](w, r, h.createValidator)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+	if err := authorizeSetRole(r, req.Role != ""); err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	u, err := h.svc.CreateUser(r.Context(), req.Name, req.Role, req.Password)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	res := h.links.user(u)
+	w.Header().Set("ETag", u.ETag())
+	// Location = _links.self, jadi ikut BasePath
+	writeCreated(w, res.Links["self"].Href, res, nil)
+}
+
+// getMany = GET /users?ids=1,2,5: user yang ditemukan (urutan ids) plus
+// daftar id yang tidak ada di meta.missing
+func (h *UsersHandler) getMany(w http.ResponseWriter, r *http.Request, includeDeleted bool) {
+	ids, err := parseUserIDList(r.URL.Query().Get("ids"))
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	users, missing, err := h.svc.GetUsers(r.Context(), ids, includeDeleted)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+	items := make([]userResource, len(users))
+	for i, u := range users {
+		items[i] = h.links.user(u)
+	}
+	setCache(w, r, cachePrivate)
+	writeData(w, http.StatusOK, items, apiResponse{
+		"count":   len(items),
+		"missing": missing,
+	})
+}
+
+// GET /users/exists?name= = cek nama sebelum create
+func (h *UsersHandler) HandleNameExists(w http.ResponseWriter, r *http.Request) {
+	if !requireMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	q := r.URL.Query()
+	if strings.TrimSpace(q.Get("name")) == "" {
+		writeAppError(w, r, validationError("missing required query parameter", []string{"name is required"}))
+		return
+	}
+
+	exists, err := h.svc.NameExists(r.Context(), q.Get("name"))
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+	setCache(w, r, cacheRevalidate)
+	writeData(w, http.StatusOK, apiResponse{"exists": exists}, nil)
+}
+
+// recentUsersWindow = jendela default GET /users/recent tanpa ?since=
+const recentUsersWindow = 5 * time.Minute
+
+// GET /users/recent?since=<RFC3339>&limit= = user baru, terbaru dulu
+func (h *UsersHandler) HandleRecentUsers(w http.ResponseWriter, r *http.Request) {
+	if !requireMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	q := r.URL.Query()
+	var details []string
+	since := h.svc.Now().Add(-recentUsersWindow)
+	if raw := strings.TrimSpace(q.Get("since")); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			details = append(details, "since must be an RFC3339 timestamp such as 2024-01-31T00:00:00Z")
+		}
+		since = t
+	}
+	limit := recentUsersLimit
+	if raw := q.Get("limit"); raw != "" {
+		n, err := parsePositiveInt("limit", raw)
+		if err != nil || n > recentUsersLimit {
+			details = append(details, "limit must be between 1 and "+strconv.Itoa(recentUsersLimit))
+		}
+		limit = n
+	}
+	if len(details) > 0 {
+		writeAppError(w, r, validationError("invalid query parameter", details))
+		return
+	}
 
-		writeJSON(w, http.StatusCreated, u)
+	page, err := h.svc.RecentUsers(r.Context(), since, limit)
+	if err != nil {
+		writeAppError(w, r, err)
 		return
 	}
+	items := make([]userResource, len(page.Items))
+	for i, u := range page.Items {
+		items[i] = h.links.user(u)
+	}
+	meta := page.meta()
+	delete(meta, "offset")
+	meta["since"] = since.Format(time.RFC3339Nano)
+	setCache(w, r, cacheRevalidate)
+	writeData(w, http.StatusOK, items, meta)
 }
 
-// /users/{id}, /users/{id}/profile, /users/{id}/orders/{orderId}
+// parseUserIDList memecah "1,2,5": dedupe, maksimal maxListLimit id,
+// setiap id harus positive integer atau UUID
+func parseUserIDList(raw string) ([]UserID, error) {
+	var (
+		ids     []UserID
+		details []string
+		seen    = map[UserID]bool{}
+	)
+	for i, part := range strings.Split(raw, ",") {
+		id, err := parseUserID(part)
+		if err != nil {
+			details = append(details, fmt.Sprintf("ids[%d]: %s", i, err.(*AppError).Message))
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > maxListLimit {
+		details = append(details, "ids must contain at most "+strconv.Itoa(maxListLimit)+" distinct ids")
+	}
+	if len(details) > 0 {
+		return nil, validationError("invalid query parameter", details)
+	}
+	return ids, nil
+}
+
+// /users/{id}, /users/{id}/profile, /users/{id}/restore, /users/{id}/password,
+// /users/{id}/orders/{orderId}
 func (h *UsersHandler) HandleUserRoutes(w http.ResponseWriter, r *http.Request) {
 	const prefix = "/users/"
 	path := r.URL.Path
@@ -64,63 +361,192 @@ func (h *UsersHandler) HandleUserRoutes(w http.ResponseWriter, r *http.Request)
 	rest := strings.Trim(path[len(prefix):], "/")
 	parts := strings.Split(rest, "/")
 
-	id, err := parsePositiveInt(parts[0])
+	id, err := parseUserID(parts[0])
 	if err != nil {
-		errorJSON(w, http.StatusBadRequest, "invalid_path", "user id must be a positive integer", nil)
+		writeAppError(w, r, err)
 		return
 	}
 
 	// /users/{id}
 	if len(parts) == 1 {
-		if !requireMethods(w, r, http.MethodGet, http.MethodDelete) {
+		setRoutePattern(r, "/users/{id}")
+		if !requireMethods(w, r, userItemMethods...) {
 			return
 		}
 
 		switch r.Method {
 		case http.MethodGet:
-			u, err := h.svc.GetUser(id)
+			includeDeleted, ok := parseIncludeDeleted(w, r)
+			if !ok {
+				return
+			}
+
+			u, err := h.svc.GetUser(r.Context(), id, includeDeleted)
+			if err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			setCache(w, r, cachePrivate)
+			if notModified(w, r, u.ETag(), time.Time{}) {
+				return
+			}
+			writeData(w, http.StatusOK, h.links.user(u), nil)
+			return
+
+		case http.MethodPut:
+			if err := authorizeRequest(r, actionWrite); err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			req, err := decodeJSON[updateUserRequest](w, r)
+			if err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			if err := authorizeSetRole(r, req.Role != nil); err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+
+			u, err := h.svc.UpdateUser(r.Context(), id, req.Name, req.Role, h.ifMatchVersion(r, id))
+			if err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			w.Header().Set("ETag", u.ETag())
+			writeData(w, http.StatusOK, h.links.user(u), nil)
+			return
+
+		case http.MethodPatch:
+			if err := authorizeRequest(r, actionWrite); err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			req, err := decodeJSON[patchUserRequest](w, r)
+			if err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			if err := authorizeSetRole(r, req.Role != nil); err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+
+			u, err := h.svc.PatchUser(r.Context(), id, req.Name, req.Role, h.ifMatchVersion(r, id))
 			if err != nil {
-				h.writeAppError(w, err)
+				writeAppError(w, r, err)
 				return
 			}
-			writeJSON(w, http.StatusOK, u)
+			w.Header().Set("ETag", u.ETag())
+			writeData(w, http.StatusOK, h.links.user(u), nil)
 			return
 
 		case http.MethodDelete:
-			if err := h.svc.DeleteUser(id); err != nil {
-				h.writeAppError(w, err)
+			if err := authorizeTarget(r, actionDelete, id); err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			if err := h.svc.DeleteUser(r.Context(), id); err != nil {
+				writeAppError(w, r, err)
 				return
 			}
-			writeJSON(w, http.StatusOK, apiResponse{
+			writeData(w, http.StatusOK, apiResponse{
 				"deleted": true,
 				"id":      id,
-			})
+			}, nil)
 			return
 		}
 	}
 
 	// /users/{id}/profile
 	if len(parts) == 2 && parts[1] == "profile" {
-		if !requireMethods(w, r, http.MethodGet) {
+		setRoutePattern(r, "/users/{id}/profile")
+		if !requireMethods(w, r, userProfileMethods...) {
 			return
 		}
 
-		// pastikan user ada
-		if _, err := h.svc.GetUser(id); err != nil {
-			h.writeAppError(w, err)
+		switch r.Method {
+		case http.MethodGet:
+			u, err := h.svc.GetUser(r.Context(), id, false)
+			if err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			setCache(w, r, cachePrivate)
+			writeData(w, http.StatusOK, profileResource{ID: u.ID, Profile: u.Profile}, nil)
+			return
+
+		case http.MethodPatch:
+			if err := authorizeRequest(r, actionWrite); err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			patch, err := decodeJSON[ProfilePatch](w, r)
+			if err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			u, err := h.svc.PatchProfile(r.Context(), id, patch)
+			if err != nil {
+				writeAppError(w, r, err)
+				return
+			}
+			w.Header().Set("ETag", u.ETag())
+			writeData(w, http.StatusOK, profileResource{ID: u.ID, Profile: u.Profile}, nil)
 			return
 		}
+	}
 
-		writeJSON(w, http.StatusOK, apiResponse{
-			"id":      id,
-			"profile": true,
-		})
+	// /users/{id}/restore
+	if len(parts) == 2 && parts[1] == "restore" {
+		setRoutePattern(r, "/users/{id}/restore")
+		if !requireMethods(w, r, userRestoreMethods...) {
+			return
+		}
+		if err := authorizeRequest(r, actionWrite); err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+
+		u, err := h.svc.RestoreUser(r.Context(), id)
+		if err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+		writeData(w, http.StatusOK, h.links.user(u), nil)
+		return
+	}
+
+	// /users/{id}/password
+	if len(parts) == 2 && parts[1] == "password" {
+		setRoutePattern(r, "/users/{id}/password")
+		if !requireMethods(w, r, userPasswordMethods...) {
+			return
+		}
+		if err := authorizeRequest(r, actionWrite); err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+
+		req, err := decodeJSON[changePasswordRequest](w, r)
+		if err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+		u, err := h.svc.ChangePassword(r.Context(), id, req.CurrentPassword, req.NewPassword)
+		if err != nil {
+			writeAppError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", u.ETag())
+		writeData(w, http.StatusOK, h.links.user(u), nil)
 		return
 	}
 
 	// /users/{id}/orders/{orderId}
 	if len(parts) == 3 && parts[1] == "orders" {
-		if !requireMethods(w, r, http.MethodGet) {
+		setRoutePattern(r, "/users/{id}/orders/{orderId}")
+		if !requireMethods(w, r, userOrderMethods...) {
 			return
 		}
 
@@ -131,36 +557,145 @@ func (h *UsersHandler) HandleUserRoutes(w http.ResponseWriter, r *http.Request)
 		}
 
 		// pastikan user ada
-		if _, err := h.svc.GetUser(id); err != nil {
-			h.writeAppError(w, err)
+		if _, err := h.svc.GetUser(r.Context(), id, false); err != nil {
+			writeAppError(w, r, err)
 			return
 		}
 
-		writeJSON(w, http.StatusOK, apiResponse{
+		setCache(w, r, cachePrivate)
+		writeData(w, http.StatusOK, apiResponse{
 			"id":      id,
 			"orderId": orderId,
-		})
+		}, nil)
 		return
 	}
 
-	errorJSON(w, http.StatusNotFound, "not_found", "resource not found", apiResponse{
-		"path": r.URL.Path,
-	})
+	notFound(w, r)
 }
 
-func (h *UsersHandler) writeAppError(w http.ResponseWriter, err error) {
-	if ae, ok := err.(*AppError); ok {
-		errorJSON(w, ae.Status, ae.Code, ae.Message, ae.Details)
-		return
+// ?includeDeleted=true ikut menampilkan user yang di-soft-delete
+func parseIncludeDeleted(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	raw := strings.TrimSpace(r.URL.Query().Get("includeDeleted"))
+	if raw == "" {
+		return false, true
 	}
-	errorJSON(w, http.StatusInternalServerError, "internal_error", "unexpected error", nil)
+
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		errorJSON(w, http.StatusBadRequest, "validation_failed", "invalid query parameter", []string{
+			"includeDeleted must be true or false",
+		})
+		return false, false
+	}
+	return v, true
+}
+
+// maxInactiveDays = batas ?inactiveSince (100 tahun), supaya hari * 24h
+// tidak overflow time.Duration
+const maxInactiveDays = 36500
+
+// parseInactiveSince membaca ?inactiveSince=30d (hari) atau durasi Go (12h, 90m)
+func parseInactiveSince(w http.ResponseWriter, r *http.Request) (time.Duration, bool) {
+	raw := strings.TrimSpace(r.URL.Query().Get("inactiveSince"))
+	if raw == "" {
+		return 0, true
+	}
+
+	d, err := time.ParseDuration(raw)
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		var n int
+		n, err = parsePositiveInt("inactiveSince", days)
+		if err == nil && n > maxInactiveDays {
+			writeAppError(w, r, validationError("invalid query parameter", []string{
+				fmt.Sprintf("inactiveSince must be at most %dd", maxInactiveDays),
+			}))
+			return 0, false
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	}
+	if err != nil || d <= 0 {
+		writeAppError(w, r, validationError("invalid query parameter", []string{
+			"inactiveSince must be a positive duration such as 30d or 12h",
+		}))
+		return 0, false
+	}
+	return d, true
 }
 
-func parsePositiveInt(s string) (int, error) {
+// parseCreatedRange membaca ?createdAfter= dan ?createdBefore= (RFC3339, inklusif)
+func parseCreatedRange(w http.ResponseWriter, r *http.Request) (after, before time.Time, ok bool) {
+	q := r.URL.Query()
+	var details []string
+
+	parse := func(name string) time.Time {
+		raw := strings.TrimSpace(q.Get(name))
+		if raw == "" {
+			return time.Time{}
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			details = append(details, name+" must be an RFC3339 timestamp such as 2024-01-31T00:00:00Z")
+		}
+		return t
+	}
+	after = parse("createdAfter")
+	before = parse("createdBefore")
+
+	if len(details) > 0 {
+		errorJSON(w, http.StatusBadRequest, "validation_failed", "invalid query parameter", details)
+		return time.Time{}, time.Time{}, false
+	}
+	return after, before, true
+}
+
+// parsePositiveInt membaca bilangan bulat > 0 untuk parameter name.
+// Error = *AppError 400 validation_failed yang pesannya membedakan kosong,
+// bukan angka, nol/negatif, dan terlalu besar.
+func parsePositiveInt(name, s string) (int, error) {
 	s = strings.TrimSpace(s)
 	n, err := strconv.Atoi(s)
-	if err != nil || n <= 0 {
-		return 0, strconv.ErrSyntax
+
+	var msg string
+	switch {
+	case s == "":
+		msg = name + " is required"
+	case errors.Is(err, strconv.ErrRange) && s[0] != '-':
+		msg = fmt.Sprintf("%s must be at most %d", name, math.MaxInt)
+	case errors.Is(err, strconv.ErrRange):
+		msg = name + " must be a positive integer"
+	case err != nil:
+		msg = fmt.Sprintf("%s must be a number, got %q", name, truncatePath(s))
+	case n <= 0:
+		msg = fmt.Sprintf("%s must be a positive integer, got %d", name, n)
+	default:
+		return n, nil
+	}
+	return 0, &AppError{Status: http.StatusBadRequest, Code: ErrValidation.Code, Message: msg}
+}
+
+// parseIfMatch membaca If-Match untuk PUT/PATCH: "" = tanpa syarat (header
+// kosong atau "*"), selain itu nilai header apa adanya.
+func parseIfMatch(r *http.Request) string {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "*" {
+		return ""
+	}
+	return v
+}
+
+// ifMatchVersion menerjemahkan If-Match ke Version user saat ini supaya store
+// tetap mengecek secara atomic: 0 = tanpa syarat, -1 = tidak cocok (412).
+// Hanya ETag persis yang cocok; ETag lemah (W/...), daftar, atau nilai lain
+// tidak pernah cocok.
+func (h *UsersHandler) ifMatchVersion(r *http.Request, id UserID) int {
+	tag := parseIfMatch(r)
+	if tag == "" {
+		return 0
+	}
+	// user tidak ada: service yang menjawab 404
+	current, err := h.svc.GetUser(r.Context(), id, false)
+	if err != nil || current.ETag() != tag {
+		return -1
 	}
-	return n, nil
+	return current.Version
 }
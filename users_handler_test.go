@@ -0,0 +1,663 @@
+// File: /users_handler_test.go
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserIDModes(t *testing.T) {
+	for _, mode := range []IDMode{IDModeInt, IDModeUUID} {
+		t.Run(string(mode), func(t *testing.T) {
+			ts := newTestServer(t, testConfig(func(c *Config) { c.IDMode = mode }))
+
+			res, body := doRequest(t, ts, "POST", "/users", `{"name":"Alice Doe"}`)
+			if res.StatusCode != http.StatusCreated {
+				t.Fatalf("create: status %d: %s", res.StatusCode, body)
+			}
+			created := decodeBody[userEnvelope](t, body).Data
+			switch mode {
+			case IDModeInt:
+				// ID angka tetap number di JSON
+				if created.ID != "1" || !strings.Contains(body, `"id":1,`) {
+					t.Fatalf("int id = %s", body)
+				}
+			case IDModeUUID:
+				if !isUUID(string(created.ID)) || !strings.Contains(body, `"id":"`+string(created.ID)+`"`) {
+					t.Fatalf("uuid id = %s", body)
+				}
+			}
+
+			for _, path := range []string{"/users/" + string(created.ID), "/users/" + strings.ToUpper(string(created.ID))} {
+				res, body = doRequest(t, ts, "GET", path, "")
+				if res.StatusCode != http.StatusOK {
+					t.Fatalf("GET %s: status %d: %s", path, res.StatusCode, body)
+				}
+				if got := decodeBody[userEnvelope](t, body).Data; got.ID != created.ID || got.Name != "Alice Doe" {
+					t.Fatalf("GET %s = %+v", path, got)
+				}
+			}
+
+			other := "/users/00000000-0000-4000-8000-000000000000"
+			if mode == IDModeUUID {
+				other = "/users/1"
+			}
+			if res, body = doRequest(t, ts, "GET", other, ""); res.StatusCode != http.StatusNotFound {
+				t.Fatalf("GET %s: status %d, want 404: %s", other, res.StatusCode, body)
+			}
+		})
+	}
+}
+
+// TestUserPathErrors mengunci pilihan error route /users/{id}: ID yang tidak
+// bisa di-parse = 400 invalid_path, ID valid yang tidak ada = 404 not_found
+// (tanpa details), sub-path tak dikenal = 404 not_found dengan path.
+func TestUserPathErrors(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Alice Doe"}`)
+
+	tests := []struct {
+		method, path, body string
+		wantStatus         int
+		want               errorResponse
+	}{
+		{"GET", "/users/abc", "", http.StatusBadRequest, errorResponse{Error: "invalid_path", Message: "user id must be a positive integer or a UUID"}},
+		{"GET", "/users/0", "", http.StatusBadRequest, errorResponse{Error: "invalid_path", Message: "user id must be a positive integer, got 0"}},
+		{"DELETE", "/users/-1", "", http.StatusBadRequest, errorResponse{Error: "invalid_path", Message: "user id must be a positive integer, got -1"}},
+		{"GET", "/users/99", "", http.StatusNotFound, errorResponse{Error: "not_found", Message: "resource not found"}},
+		{"PUT", "/users/99", `{"name":"Nobody Here"}`, http.StatusNotFound, errorResponse{Error: "not_found", Message: "resource not found"}},
+		{"DELETE", "/users/99", "", http.StatusNotFound, errorResponse{Error: "not_found", Message: "resource not found"}},
+		{"GET", "/users/1/nope", "", http.StatusNotFound, errorResponse{Error: "not_found", Message: "resource not found", Details: map[string]any{"path": "/users/1/nope"}}},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, tt.method, tt.path, tt.body)
+		if res.StatusCode != tt.wantStatus {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, res.StatusCode, tt.wantStatus)
+			continue
+		}
+		got := decodeBody[errorResponse](t, body)
+		got.RequestID, got.CorrelationID = "", ""
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s = %+v, want %+v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSoftDeleteHidesAndRestores(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(func(c *Config) { c.SoftDelete = true }), WithServerClock(clock.Now))
+	doRequest(t, ts, "POST", "/users", `{"name":"Alice Doe"}`)
+	doRequest(t, ts, "POST", "/users", `{"name":"Budi Santoso"}`)
+
+	clock.Advance(time.Minute)
+	if res, body := doRequest(t, ts, "DELETE", "/users/1", ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("delete: status %d: %s", res.StatusCode, body)
+	}
+
+	// default: tersembunyi dari get, list dan total
+	if res, _ := doRequest(t, ts, "GET", "/users/1", ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET deleted user: status %d, want 404", res.StatusCode)
+	}
+	type listEnvelope struct {
+		Data []struct {
+			ID        UserID     `json:"id"`
+			DeletedAt *time.Time `json:"deletedAt"`
+		} `json:"data"`
+		Meta struct {
+			Total int `json:"total"`
+		} `json:"meta"`
+	}
+	_, body := doRequest(t, ts, "GET", "/users", "")
+	if list := decodeBody[listEnvelope](t, body); len(list.Data) != 1 || list.Data[0].ID != "2" || list.Meta.Total != 1 {
+		t.Fatalf("GET /users = %s", body)
+	}
+
+	// ?includeDeleted=true menampilkannya dengan deletedAt
+	_, body = doRequest(t, ts, "GET", "/users?includeDeleted=true", "")
+	list := decodeBody[listEnvelope](t, body)
+	if len(list.Data) != 2 || list.Meta.Total != 2 {
+		t.Fatalf("GET /users?includeDeleted=true = %s", body)
+	}
+	res, body := doRequest(t, ts, "GET", "/users/1?includeDeleted=true", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET deleted user with includeDeleted: status %d", res.StatusCode)
+	}
+	if got := decodeBody[userEnvelope](t, body).Data.DeletedAt; got == nil || !got.Equal(testEpoch.Add(time.Minute)) {
+		t.Fatalf("deletedAt = %v, want %v", got, testEpoch.Add(time.Minute))
+	}
+	if res, _ := doRequest(t, ts, "DELETE", "/users/1", ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("second delete: status %d, want 404", res.StatusCode)
+	}
+
+	res, body = doRequest(t, ts, "POST", "/users/1/restore", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("restore: status %d: %s", res.StatusCode, body)
+	}
+	if got := decodeBody[userEnvelope](t, body).Data; got.DeletedAt != nil || got.Name != "Alice Doe" {
+		t.Fatalf("restored user = %s", body)
+	}
+	if res, _ := doRequest(t, ts, "GET", "/users/1", ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("GET restored user: status %d", res.StatusCode)
+	}
+	if res, _ := doRequest(t, ts, "POST", "/users/1/restore", ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("restore of a live user: status %d, want 404", res.StatusCode)
+	}
+}
+
+func TestUserRoutesMethodNotAllowed(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Alice Doe"}`)
+
+	tests := []struct {
+		pattern, method, path, allow string
+	}{
+		{"/users", "PATCH", "/users", "GET, POST, HEAD, OPTIONS"},
+		{"/users/recent", "POST", "/users/recent", "GET, HEAD, OPTIONS"},
+		{"/users/exists", "DELETE", "/users/exists", "GET, HEAD, OPTIONS"},
+		{"/users/import", "GET", "/users/import", "POST, OPTIONS"},
+		{"/users/{id}", "POST", "/users/1", "GET, PUT, PATCH, DELETE, HEAD, OPTIONS"},
+		{"/users/{id}/profile", "PUT", "/users/1/profile", "GET, PATCH, HEAD, OPTIONS"},
+		{"/users/{id}/restore", "GET", "/users/1/restore", "POST, OPTIONS"},
+		{"/users/{id}/password", "GET", "/users/1/password", "POST, OPTIONS"},
+		{"/users/{id}/orders/{orderId}", "POST", "/users/1/orders/7", "GET, HEAD, OPTIONS"},
+	}
+	covered := map[string]bool{}
+	for _, tt := range tests {
+		covered[tt.pattern] = true
+		res, body := doRequest(t, ts, tt.method, tt.path, "")
+		if res.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: status %d, want 405", tt.method, tt.path, res.StatusCode)
+			continue
+		}
+		if got := res.Header.Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s: Allow %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+		if !strings.Contains(body, `"method":"`+tt.method+`"`) {
+			t.Errorf("%s %s: body %s", tt.method, tt.path, body)
+		}
+	}
+	for _, rt := range routeTable {
+		if strings.HasPrefix(rt.pattern, "/users") && !covered[rt.pattern] {
+			t.Errorf("%s has no 405 case", rt.pattern)
+		}
+	}
+
+	// method dicek sebelum keberadaan user
+	if res, _ := doRequest(t, ts, "POST", "/users/99", ""); res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /users/99: status %d, want 405", res.StatusCode)
+	}
+}
+
+func TestCreateUserLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		// want = Location persis per create; "{id}" diganti ID dari body
+		want []string
+	}{
+		{"int ids", nil, []string{"/users/1", "/users/2"}},
+		{"uuid ids", func(c *Config) { c.IDMode = IDModeUUID }, []string{"/users/{id}", "/users/{id}"}},
+		// BasePath = prefix dari reverse proxy, route server sendiri tetap /users
+		{"base path", func(c *Config) { c.BasePath = "/api/v1" }, []string{"/api/v1/users/1", "/api/v1/users/2"}},
+		{"base path with slash", func(c *Config) { c.BasePath = "/api/" }, []string{"/api/users/1", "/api/users/2"}},
+		{"base path and uuid", func(c *Config) { c.BasePath, c.IDMode = "/api", IDModeUUID }, []string{"/api/users/{id}", "/api/users/{id}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, testConfig(tt.mutate))
+			for i, want := range tt.want {
+				res, body := doRequest(t, ts, "POST", "/users", fmt.Sprintf(`{"name":"user %d"}`, i))
+				if res.StatusCode != http.StatusCreated {
+					t.Fatalf("status %d, want 201: %s", res.StatusCode, body)
+				}
+				created := decodeBody[struct {
+					Data struct {
+						ID    UserID          `json:"id"`
+						Links map[string]link `json:"_links"`
+					} `json:"data"`
+				}](t, body).Data
+				want = strings.Replace(want, "{id}", string(created.ID), 1)
+				if got := res.Header.Get("Location"); got != want {
+					t.Fatalf("create %d: Location %q, want %q", i, got, want)
+				}
+				if self := created.Links["self"].Href; self != want {
+					t.Fatalf("create %d: _links.self %q, want %q", i, self, want)
+				}
+			}
+		})
+	}
+}
+
+// Location menunjuk resource yang bisa langsung di-GET
+func TestCreateUserLocationResolves(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	res, body := doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+	created := decodeBody[userEnvelope](t, body).Data
+
+	res, body = doRequest(t, ts, "GET", res.Header.Get("Location"), "")
+	if got := decodeBody[userEnvelope](t, body).Data; res.StatusCode != http.StatusOK || got.ID != created.ID || got.Name != "Ada" {
+		t.Fatalf("GET Location: status %d: %s", res.StatusCode, body)
+	}
+
+	// hanya 201 yang membawa Location
+	if res, _ = doRequest(t, ts, "POST", "/users", `{"name":" "}`); res.StatusCode != http.StatusBadRequest || res.Header.Get("Location") != "" {
+		t.Fatalf("invalid create: status %d, Location %q", res.StatusCode, res.Header.Get("Location"))
+	}
+}
+
+func TestIfMatchOnUpdates(t *testing.T) {
+	tests := []struct {
+		method, body string
+	}{
+		{"PUT", `{"name":"Ada Lovelace"}`},
+		{"PATCH", `{"name":"Ada Lovelace"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			ts := newTestServer(t, testConfig(nil))
+			doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+			res, _ := doRequest(t, ts, "GET", "/users/1", "")
+			etag := res.Header.Get("ETag")
+			if etag == "" {
+				t.Fatal("GET has no ETag")
+			}
+
+			// cocok = sukses, ETag baru dikirim balik
+			res, body := doRequest(t, ts, tt.method, "/users/1", tt.body, "If-Match", etag)
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("matching If-Match: status %d: %s", res.StatusCode, body)
+			}
+			next := res.Header.Get("ETag")
+			if next == "" || next == etag {
+				t.Fatalf("ETag after update %q, was %q", next, etag)
+			}
+
+			// ETag lama = 412, user tidak berubah
+			res, body = doRequest(t, ts, tt.method, "/users/1", `{"name":"Lost Update"}`, "If-Match", etag)
+			got := decodeBody[errorResponse](t, body)
+			if res.StatusCode != http.StatusPreconditionFailed || got.Error != "precondition_failed" {
+				t.Fatalf("stale If-Match: status %d: %s", res.StatusCode, body)
+			}
+			if details, _ := got.Details.(map[string]any); details["etag"] != next {
+				t.Errorf("412 details %v, want current etag %s", got.Details, next)
+			}
+			_, body = doRequest(t, ts, "GET", "/users/1", "")
+			if name := decodeBody[userEnvelope](t, body).Data.Name; name != "Ada Lovelace" {
+				t.Fatalf("name after 412 = %q", name)
+			}
+
+			// tanpa syarat: header kosong atau "*"
+			for _, h := range [][]string{nil, {"If-Match", "*"}} {
+				if res, body := doRequest(t, ts, tt.method, "/users/1", tt.body, h...); res.StatusCode != http.StatusOK {
+					t.Fatalf("If-Match %v: status %d: %s", h, res.StatusCode, body)
+				}
+			}
+		})
+	}
+}
+
+// bentuk If-Match yang tidak dikenal tidak pernah cocok
+func TestIfMatchMalformed(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+	res, _ := doRequest(t, ts, "GET", "/users/1", "")
+	etag := res.Header.Get("ETag")
+
+	for _, v := range []string{"W/" + etag, strings.Trim(etag, `"`), etag + `, "99"`, `"0"`, `"abc"`} {
+		res, body := doRequest(t, ts, "PUT", "/users/1", `{"name":"X"}`, "If-Match", v)
+		if res.StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("If-Match %s: status %d: %s", v, res.StatusCode, body)
+		}
+	}
+	if res, _ := doRequest(t, ts, "PUT", "/users/99", `{"name":"X"}`, "If-Match", etag); res.StatusCode != http.StatusNotFound {
+		t.Errorf("missing user: status %d, want 404", res.StatusCode)
+	}
+}
+
+// getManyEnvelope = body GET /users?ids=
+type getManyEnvelope struct {
+	Data []struct {
+		ID UserID `json:"id"`
+	} `json:"data"`
+	Meta struct {
+		Count   int      `json:"count"`
+		Missing []UserID `json:"missing"`
+	} `json:"meta"`
+}
+
+func TestGetManyUsers(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.SoftDelete = true }))
+	for _, name := range []string{"a", "b", "c", "d"} {
+		doRequest(t, ts, "POST", "/users", `{"name":"`+name+`"}`)
+	}
+	doRequest(t, ts, "DELETE", "/users/4", "")
+
+	tests := []struct {
+		query       string
+		wantFound   []UserID
+		wantMissing []UserID
+	}{
+		{"ids=1,2,5", []UserID{"1", "2"}, []UserID{"5"}},
+		// urutan data = urutan ids, duplikat hanya sekali
+		{"ids=3,1,3,1", []UserID{"3", "1"}, []UserID{}},
+		{"ids=7,8", []UserID{}, []UserID{"7", "8"}},
+		// soft-deleted = missing kecuali includeDeleted
+		{"ids=4,2,9", []UserID{"2"}, []UserID{"4", "9"}},
+		{"ids=4,2,9&includeDeleted=true", []UserID{"4", "2"}, []UserID{"9"}},
+		{"ids=%202%20", []UserID{"2"}, []UserID{}},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", "/users?"+tt.query, "")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", tt.query, res.StatusCode, body)
+		}
+		got := decodeBody[getManyEnvelope](t, body)
+		found := []UserID{}
+		for _, u := range got.Data {
+			found = append(found, u.ID)
+		}
+		slices.Sort(got.Meta.Missing)
+		if !slices.Equal(found, tt.wantFound) || !slices.Equal(got.Meta.Missing, tt.wantMissing) || got.Meta.Count != len(tt.wantFound) {
+			t.Errorf("%s: found %v missing %v (count %d), want %v and %v", tt.query, found, got.Meta.Missing, got.Meta.Count, tt.wantFound, tt.wantMissing)
+		}
+	}
+}
+
+func TestGetManyUsersInvalidIDs(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	many := make([]string, maxListLimit+1)
+	for i := range many {
+		many[i] = strconv.Itoa(i + 1)
+	}
+
+	tests := []struct {
+		query string
+		want  []string // potongan tiap details
+	}{
+		{"ids=1,0,-3", []string{"ids[1]:", "ids[2]:"}},
+		{"ids=1,abc", []string{"ids[1]:"}},
+		{"ids=", []string{"ids[0]:"}},
+		{"ids=1,,2", []string{"ids[1]:"}},
+		{"ids=" + strings.Join(many, ","), []string{"at most 100 distinct ids"}},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", "/users?"+tt.query, "")
+		got := decodeBody[errorResponse](t, body)
+		details, _ := got.Details.([]any)
+		if res.StatusCode != http.StatusBadRequest || got.Error != "validation_failed" || len(details) != len(tt.want) {
+			t.Errorf("%.40s: status %d: %s", tt.query, res.StatusCode, body)
+			continue
+		}
+		for i, want := range tt.want {
+			if d, _ := details[i].(string); !strings.Contains(d, want) {
+				t.Errorf("%.40s: details[%d] = %q, want %q", tt.query, i, d, want)
+			}
+		}
+	}
+}
+
+// recentEnvelope = body GET /users/recent
+type recentEnvelope struct {
+	Data []struct {
+		ID UserID `json:"id"`
+	} `json:"data"`
+	Meta struct {
+		Count int    `json:"count"`
+		Limit int    `json:"limit"`
+		Since string `json:"since"`
+	} `json:"meta"`
+}
+
+// TestRecentUsers: jendela default dihitung dari jam server, bukan time.Now
+func TestRecentUsers(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(nil), WithServerClock(clock.Now))
+	for _, name := range []string{"a", "b", "c"} {
+		doRequest(t, ts, "POST", "/users", `{"name":"`+name+`"}`)
+		clock.Advance(3 * time.Minute)
+	}
+	// sekarang = epoch+9m: a (epoch), b (+3m), c (+6m)
+
+	tests := []struct {
+		query     string
+		wantIDs   []UserID
+		wantSince string
+	}{
+		// default 5 menit terakhir: hanya c
+		{"", []UserID{"3"}, testEpoch.Add(4 * time.Minute).Format(time.RFC3339Nano)},
+		// since eksklusif, terbaru dulu
+		{"?since=2024-01-02T03:04:05Z", []UserID{"3", "2"}, "2024-01-02T03:04:05Z"},
+		{"?since=2024-01-01T00:00:00Z", []UserID{"3", "2", "1"}, "2024-01-01T00:00:00Z"},
+		{"?since=2024-01-01T00:00:00Z&limit=1", []UserID{"3"}, "2024-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", "/users/recent"+tt.query, "")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", tt.query, res.StatusCode, body)
+		}
+		got := decodeBody[recentEnvelope](t, body)
+		ids := []UserID{}
+		for _, u := range got.Data {
+			ids = append(ids, u.ID)
+		}
+		if !slices.Equal(ids, tt.wantIDs) || got.Meta.Since != tt.wantSince {
+			t.Errorf("%s: ids %v since %s, want %v since %s", tt.query, ids, got.Meta.Since, tt.wantIDs, tt.wantSince)
+		}
+	}
+
+	// jendela default ikut jam server
+	clock.Advance(10 * time.Minute)
+	_, body := doRequest(t, ts, "GET", "/users/recent", "")
+	if got := decodeBody[recentEnvelope](t, body); got.Meta.Count != 0 {
+		t.Fatalf("after advancing the clock: %s", body)
+	}
+
+	for _, q := range []string{"?since=yesterday", "?since=2024-01-02", "?since=2024-01-02T03:04:05Z&limit=0"} {
+		res, body := doRequest(t, ts, "GET", "/users/recent"+q, "")
+		if got := decodeBody[errorResponse](t, body); res.StatusCode != http.StatusBadRequest || got.Error != "validation_failed" {
+			t.Errorf("%s: status %d: %s", q, res.StatusCode, body)
+		}
+	}
+}
+
+// inactiveSince dihitung dari jam server, sama dengan lastActiveAt
+func TestListInactiveSinceUsesServerClock(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(nil), WithServerClock(clock.Now))
+	doRequest(t, ts, "POST", "/users", `{"name":"old"}`)
+	clock.Advance(2 * time.Hour)
+	doRequest(t, ts, "POST", "/users", `{"name":"new"}`)
+	clock.Advance(30 * time.Minute)
+
+	for _, tt := range []struct{ query, want string }{
+		{"1h", "1"},
+		{"3h", ""},
+		{"10m", "1,2"},
+	} {
+		_, body := doRequest(t, ts, "GET", "/users?inactiveSince="+tt.query, "")
+		var ids []string
+		for _, u := range decodeBody[userListEnvelope](t, body).Data {
+			ids = append(ids, string(u.ID))
+		}
+		if got := strings.Join(ids, ","); got != tt.want {
+			t.Errorf("inactiveSince=%s: ids %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestListInactiveSinceInvalid(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+
+	const format = "inactiveSince must be a positive duration such as 30d or 12h"
+	tests := []struct{ query, want string }{
+		{"abc", format},
+		{"0d", format},
+		{"-5h", format},
+		{"36501d", "inactiveSince must be at most 36500d"},
+		// dulu overflow jadi durasi negatif dan 200 dengan list kosong
+		{"300000d", "inactiveSince must be at most 36500d"},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", "/users?inactiveSince="+tt.query, "")
+		got := decodeBody[errorResponse](t, body)
+		if res.StatusCode != http.StatusBadRequest || got.Error != "validation_failed" || got.RequestID == "" {
+			t.Errorf("inactiveSince=%s: status %d: %s", tt.query, res.StatusCode, body)
+			continue
+		}
+		if details, _ := got.Details.([]any); len(details) != 1 || details[0] != tt.want {
+			t.Errorf("inactiveSince=%s: details %v, want %q", tt.query, got.Details, tt.want)
+		}
+	}
+	// batas atas sendiri masih diterima
+	if res, body := doRequest(t, ts, "GET", "/users?inactiveSince=36500d", ""); res.StatusCode != http.StatusOK {
+		t.Errorf("inactiveSince=36500d: status %d: %s", res.StatusCode, body)
+	}
+}
+
+// TestListUsersNDJSON: export NDJSON dibaca ulang baris per baris, tanpa
+// ?limit semua user (lebih dari satu flush)
+func TestListUsersNDJSON(t *testing.T) {
+	const n = ndjsonFlushEvery + 50
+	ts := newTestServer(t, testConfig(func(c *Config) { c.Seed = n }))
+
+	for _, tt := range []struct {
+		path   string
+		header []string
+		want   int
+	}{
+		{"/users?format=ndjson", nil, n},
+		{"/users", []string{"Accept", "application/json;q=0.5, application/x-ndjson"}, n},
+		{"/users?format=ndjson&limit=5&offset=148", nil, 2},
+	} {
+		res, body := doRequest(t, ts, "GET", tt.path, "", tt.header...)
+		if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != ndjsonContentType {
+			t.Fatalf("%s: status %d, Content-Type %q", tt.path, res.StatusCode, res.Header.Get("Content-Type"))
+		}
+		if !strings.HasSuffix(body, "\n") {
+			t.Fatalf("%s: body does not end with a newline", tt.path)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+		if len(lines) != tt.want {
+			t.Fatalf("%s: %d lines, want %d", tt.path, len(lines), tt.want)
+		}
+		for _, line := range lines {
+			// tiap baris = satu user utuh, bukan envelope data/meta
+			u := decodeBody[struct {
+				ID    UserID                    `json:"id"`
+				Name  string                    `json:"name"`
+				Links map[string]map[string]any `json:"_links"`
+			}](t, line)
+			if u.Name != "user-"+string(u.ID) || u.Links["self"]["href"] != "/users/"+string(u.ID) {
+				t.Fatalf("%s: line %s", tt.path, line)
+			}
+		}
+	}
+}
+
+// hash password (dan password-nya sendiri) tidak pernah muncul di response mana pun
+func TestPasswordHashNeverInResponses(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.SoftDelete = true }))
+
+	requests := []struct{ method, path, body string }{
+		{"POST", "/users", `{"name":"Ada","password":"secret123"}`},
+		{"POST", "/users", `{"name":"Grace","password":"secret123"}`},
+		{"GET", "/users/1", ""},
+		{"GET", "/users", ""},
+		{"GET", "/users?format=ndjson", ""},
+		{"GET", "/users?ids=1,2", ""},
+		{"GET", "/users/recent?since=2000-01-01T00:00:00Z", ""},
+		{"POST", "/users/1/password", `{"currentPassword":"secret123","newPassword":"secret456"}`},
+		{"PUT", "/users/1", `{"name":"Ada Lovelace"}`},
+		{"PATCH", "/users/1", `{"name":"Ada L."}`},
+		{"GET", "/users/1/profile", ""},
+		{"DELETE", "/users/2", ""},
+		{"POST", "/users/2/restore", ""},
+		{"POST", "/batch", `{"operations":[{"method":"POST","path":"/users","body":{"name":"Dewi","password":"secret123"}},{"method":"PATCH","path":"/users/$1.id","body":{"name":"Dewi R."}}]}`},
+		{"GET", "/audit", ""},
+	}
+	for _, req := range requests {
+		res, body := doRequest(t, ts, req.method, req.path, req.body)
+		if res.StatusCode >= 400 {
+			t.Fatalf("%s %s: status %d: %s", req.method, req.path, res.StatusCode, body)
+		}
+		for _, leak := range []string{"$2a$", "$2b$", "asswordHash", "secret123", "secret456"} {
+			if strings.Contains(body, leak) {
+				t.Errorf("%s %s: response contains %q: %s", req.method, req.path, leak, body)
+			}
+		}
+	}
+}
+
+func TestNameExists(t *testing.T) {
+	ts := newTestServer(t, testConfig(func(c *Config) { c.SoftDelete = true }))
+	doRequest(t, ts, "POST", "/users", `{"name":"Alice Doe"}`)
+	doRequest(t, ts, "POST", "/users", `{"name":"Gone User"}`)
+	doRequest(t, ts, "DELETE", "/users/2", "")
+
+	type existsEnvelope struct {
+		Data struct {
+			Exists bool `json:"exists"`
+		} `json:"data"`
+	}
+	tests := []struct {
+		name, path string
+		wantStatus int
+		wantExists bool
+	}{
+		{"present", "/users/exists?name=Alice%20Doe", http.StatusOK, true},
+		{"present other case", "/users/exists?name=alice%20DOE", http.StatusOK, true},
+		{"absent", "/users/exists?name=Bob", http.StatusOK, false},
+		{"soft-deleted", "/users/exists?name=Gone%20User", http.StatusOK, false},
+		{"missing name", "/users/exists", http.StatusBadRequest, false},
+		{"empty name", "/users/exists?name=", http.StatusBadRequest, false},
+		{"blank name", "/users/exists?name=%20%20", http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		res, body := doRequest(t, ts, "GET", tt.path, "")
+		if res.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, res.StatusCode, tt.wantStatus, body)
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			got := decodeBody[errorResponse](t, body)
+			if got.Error != "validation_failed" || !reflect.DeepEqual(got.Details, []any{"name is required"}) {
+				t.Errorf("%s: body %s", tt.name, body)
+			}
+			continue
+		}
+		if got := decodeBody[existsEnvelope](t, body).Data.Exists; got != tt.wantExists {
+			t.Errorf("%s: exists %v, want %v", tt.name, got, tt.wantExists)
+		}
+	}
+
+	if res, _ := doRequest(t, ts, "POST", "/users/exists?name=Alice", ""); res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status %d, want 405", res.StatusCode)
+	}
+}
+
+// PATCH /users/{id} satu field: field lain dan profile tidak berubah
+func TestPatchUserSingleField(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	doRequest(t, ts, "POST", "/users", `{"name":"Ada","role":"admin"}`)
+	doRequest(t, ts, "PATCH", "/users/1/profile", `{"bio":"math"}`)
+
+	res, body := doRequest(t, ts, "PATCH", "/users/1", `{"role":"user"}`)
+	if got := decodeBody[userEnvelope](t, body).Data; res.StatusCode != http.StatusOK || got.Name != "Ada" || got.Role != RoleUser {
+		t.Fatalf("patch role: status %d: %s", res.StatusCode, body)
+	}
+	res, body = doRequest(t, ts, "PATCH", "/users/1", `{"name":"Ada Lovelace"}`)
+	if got := decodeBody[userEnvelope](t, body).Data; res.StatusCode != http.StatusOK || got.Name != "Ada Lovelace" || got.Role != RoleUser || got.Version != 4 {
+		t.Fatalf("patch name: status %d: %s", res.StatusCode, body)
+	}
+	if _, body = doRequest(t, ts, "GET", "/users/1/profile", ""); !strings.Contains(body, `"bio":"math"`) {
+		t.Fatalf("profile after user PATCH: %s", body)
+	}
+}
@@ -0,0 +1,251 @@
+// File: /users_import.go
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// maxImportBytes = batas ukuran file CSV (limitBody / -max-body-bytes tetap
+	// berlaku, yang lebih kecil menang)
+	maxImportBytes = 1 << 20
+	// maxImportRows: tiap baris dengan password = satu bcrypt (~70ms di cost
+	// 10), 200 baris masih muat di -request-timeout default 30s
+	maxImportRows = 200
+	// importDeadlineReserve = sisa waktu minimal sebelum deadline request
+	// untuk memulai baris berikutnya (ditambah 2x baris paling lambat)
+	importDeadlineReserve = 100 * time.Millisecond
+	// importFileField = nama field file untuk multipart/form-data
+	importFileField = "file"
+)
+
+// importColumns = kolom CSV yang dikenal; baris pertama wajib header dan
+// name wajib ada. Kolom sama dengan body POST /users.
+var importColumns = []string{"name", "role", "password"}
+
+// importRowError = satu baris yang dilewati beserta alasannya
+type importRowError struct {
+	Line    int    `json:"line"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// HandleImportUsers = POST /users/import: CSV (text/csv atau multipart field
+// "file") dibuat per baris lewat service. Baris tidak valid dilewati dan
+// dilaporkan di errors; file yang tidak bisa dibaca sama sekali = 400.
+// Baris tidak dibatalkan kalau import berhenti di tengah: sebelum deadline
+// request (-request-timeout) habis, sisa baris tidak diproses dan response
+// tetap 200 dengan incomplete=true dan resumeLine, supaya client tahu persis
+// baris mana yang sudah dibuat dan tidak pernah dapat 504 setelah commit.
+func (h *UsersHandler) HandleImportUsers(w http.ResponseWriter, r *http.Request) {
+	if !requireMethods(w, r, userImportMethods...) {
+		return
+	}
+	if err := authorizeRequest(r, actionWrite); err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	body, err := importBody(w, r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+	defer body.Close()
+
+	rows, lines, err := readImportCSV(body)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	created := []userResource{}
+	skipped := []importRowError{}
+	deadline, hasDeadline := r.Context().Deadline()
+	var slowest time.Duration
+	for i, row := range rows {
+		if hasDeadline && time.Until(deadline) < importDeadlineReserve+2*slowest {
+			requestLog(r.Context()).Warn("import stopped before request deadline", "processed", i, "remaining", len(rows)-i)
+			writeData(w, http.StatusOK, apiResponse{
+				"created":      len(created),
+				"skipped":      len(skipped),
+				"users":        created,
+				"errors":       skipped,
+				"incomplete":   true,
+				"notProcessed": len(rows) - i,
+				"resumeLine":   lines[i],
+			}, nil)
+			return
+		}
+
+		start := time.Now()
+		u, err := h.importRow(r, row)
+		slowest = max(slowest, time.Since(start))
+		if err != nil {
+			var appErr *AppError
+			if !errors.As(err, &appErr) || appErr.Status >= http.StatusInternalServerError {
+				// context batal / error server, baris sisanya tidak diproses
+				writeAppError(w, r, err)
+				return
+			}
+			skipped = append(skipped, importRowError{Line: lines[i], Error: appErr.Code, Message: appErr.Message, Details: appErr.Details})
+			continue
+		}
+		created = append(created, h.links.user(u))
+	}
+
+	writeData(w, http.StatusOK, apiResponse{
+		"created":    len(created),
+		"skipped":    len(skipped),
+		"users":      created,
+		"errors":     skipped,
+		"incomplete": false,
+	}, nil)
+}
+
+func (h *UsersHandler) importRow(r *http.Request, row map[string]string) (User, error) {
+	if msg, ok := row[""]; ok {
+		return User{}, validationError("invalid row", []string{msg})
+	}
+	role := Role(row["role"])
+	if role != "" {
+		if _, err := parseRole(string(role)); err != nil {
+			return User{}, validationError("invalid field", []string{err.Error()})
+		}
+	}
+	if err := authorizeSetRole(r, role != ""); err != nil {
+		return User{}, err
+	}
+	return h.svc.CreateUser(r.Context(), row["name"], role, row["password"])
+}
+
+// importBody = isi file CSV dari body text/csv atau field "file" multipart
+func importBody(w http.ResponseWriter, r *http.Request) (io.ReadCloser, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	ct := r.Header.Get("Content-Type")
+	mt, _, err := mime.ParseMediaType(ct)
+	switch {
+	case err == nil && mt == "text/csv":
+		return r.Body, nil
+	case err == nil && mt == "multipart/form-data":
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return nil, &AppError{Status: http.StatusBadRequest, Code: "invalid_body", Message: "invalid multipart body", Err: err}
+		}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil, validationError("invalid body", []string{"multipart field " + importFileField + " is required"})
+			}
+			if err != nil {
+				return nil, importReadError(err)
+			}
+			if part.FormName() == importFileField {
+				return part, nil
+			}
+			part.Close()
+		}
+	}
+	return nil, &AppError{
+		Status:  http.StatusUnsupportedMediaType,
+		Code:    "unsupported_media_type",
+		Message: "Content-Type must be text/csv or multipart/form-data",
+		Details: apiResponse{"contentType": ct},
+	}
+}
+
+// readImportCSV membaca seluruh file dulu (sudah dibatasi maxImportBytes),
+// jadi file rusak atau terlalu banyak baris ditolak sebelum ada user dibuat.
+// lines[i] = nomor baris file untuk rows[i].
+func readImportCSV(body io.Reader) (rows []map[string]string, lines []int, err error) {
+	cr := csv.NewReader(body)
+	cr.FieldsPerRecord = -1 // jumlah kolom salah = error per baris, bukan seluruh file
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, validationError("invalid csv", []string{"file is empty, a header row is required"})
+	}
+	if err != nil {
+		return nil, nil, importReadError(err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // BOM dari Excel
+	}
+	var details []string
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(col))
+		header[i] = col
+		if !slices.Contains(importColumns, col) {
+			details = append(details, fmt.Sprintf("unknown column %q, allowed: %s", truncatePath(col), strings.Join(importColumns, ", ")))
+		} else if slices.Index(header, col) < i {
+			details = append(details, fmt.Sprintf("duplicate column %q", col))
+		}
+	}
+	if !slices.Contains(header, "name") {
+		details = append(details, "column name is required")
+	}
+	if len(details) > 0 {
+		return nil, nil, validationError("invalid csv header", details)
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, importReadError(err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, nil, validationError("too many rows", []string{fmt.Sprintf("at most %d rows per import", maxImportRows)})
+		}
+		line, _ := cr.FieldPos(0)
+		row := make(map[string]string, len(header))
+		if len(record) != len(header) {
+			// ditandai dengan kunci kosong, dilaporkan importRow
+			row[""] = fmt.Sprintf("row has %d fields, header has %d", len(record), len(header))
+		}
+		for i, col := range header {
+			if i < len(record) {
+				row[col] = record[i]
+			}
+		}
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+	return rows, lines, nil
+}
+
+// importReadError: file terlalu besar = 413, CSV rusak = 400 invalid_csv
+func importReadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &AppError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Code:    "payload_too_large",
+			Message: "request body too large",
+			Details: apiResponse{"maxBytes": tooLarge.Limit},
+		}
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &AppError{
+			Status:  http.StatusBadRequest,
+			Code:    "invalid_csv",
+			Message: parseErr.Err.Error(),
+			Details: apiResponse{"line": parseErr.Line, "column": parseErr.Column},
+		}
+	}
+	return &AppError{Status: http.StatusBadRequest, Code: "invalid_body", Message: "could not read request body", Err: err}
+}
@@ -0,0 +1,150 @@
+// File: /users_import_test.go
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// importEnvelope = body sukses POST /users/import
+type importEnvelope struct {
+	Data struct {
+		Created    int              `json:"created"`
+		Skipped    int              `json:"skipped"`
+		Incomplete bool             `json:"incomplete"`
+		Users      []userResource   `json:"users"`
+		Errors     []importRowError `json:"errors"`
+	} `json:"data"`
+}
+
+var csvHeader = []string{"Content-Type", "text/csv"}
+
+func importedNames(users []userResource) []string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Name
+	}
+	return names
+}
+
+func TestImportUsersRows(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+	csv := "\ufeffName, Role ,password\n" + // BOM dan spasi di header
+		"Ada,admin,secret123\n" +
+		",user,\n" + // line 3: name kosong
+		"Grace,root,\n" + // line 4: role salah
+		"Linus\n" + // line 5: jumlah kolom salah
+		"\"Multi\nLine\",user,\n" + // line 6-7: satu record dua baris
+		"Dewi,user,short\n" + // line 8: password terlalu pendek
+		"Eko,,\n"
+
+	res, body := doRequest(t, ts, "POST", "/users/import", csv, csvHeader...)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status %d: %s", res.StatusCode, body)
+	}
+	got := decodeBody[importEnvelope](t, body).Data
+	if got.Created != 3 || got.Skipped != 4 || got.Incomplete {
+		t.Fatalf("counts: %s", body)
+	}
+	if names := importedNames(got.Users); !reflect.DeepEqual(names, []string{"Ada", "Multi\nLine", "Eko"}) {
+		t.Fatalf("created %q", names)
+	}
+	if got.Users[0].Role != RoleAdmin || got.Users[2].Role != RoleUser {
+		t.Fatalf("roles: %s", body)
+	}
+
+	wantLines := []int{3, 4, 5, 8}
+	for i, e := range got.Errors {
+		if i >= len(wantLines) || e.Line != wantLines[i] || e.Error != "validation_failed" {
+			t.Fatalf("errors[%d] = %+v, want line %v", i, e, wantLines)
+		}
+	}
+	if details, _ := got.Errors[2].Details.([]any); len(details) != 1 || details[0] != "row has 1 fields, header has 3" {
+		t.Fatalf("field count error %+v", got.Errors[2])
+	}
+
+	// user dari import bisa dibaca seperti user biasa
+	if _, body = doRequest(t, ts, "GET", "/users", ""); decodeBody[userListEnvelope](t, body).Meta.Total != 3 {
+		t.Fatalf("users after import: %s", body)
+	}
+}
+
+// error di level file: tidak ada satu pun user dibuat
+func TestImportUsersRejectedFiles(t *testing.T) {
+	var tooMany strings.Builder
+	tooMany.WriteString("name\n")
+	for i := range maxImportRows + 1 {
+		fmt.Fprintf(&tooMany, "user %d\n", i)
+	}
+	oversized := "name\n" + strings.Repeat("x", maxImportBytes) + "\n"
+
+	tests := []struct {
+		name, body  string
+		header      []string
+		wantStatus  int
+		wantCode    string
+		wantDetails any
+	}{
+		{"oversized", oversized, csvHeader, http.StatusRequestEntityTooLarge, "payload_too_large", map[string]any{"maxBytes": float64(maxImportBytes)}},
+		{"too many rows", tooMany.String(), csvHeader, http.StatusBadRequest, "validation_failed", []any{"at most 200 rows per import"}},
+		{"unknown column", "name,email\nAda,a@x\n", csvHeader, http.StatusBadRequest, "validation_failed",
+			[]any{`unknown column "email", allowed: name, role, password`}},
+		{"duplicate column", "name,role,name\nAda,user,Ada\n", csvHeader, http.StatusBadRequest, "validation_failed",
+			[]any{`duplicate column "name"`}},
+		{"missing name column", "role\nuser\n", csvHeader, http.StatusBadRequest, "validation_failed",
+			[]any{"column name is required"}},
+		{"empty file", "", csvHeader, http.StatusBadRequest, "validation_failed",
+			[]any{"file is empty, a header row is required"}},
+		{"broken quoting", "name\n\"Ada\n", csvHeader, http.StatusBadRequest, "invalid_csv", nil},
+		{"json body", `{"name":"Ada"}`, nil, http.StatusUnsupportedMediaType, "unsupported_media_type", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// -max-body-bytes lebih besar, jadi batas import sendiri yang kena
+			ts := newTestServer(t, testConfig(func(c *Config) { c.MaxBodyBytes = 4 << 20 }))
+			res, body := doRequest(t, ts, "POST", "/users/import", tt.body, tt.header...)
+			got := decodeBody[errorResponse](t, body)
+			if res.StatusCode != tt.wantStatus || got.Error != tt.wantCode {
+				t.Fatalf("status %d, want %d %s: %s", res.StatusCode, tt.wantStatus, tt.wantCode, body)
+			}
+			if tt.wantDetails != nil && !reflect.DeepEqual(got.Details, tt.wantDetails) {
+				t.Fatalf("details %#v, want %#v", got.Details, tt.wantDetails)
+			}
+			if _, body = doRequest(t, ts, "GET", "/users", ""); decodeBody[userListEnvelope](t, body).Meta.Total != 0 {
+				t.Fatalf("users created by rejected import: %s", body)
+			}
+		})
+	}
+}
+
+func TestImportUsersMultipart(t *testing.T) {
+	ts := newTestServer(t, testConfig(nil))
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("note", "ignored")
+	fw, _ := mw.CreateFormFile(importFileField, "users.csv")
+	_, _ = fw.Write([]byte("name\nAda\nGrace\n"))
+	_ = mw.Close()
+
+	res, body := doRequest(t, ts, "POST", "/users/import", buf.String(), "Content-Type", mw.FormDataContentType())
+	got := decodeBody[importEnvelope](t, body).Data
+	if res.StatusCode != http.StatusOK || got.Created != 2 || !reflect.DeepEqual(importedNames(got.Users), []string{"Ada", "Grace"}) {
+		t.Fatalf("status %d: %s", res.StatusCode, body)
+	}
+
+	// multipart tanpa field file
+	buf.Reset()
+	mw = multipart.NewWriter(&buf)
+	_ = mw.WriteField("note", "no file")
+	_ = mw.Close()
+	res, body = doRequest(t, ts, "POST", "/users/import", buf.String(), "Content-Type", mw.FormDataContentType())
+	if res.StatusCode != http.StatusBadRequest || !strings.Contains(body, "multipart field file is required") {
+		t.Fatalf("without file: status %d: %s", res.StatusCode, body)
+	}
+}
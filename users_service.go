@@ -2,56 +2,400 @@
 package main
 
 import (
-	"net/http"
+	"context"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
+// activityThrottle: RecordActivity menulis ke store paling banyak
+// sekali per user dalam jendela ini, supaya write lock tidak dibanjiri.
+const activityThrottle = time.Minute
+
+// UserService: semua method menerima ctx dari request dan berhenti lebih awal
+// (mengembalikan ctx.Err()) kalau client sudah pergi, lihat writeAppError.
 type UserService struct {
 	store *UserStore
+	// softDelete: DeleteUser hanya mengisi DeletedAt
+	softDelete bool
+	// audit mencatat create/update/delete yang berhasil
+	audit *AuditLog
+	// passwordCost = cost bcrypt untuk password baru (-bcrypt-cost)
+	passwordCost int
+
+	activityMu sync.Mutex
+	// lastTouch: kapan terakhir Touch ke store per user
+	lastTouch map[UserID]time.Time
+	lastSweep time.Time
+}
+
+func NewUserService(store *UserStore, softDelete bool, audit *AuditLog) *UserService {
+	return &UserService{
+		store:        store,
+		softDelete:   softDelete,
+		audit:        audit,
+		passwordCost: bcrypt.DefaultCost,
+		lastTouch:    make(map[UserID]time.Time),
+	}
 }
 
-func NewUserService(store *UserStore) *UserService {
-	return &UserService{store: store}
+// Now = jam store (WithClock / WithServerClock), dipakai semua perhitungan
+// "sekarang" supaya konsisten dengan CreatedAt dan LastActiveAt
+func (s *UserService) Now() time.Time {
+	return s.store.now().UTC()
 }
 
-func (s *UserService) CreateUser(name string) (User, error) {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return User{}, &AppError{
-			Status:  http.StatusBadRequest,
-			Code:    "validation_failed",
-			Message: "missing required fields",
-			Details: []string{"name is required"},
+// RecordActivity dipanggil untuk request terautentikasi milik user id
+// (lihat resolveCaller). Aktivitas dalam activityThrottle sejak tulis
+// terakhir diabaikan. Waktu dari jam store, sama dengan mutasi.
+func (s *UserService) RecordActivity(id UserID) {
+	now := s.Now()
+
+	s.activityMu.Lock()
+	if last, ok := s.lastTouch[id]; ok && now.Sub(last) < activityThrottle {
+		s.activityMu.Unlock()
+		return
+	}
+	s.lastTouch[id] = now
+
+	// bersihkan entry yang sudah lewat jendela, paling sering sekali per jendela
+	if now.Sub(s.lastSweep) >= activityThrottle {
+		for uid, last := range s.lastTouch {
+			if now.Sub(last) >= activityThrottle {
+				delete(s.lastTouch, uid)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.activityMu.Unlock()
+
+	s.store.Touch(id, now)
+}
+
+// CreateUser: role kosong = RoleUser, password kosong = user tanpa password
+func (s *UserService) CreateUser(ctx context.Context, name string, role Role, password string) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	name, err := validateUserName(name)
+	if err != nil {
+		return User{}, err
+	}
+
+	var hash []byte
+	if password != "" {
+		if err := validatePassword("password", password); err != nil {
+			return User{}, err
+		}
+		if hash, err = hashPassword(password, s.passwordCost); err != nil {
+			return User{}, err
 		}
 	}
 
-	u := s.store.Create(name)
+	if role == "" {
+		role = RoleUser
+	}
+	u := s.store.Create(name, role, hash)
+	s.audit.Record(ctx, auditUserCreate, u.ID)
 	return u, nil
 }
 
-func (s *UserService) GetUser(id int) (User, error) {
-	u, ok := s.store.Get(id)
+// UpdateUser: ifVersion != 0 = If-Match, versi lain menghasilkan 412
+func (s *UserService) UpdateUser(ctx context.Context, id UserID, name string, role *Role, ifVersion int) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	name, err := validateUserName(name)
+	if err != nil {
+		return User{}, err
+	}
+
+	// user yang sudah di-soft-delete tidak boleh diubah
+	if _, err := s.GetUser(ctx, id, false); err != nil {
+		return User{}, err
+	}
+
+	u, ok := s.store.Update(id, name, role, ifVersion)
 	if !ok {
-		return User{}, &AppError{
-			Status:  http.StatusNotFound,
-			Code:    "not_found",
-			Message: "resource not found",
+		return User{}, versionError(u)
+	}
+	s.audit.Record(ctx, auditUserUpdate, id)
+	return u, nil
+}
+
+func (s *UserService) PatchUser(ctx context.Context, id UserID, name *string, role *Role, ifVersion int) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	if name != nil {
+		n, err := validateUserName(*name)
+		if err != nil {
+			return User{}, err
 		}
+		name = &n
+	}
+
+	if _, err := s.GetUser(ctx, id, false); err != nil {
+		return User{}, err
+	}
+
+	u, ok := s.store.Patch(id, name, role, ifVersion)
+	if !ok {
+		return User{}, versionError(u)
+	}
+	s.audit.Record(ctx, auditUserPatch, id)
+	return u, nil
+}
+
+// PatchProfile mengubah field profile yang diisi patch
+func (s *UserService) PatchProfile(ctx context.Context, id UserID, patch ProfilePatch) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+	if err := patch.Validate(); err != nil {
+		return User{}, err
+	}
+
+	if _, err := s.GetUser(ctx, id, false); err != nil {
+		return User{}, err
+	}
+
+	u, ok := s.store.PatchProfile(id, patch)
+	if !ok {
+		return User{}, NewNotFound("resource not found")
+	}
+	s.audit.Record(ctx, auditUserProfile, id)
+	return u, nil
+}
+
+// ChangePassword mengganti password setelah password lama dicek.
+// User yang belum punya password boleh langsung mengisi (current diabaikan).
+func (s *UserService) ChangePassword(ctx context.Context, id UserID, current, next string) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+	if err := validatePassword("newPassword", next); err != nil {
+		return User{}, err
+	}
+
+	u, err := s.GetUser(ctx, id, false)
+	if err != nil {
+		return User{}, err
+	}
+	if len(u.PasswordHash) > 0 && bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(current)) != nil {
+		return User{}, errInvalidPassword
+	}
+
+	hash, err := hashPassword(next, s.passwordCost)
+	if err != nil {
+		return User{}, err
+	}
+	// Version dari pembacaan di atas: perubahan lain di antaranya = 412
+	u, ok := s.store.SetPassword(id, hash, u.Version)
+	if !ok {
+		return User{}, versionError(u)
+	}
+	s.audit.Record(ctx, auditUserPassword, id)
+	return u, nil
+}
+
+// versionError menerjemahkan ok=false dari store.Update/Patch
+func versionError(current User) error {
+	if current.ID == "" {
+		return NewNotFound("resource not found")
+	}
+	e := NewPreconditionFailed("user was modified, fetch it again and retry")
+	e.Details = apiResponse{"etag": current.ETag(), "version": current.Version}
+	return e
+}
+
+func validateUserName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", validationError("missing required fields", []string{"name is required"})
+	}
+	return name, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, id UserID, includeDeleted bool) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	u, ok := s.store.Get(id)
+	if !ok || (u.DeletedAt != nil && !includeDeleted) {
+		return User{}, NewNotFound("resource not found")
 	}
 	return u, nil
 }
 
-func (s *UserService) DeleteUser(id int) error {
-	if ok := s.store.Delete(id); !ok {
-		return &AppError{
-			Status:  http.StatusNotFound,
-			Code:    "not_found",
-			Message: "resource not found",
+// GetUsers = GetUser untuk banyak id; user soft-deleted dianggap missing
+// kecuali includeDeleted
+func (s *UserService) GetUsers(ctx context.Context, ids []UserID, includeDeleted bool) ([]User, []UserID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	found, missing := s.store.GetMany(ids)
+	visible := found[:0]
+	for _, u := range found {
+		if u.DeletedAt != nil && !includeDeleted {
+			missing = append(missing, u.ID)
+			continue
 		}
+		visible = append(visible, u)
 	}
+	return visible, missing, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id UserID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	deleteFn := s.store.Delete
+	if s.softDelete {
+		deleteFn = s.store.SoftDelete
+	}
+
+	if ok := deleteFn(id); !ok {
+		return NewNotFound("resource not found")
+	}
+	s.audit.Record(ctx, auditUserDelete, id)
 	return nil
 }
 
-func (s *UserService) ListUsers() []User {
-	return s.store.List()
+func (s *UserService) RestoreUser(ctx context.Context, id UserID) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	u, ok := s.store.Restore(id)
+	if !ok {
+		return User{}, NewNotFound("resource not found")
+	}
+	s.audit.Record(ctx, auditUserRestore, id)
+	return u, nil
+}
+
+// field yang bisa dipakai di ?sort= dan filter GET /users
+var userListFields = listFields[User]{
+	text: func(u User) string { return u.Name },
+	sorts: map[string]func(a, b User) bool{
+		"":             func(a, b User) bool { return lessUserID(a.ID, b.ID) },
+		"id":           func(a, b User) bool { return lessUserID(a.ID, b.ID) },
+		"name":         func(a, b User) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) },
+		"createdAt":    func(a, b User) bool { return a.CreatedAt.Before(b.CreatedAt) },
+		"updatedAt":    func(a, b User) bool { return a.UpdatedAt.Before(b.UpdatedAt) },
+		"lastActiveAt": func(a, b User) bool { return a.LastActiveAt.Before(b.LastActiveAt) },
+	},
+	filters: map[string]func(User) string{
+		"name": func(u User) string { return u.Name },
+	},
+}
+
+// userListFilter = filter GET /users di luar ListOptions (q, sort, pagination)
+type userListFilter struct {
+	IncludeDeleted bool
+	// InactiveSince > 0: hanya user dengan LastActiveAt lebih lama dari ini
+	InactiveSince time.Duration
+	// CreatedAfter/CreatedBefore: rentang CreatedAt inklusif, zero = tanpa batas
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+func (f userListFilter) match(u User, cutoff time.Time) bool {
+	if u.DeletedAt != nil && !f.IncludeDeleted {
+		return false
+	}
+	if f.InactiveSince > 0 && !u.LastActiveAt.Before(cutoff) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && u.CreatedAt.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && u.CreatedAt.After(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// ListUsers mengembalikan satu halaman user, Page.Total = jumlah setelah filter.
+func (s *UserService) ListUsers(ctx context.Context, filter userListFilter, opts ListOptions) (Page[User], error) {
+	if err := ctx.Err(); err != nil {
+		return Page[User]{}, err
+	}
+
+	users := s.store.List()
+	cutoff := s.Now().Add(-filter.InactiveSince)
+
+	visible := users[:0]
+	for _, u := range users {
+		if filter.match(u, cutoff) {
+			visible = append(visible, u)
+		}
+	}
+	users = visible
+
+	// cek lagi sebelum sort: bagian paling mahal untuk list besar
+	if err := ctx.Err(); err != nil {
+		return Page[User]{}, err
+	}
+	return ApplyListOptions(users, opts, userListFields)
+}
+
+// CollectionVersion = versi seluruh user di store, untuk ETag GET /users
+func (s *UserService) CollectionVersion() (uint64, time.Time) {
+	return s.store.CollectionVersion()
+}
+
+// NameExists: nama sudah dipakai user lain (case-insensitive, spasi di tepi diabaikan)
+func (s *UserService) NameExists(ctx context.Context, name string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	name, err := validateUserName(name)
+	if err != nil {
+		return false, err
+	}
+	return s.store.ExistsByName(name), nil
+}
+
+// recentUsersLimit = batas hasil GET /users/recent
+const recentUsersLimit = maxListLimit
+
+// RecentUsers = user (belum dihapus) yang dibuat setelah since, terbaru dulu,
+// maksimal limit item. Page.Total = jumlah sebelum dipotong.
+func (s *UserService) RecentUsers(ctx context.Context, since time.Time, limit int) (Page[User], error) {
+	if err := ctx.Err(); err != nil {
+		return Page[User]{}, err
+	}
+
+	users := s.store.List()
+	recent := users[:0]
+	for _, u := range users {
+		if u.DeletedAt == nil && u.CreatedAt.After(since) {
+			recent = append(recent, u)
+		}
+	}
+	slices.SortFunc(recent, func(a, b User) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		switch {
+		case lessUserID(b.ID, a.ID):
+			return -1
+		case lessUserID(a.ID, b.ID):
+			return 1
+		}
+		return 0
+	})
+	return paginate(recent, min(limit, recentUsersLimit), 0)
 }
@@ -0,0 +1,190 @@
+// File: /users_service_test.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestService = UserService di atas store dengan jam clock
+func newTestService(clock *fakeClock) (*UserService, *UserStore) {
+	store := NewUserStore(IDModeInt, WithClock(clock.Now))
+	audit := NewAuditLog(10)
+	audit.now = clock.Now
+	svc := NewUserService(store, false, audit)
+	svc.passwordCost = 4
+	return svc, store
+}
+
+func TestRecordActivityThrottle(t *testing.T) {
+	clock := newFakeClock()
+	svc, store := newTestService(clock)
+	u, err := svc.CreateUser(context.Background(), "Alice Doe", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastActive := func() time.Time {
+		got, _ := store.Get(u.ID)
+		return got.LastActiveAt
+	}
+
+	// create sudah menulis LastActiveAt, tapi belum ada Touch: aktivitas pertama langsung tercatat
+	clock.Advance(10 * time.Second)
+	svc.RecordActivity(u.ID)
+	first := testEpoch.Add(10 * time.Second)
+	if !lastActive().Equal(first) {
+		t.Fatalf("after first activity: %v, want %v", lastActive(), first)
+	}
+
+	// masih dalam jendela: diabaikan
+	clock.Advance(activityThrottle - time.Second)
+	svc.RecordActivity(u.ID)
+	if !lastActive().Equal(first) {
+		t.Fatalf("inside throttle window: %v, want unchanged %v", lastActive(), first)
+	}
+
+	// tepat satu jendela sejak tulis terakhir: ditulis lagi
+	clock.Advance(time.Second)
+	svc.RecordActivity(u.ID)
+	if want := first.Add(activityThrottle); !lastActive().Equal(want) {
+		t.Fatalf("after throttle window: %v, want %v", lastActive(), want)
+	}
+
+	// aktivitas pasif tidak menyentuh Version / UpdatedAt (If-Match tetap berlaku)
+	got, _ := store.Get(u.ID)
+	if got.Version != 1 || !got.UpdatedAt.Equal(testEpoch) {
+		t.Fatalf("activity changed version %d / updatedAt %v", got.Version, got.UpdatedAt)
+	}
+	if _, err := svc.UpdateUser(context.Background(), u.ID, "Alice Updated", nil, 1); err != nil {
+		t.Fatalf("update with the pre-activity version: %v", err)
+	}
+}
+
+func TestRecordActivitySweepsThrottleCache(t *testing.T) {
+	clock := newFakeClock()
+	svc, store := newTestService(clock)
+	a := store.Create("Alice Doe", RoleUser, nil)
+	b := store.Create("Budi Santoso", RoleUser, nil)
+
+	svc.RecordActivity(a.ID)
+	clock.Advance(activityThrottle)
+	svc.RecordActivity(b.ID)
+
+	svc.activityMu.Lock()
+	defer svc.activityMu.Unlock()
+	if _, ok := svc.lastTouch[a.ID]; ok || len(svc.lastTouch) != 1 {
+		t.Fatalf("lastTouch = %v, want only user %s", svc.lastTouch, b.ID)
+	}
+}
+
+// TestAPIKeyUserRecordsActivity: request dengan key user=<id> mengisi
+// lastActiveAt user itu, key tanpa user= tidak
+func TestAPIKeyUserRecordsActivity(t *testing.T) {
+	clock := newFakeClock()
+	ts := newTestServer(t, testConfig(func(c *Config) {
+		c.APIKeys = []string{"admin-key ops", "alice-key alice role=user user=1"}
+	}), WithServerClock(clock.Now))
+	doRequest(t, ts, "POST", "/users", `{"name":"Alice Doe"}`, "X-API-Key", "admin-key")
+
+	clock.Advance(time.Hour)
+	_, body := doRequest(t, ts, "GET", "/users/1", "", "X-API-Key", "admin-key")
+	if got := decodeBody[userEnvelope](t, body).Data.LastActiveAt; !got.Equal(testEpoch) {
+		t.Fatalf("service key moved lastActiveAt to %v", got)
+	}
+
+	_, body = doRequest(t, ts, "GET", "/users/1", "", "X-API-Key", "alice-key")
+	if got := decodeBody[userEnvelope](t, body).Data.LastActiveAt; !got.Equal(testEpoch.Add(time.Hour)) {
+		t.Fatalf("lastActiveAt = %v, want %v", got, testEpoch.Add(time.Hour))
+	}
+}
+
+func TestAPIKeyUserMustBeAnID(t *testing.T) {
+	if _, err := loadAPIKeys([]string{"k1 user=abc"}, ""); err == nil {
+		t.Fatal("user=abc accepted")
+	}
+	set, err := loadAPIKeys([]string{"k1 some label role=user user=7"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k, _ := set.lookup("k1"); k.user != "7" || k.role != RoleUser || k.label != "some label" {
+		t.Fatalf("key = %+v", k)
+	}
+}
+
+// ctx yang sudah batal: tiap method berhenti sebelum menyentuh store
+func TestUserServiceRespectsCanceledContext(t *testing.T) {
+	svc, store := newTestService(newFakeClock())
+	u, err := svc.CreateUser(context.Background(), "Ada", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	name, role := "Grace", RoleAdmin
+
+	calls := map[string]func() error{
+		"CreateUser": func() error { _, err := svc.CreateUser(ctx, "Grace", "", "password123"); return err },
+		"UpdateUser": func() error { _, err := svc.UpdateUser(ctx, u.ID, "Grace", nil, 0); return err },
+		"PatchUser":  func() error { _, err := svc.PatchUser(ctx, u.ID, &name, &role, 0); return err },
+		"PatchProfile": func() error {
+			_, err := svc.PatchProfile(ctx, u.ID, ProfilePatch{})
+			return err
+		},
+		"ChangePassword": func() error { _, err := svc.ChangePassword(ctx, u.ID, "", "password123"); return err },
+		"GetUser":        func() error { _, err := svc.GetUser(ctx, u.ID, false); return err },
+		"GetUsers":       func() error { _, _, err := svc.GetUsers(ctx, []UserID{u.ID}, false); return err },
+		"DeleteUser":     func() error { return svc.DeleteUser(ctx, u.ID) },
+		"RestoreUser":    func() error { _, err := svc.RestoreUser(ctx, u.ID); return err },
+		"ListUsers":      func() error { _, err := svc.ListUsers(ctx, userListFilter{}, ListOptions{}); return err },
+		"NameExists":     func() error { _, err := svc.NameExists(ctx, "Ada"); return err },
+		"RecentUsers":    func() error { _, err := svc.RecentUsers(ctx, testEpoch, 10); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: error %v, want context.Canceled", name, err)
+		}
+	}
+
+	got, ok := store.Get(u.ID)
+	if !ok || got.Name != "Ada" || got.Version != u.Version || store.Count() != 1 {
+		t.Fatalf("store changed after canceled calls: %+v (count %d)", got, store.Count())
+	}
+	if events := svc.audit.Recent(); len(events) != 1 {
+		t.Fatalf("audit has %d events, want only the initial create", len(events))
+	}
+}
+
+func TestUserServiceDeadlineExceeded(t *testing.T) {
+	svc, _ := newTestService(newFakeClock())
+	ctx, cancel := context.WithDeadline(context.Background(), time.Unix(0, 0))
+	defer cancel()
+
+	if _, err := svc.CreateUser(ctx, "Ada", "", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// error ctx dari service dipetakan writeAppError: batal = 499, deadline = 504
+func TestWriteAppErrorContextErrors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{context.Canceled, statusClientClosedRequest, "client_closed_request"},
+		{fmt.Errorf("create: %w", context.Canceled), statusClientClosedRequest, "client_closed_request"},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, "request_timeout"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		writeAppError(rec, httptest.NewRequest("GET", "/users", nil), tt.err)
+		if got := decodeBody[errorResponse](t, rec.Body.String()); rec.Code != tt.status || got.Error != tt.code {
+			t.Errorf("%v: status %d body %s, want %d %s", tt.err, rec.Code, rec.Body, tt.status, tt.code)
+		}
+	}
+}
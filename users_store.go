@@ -2,44 +2,205 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type User struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        UserID     `json:"id"`
+	Name      string     `json:"name"`
+	Role      Role       `json:"role"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+
+	// LastActiveAt = mutasi terakhir atau aktivitas terakhir user (read-only).
+	// Touch hanya mengubah field ini, UpdatedAt tetap.
+	LastActiveAt time.Time `json:"lastActiveAt"`
+
+	// Version naik setiap kali user diubah (mulai 1), dipakai untuk If-Match
+	Version int `json:"version"`
+
+	// PasswordHash = hash bcrypt, kosong = belum punya password.
+	// Tidak pernah ikut JSON.
+	PasswordHash []byte `json:"-"`
+
+	// Profile = resource /users/{id}/profile, tidak ikut JSON User
+	Profile Profile `json:"-"`
+}
+
+// ETag = strong entity tag dari JSON user (lihat hashETag), jadi ikut
+// berubah kalau hanya LastActiveAt yang maju atau Version diulang dari 1
+func (u User) ETag() string {
+	return hashETag(u)
 }
 
 type UserStore struct {
 	mu     sync.RWMutex
+	idMode IDMode
 	nextID int
-	items  map[int]User
+	items  map[UserID]User
+	// now = sumber waktu CreatedAt/UpdatedAt/DeletedAt, default time.Now
+	now func() time.Time
+
+	// version/modified = versi seluruh koleksi (ETag / Last-Modified GET /users).
+	// Hanya diubah markChanged di bawah write lock, dibaca atomic tanpa lock.
+	version  atomic.Uint64
+	modified atomic.Int64 // unix nano
+}
+
+// UserStoreOption mengubah setting opsional NewUserStore
+type UserStoreOption func(*UserStore)
+
+// WithClock mengganti sumber waktu store, mis. jam tetap di test
+func WithClock(now func() time.Time) UserStoreOption {
+	return func(s *UserStore) {
+		s.now = now
+	}
 }
 
-func NewUserStore() *UserStore {
-	return &UserStore{
+func NewUserStore(idMode IDMode, opts ...UserStoreOption) *UserStore {
+	s := &UserStore{
+		idMode: idMode,
 		nextID: 1,
-		items:  make(map[int]User),
+		items:  make(map[UserID]User),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.modified.Store(s.now().UnixNano())
+	return s
+}
+
+// markChanged wajib dipanggil (dengan write lock) di setiap mutasi yang
+// terlihat di List
+func (s *UserStore) markChanged() {
+	s.version.Add(1)
+	s.modified.Store(s.now().UnixNano())
 }
 
-func (s *UserStore) Create(name string) User {
+// CollectionVersion = versi koleksi dan waktu mutasi terakhir. Dibaca
+// sebelum List, jadi isi List tidak pernah lebih lama dari versi ini.
+func (s *UserStore) CollectionVersion() (uint64, time.Time) {
+	return s.version.Load(), time.Unix(0, s.modified.Load()).UTC()
+}
+
+// newID harus dipanggil saat memegang write lock.
+// ID yang sudah dipakai di items dilewati, jadi nextID yang tertinggal
+// (mis. setelah restore data) tidak pernah menimpa user lain.
+func (s *UserStore) newID() UserID {
+	for {
+		var id UserID
+		if s.idMode == IDModeUUID {
+			id = UserID(newUUID())
+		} else {
+			id = UserID(strconv.Itoa(s.nextID))
+			s.nextID++
+		}
+		if _, taken := s.items[id]; !taken {
+			return id
+		}
+	}
+}
+
+// setNextIDFromItems menaikkan nextID melewati ID angka terbesar di items.
+// Wajib dipanggil (dengan write lock) setelah items diisi sekaligus.
+func (s *UserStore) setNextIDFromItems() {
+	for id := range s.items {
+		if n, ok := id.Int(); ok && n >= s.nextID {
+			s.nextID = n + 1
+		}
+	}
+}
+
+// Create: passwordHash boleh nil (user tanpa password)
+func (s *UserStore) Create(name string, role Role, passwordHash []byte) User {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	now := s.now().UTC()
 	u := User{
-		ID:        s.nextID,
-		Name:      name,
-		CreatedAt: time.Now().UTC(),
+		ID:           s.newID(),
+		Name:         name,
+		Role:         role,
+		PasswordHash: passwordHash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		LastActiveAt: now,
+		Version:      1,
 	}
 	s.items[u.ID] = u
-	s.nextID++
+	s.markChanged()
 	return u
 }
 
-func (s *UserStore) Get(id int) (User, bool) {
+// Update mengganti field yang bisa diubah (PUT); role nil = tidak diubah.
+// ifVersion != 0 = hanya kalau Version masih sama (If-Match). ok=false dengan
+// User kosong = tidak ada; dengan User terisi = versi tidak cocok (user saat ini).
+func (s *UserStore) Update(id UserID, name string, role *Role, ifVersion int) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.items[id]
+	if !ok {
+		return User{}, false
+	}
+	if ifVersion != 0 && u.Version != ifVersion {
+		return u, false
+	}
+	u.Name = name
+	if role != nil {
+		u.Role = *role
+	}
+	u.UpdatedAt = s.now().UTC()
+	u.LastActiveAt = u.UpdatedAt
+	u.Version++
+	s.items[id] = u
+	s.markChanged()
+	return u, true
+}
+
+// Patch hanya mengubah field yang tidak nil (PATCH).
+// Kalau tidak ada yang berubah, UpdatedAt dan Version tetap.
+// ifVersion dan hasil ok sama dengan Update.
+func (s *UserStore) Patch(id UserID, name *string, role *Role, ifVersion int) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.items[id]
+	if !ok {
+		return User{}, false
+	}
+	if ifVersion != 0 && u.Version != ifVersion {
+		return u, false
+	}
+	changed := false
+	if name != nil && *name != u.Name {
+		u.Name = *name
+		changed = true
+	}
+	if role != nil && *role != u.Role {
+		u.Role = *role
+		changed = true
+	}
+	if !changed {
+		return u, true
+	}
+	u.UpdatedAt = s.now().UTC()
+	u.LastActiveAt = u.UpdatedAt
+	u.Version++
+	s.items[id] = u
+	s.markChanged()
+	return u, true
+}
+
+func (s *UserStore) Get(id UserID) (User, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -47,7 +208,25 @@ func (s *UserStore) Get(id int) (User, bool) {
 	return u, ok
 }
 
-func (s *UserStore) Delete(id int) bool {
+// GetMany mengambil banyak user sekaligus dalam satu read lock.
+// found mengikuti urutan ids; ids yang tidak ada masuk missing.
+func (s *UserStore) GetMany(ids []UserID) (found []User, missing []UserID) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found = make([]User, 0, len(ids))
+	missing = []UserID{}
+	for _, id := range ids {
+		if u, ok := s.items[id]; ok {
+			found = append(found, u)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing
+}
+
+func (s *UserStore) Delete(id UserID) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -55,9 +234,206 @@ func (s *UserStore) Delete(id int) bool {
 		return false
 	}
 	delete(s.items, id)
+	s.markChanged()
+	return true
+}
+
+// Replace mengganti seluruh isi store sekaligus (dipakai fixtures).
+// ID dari users diabaikan, store memberi ID baru sesuai id mode.
+func (s *UserStore) Replace(users []User) []User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = make(map[UserID]User, len(users))
+	s.nextID = 1
+
+	out := make([]User, len(users))
+	for i, u := range users {
+		u.ID = s.newID()
+		if u.UpdatedAt.IsZero() {
+			u.UpdatedAt = u.CreatedAt
+		}
+		if u.LastActiveAt.IsZero() {
+			u.LastActiveAt = u.UpdatedAt
+		}
+		if u.Version == 0 {
+			u.Version = 1
+		}
+		if u.Role == "" {
+			u.Role = RoleUser
+		}
+		s.items[u.ID] = u
+		out[i] = u
+	}
+	s.markChanged()
+	return out
+}
+
+// Reset menghapus semua user dan mengulang ID dari 1, mengembalikan jumlah yang dihapus
+func (s *UserStore) Reset() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.items)
+	s.items = make(map[UserID]User)
+	s.nextID = 1
+	s.markChanged()
+	return n
+}
+
+// SetPassword mengganti hash password kalau Version masih ifVersion.
+// ok sama dengan Update: User kosong = tidak ada, terisi = versi tidak cocok.
+func (s *UserStore) SetPassword(id UserID, hash []byte, ifVersion int) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.items[id]
+	if !ok {
+		return User{}, false
+	}
+	if u.Version != ifVersion {
+		return u, false
+	}
+	u.PasswordHash = hash
+	u.UpdatedAt = s.now().UTC()
+	u.LastActiveAt = u.UpdatedAt
+	u.Version++
+	s.items[id] = u
+	s.markChanged()
+	return u, true
+}
+
+// PatchProfile mengubah profile lewat patch di bawah satu lock.
+// Profile yang tidak berubah tidak menaikkan Version.
+func (s *UserStore) PatchProfile(id UserID, patch ProfilePatch) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.items[id]
+	if !ok {
+		return User{}, false
+	}
+	p := patch.apply(u.Profile)
+	if p == u.Profile {
+		return u, true
+	}
+	u.Profile = p
+	u.UpdatedAt = s.now().UTC()
+	u.LastActiveAt = u.UpdatedAt
+	u.Version++
+	s.items[id] = u
+	s.markChanged()
+	return u, true
+}
+
+// SoftDelete menandai user sebagai terhapus tanpa membuang datanya
+func (s *UserStore) SoftDelete(id UserID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.items[id]
+	if !ok || u.DeletedAt != nil {
+		return false
+	}
+	now := s.now().UTC()
+	u.DeletedAt = &now
+	u.UpdatedAt = now
+	u.LastActiveAt = now
+	u.Version++
+	s.items[id] = u
+	s.markChanged()
 	return true
 }
 
+// Restore menghapus tanda DeletedAt
+func (s *UserStore) Restore(id UserID) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.items[id]
+	if !ok || u.DeletedAt == nil {
+		return User{}, false
+	}
+	u.DeletedAt = nil
+	u.UpdatedAt = s.now().UTC()
+	u.LastActiveAt = u.UpdatedAt
+	u.Version++
+	s.items[id] = u
+	s.markChanged()
+	return u, true
+}
+
+// Touch mencatat aktivitas pasif: hanya LastActiveAt yang maju
+func (s *UserStore) Touch(id UserID, at time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.items[id]
+	if !ok {
+		return false
+	}
+	if at.After(u.LastActiveAt) {
+		u.LastActiveAt = at
+		s.items[id] = u
+		s.markChanged()
+	}
+	return true
+}
+
+// userStoreSnapshot = salinan isi store untuk rollback batch
+type userStoreSnapshot struct {
+	nextID int
+	items  map[UserID]User
+}
+
+func (s *UserStore) snapshot() userStoreSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make(map[UserID]User, len(s.items))
+	for id, u := range s.items {
+		items[id] = u
+	}
+	return userStoreSnapshot{nextID: s.nextID, items: items}
+}
+
+func (s *UserStore) restoreSnapshot(snap userStoreSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID = snap.nextID
+	s.items = snap.items
+	s.setNextIDFromItems()
+	s.markChanged()
+}
+
+// ExistsByName: ada user (belum di-soft-delete) dengan nama ini, tanpa beda huruf besar/kecil
+func (s *UserStore) ExistsByName(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.items {
+		if u.DeletedAt == nil && strings.EqualFold(u.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count = jumlah user yang belum di-soft-delete
+func (s *UserStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := 0
+	for _, u := range s.items {
+		if u.DeletedAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
 func (s *UserStore) List() []User {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -68,3 +444,24 @@ func (s *UserStore) List() []User {
 	}
 	return out
 }
+
+// probeUserID tidak pernah dipakai user biasa (bukan angka, bukan UUID)
+const probeUserID UserID = "__probe__"
+
+// Probe melakukan create-read-delete sentinel dalam satu lock,
+// jadi tidak pernah terlihat oleh request lain dan tidak memakai nextID.
+func (s *UserStore) Probe(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("memory store: lock: %w", err)
+	}
+
+	s.items[probeUserID] = User{ID: probeUserID, Name: "probe"}
+	if _, ok := s.items[probeUserID]; !ok {
+		return fmt.Errorf("memory store: read: sentinel record missing")
+	}
+	delete(s.items, probeUserID)
+	return nil
+}
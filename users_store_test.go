@@ -0,0 +1,110 @@
+// File: /users_store_test.go
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// WithClock: CreatedAt/UpdatedAt persis dari jam yang disuntikkan, tanpa
+// WithClock dari time.Now
+func TestWithClock(t *testing.T) {
+	clock := newFakeClock()
+	store := NewUserStore(IDModeInt, WithClock(clock.Now))
+
+	u := store.Create("Ada", RoleUser, nil)
+	if u.CreatedAt != testEpoch || u.UpdatedAt != testEpoch {
+		t.Fatalf("createdAt %v, updatedAt %v, want %v", u.CreatedAt, u.UpdatedAt, testEpoch)
+	}
+	clock.Advance(90 * time.Second)
+	u, _ = store.Update(u.ID, "Ada Lovelace", nil, 0)
+	if u.CreatedAt != testEpoch || u.UpdatedAt != testEpoch.Add(90*time.Second) {
+		t.Fatalf("after Advance: createdAt %v, updatedAt %v", u.CreatedAt, u.UpdatedAt)
+	}
+
+	before := time.Now()
+	u = NewUserStore(IDModeInt).Create("Grace", RoleUser, nil)
+	if u.CreatedAt.Before(before.Truncate(time.Second)) || u.CreatedAt.After(time.Now()) || u.CreatedAt.Location() != time.UTC {
+		t.Fatalf("default clock: createdAt %v", u.CreatedAt)
+	}
+
+	// jam server yang sama sampai ke JSON response
+	ts := newTestServer(t, testConfig(nil), WithServerClock(clock.Now))
+	_, body := doRequest(t, ts, "POST", "/users", `{"name":"Ada"}`)
+	if got := decodeBody[userEnvelope](t, body).Data; got.CreatedAt != testEpoch.Add(90*time.Second) {
+		t.Fatalf("POST /users createdAt %v: %s", got.CreatedAt, body)
+	}
+}
+
+func TestUpdatedAtAdvancesOnWriteOnly(t *testing.T) {
+	clock := newFakeClock()
+	store := NewUserStore(IDModeInt, WithClock(clock.Now))
+
+	u := store.Create("Alice Doe", RoleUser, nil)
+	if !u.UpdatedAt.Equal(testEpoch) || !u.UpdatedAt.Equal(u.CreatedAt) {
+		t.Fatalf("created: createdAt %v, updatedAt %v, want both %v", u.CreatedAt, u.UpdatedAt, testEpoch)
+	}
+
+	// baca tidak mengubah UpdatedAt
+	clock.Advance(time.Minute)
+	got, _ := store.Get(u.ID)
+	store.List()
+	store.GetMany([]UserID{u.ID})
+	if again, _ := store.Get(u.ID); !again.UpdatedAt.Equal(got.UpdatedAt) || !got.UpdatedAt.Equal(testEpoch) {
+		t.Fatalf("read changed updatedAt: %v", again.UpdatedAt)
+	}
+
+	// PATCH tanpa perubahan juga tidak
+	same := "Alice Doe"
+	if p, _ := store.Patch(u.ID, &same, nil, 0); !p.UpdatedAt.Equal(testEpoch) || p.Version != 1 {
+		t.Fatalf("no-op patch: updatedAt %v, version %d", p.UpdatedAt, p.Version)
+	}
+
+	updated, ok := store.Update(u.ID, "Alice Updated", nil, 0)
+	if !ok {
+		t.Fatal("update failed")
+	}
+	if want := testEpoch.Add(time.Minute); !updated.UpdatedAt.Equal(want) {
+		t.Fatalf("updatedAt after update = %v, want %v", updated.UpdatedAt, want)
+	}
+	if !updated.CreatedAt.Equal(testEpoch) {
+		t.Fatalf("update moved createdAt to %v", updated.CreatedAt)
+	}
+
+	clock.Advance(time.Minute)
+	name := "Alice Patched"
+	patched, _ := store.Patch(u.ID, &name, nil, 0)
+	if want := testEpoch.Add(2 * time.Minute); !patched.UpdatedAt.Equal(want) {
+		t.Fatalf("updatedAt after patch = %v, want %v", patched.UpdatedAt, want)
+	}
+}
+
+// ID hasil restore yang tidak urut, dengan nextID tertinggal, tidak pernah
+// dipakai ulang oleh create berikutnya
+func TestRestoredOutOfOrderIDsDoNotCollide(t *testing.T) {
+	store := NewUserStore(IDModeInt)
+	items := map[UserID]User{}
+	for _, id := range []UserID{"7", "2", "5"} {
+		items[id] = User{ID: id, Name: "restored " + string(id)}
+	}
+	store.restoreSnapshot(userStoreSnapshot{nextID: 1, items: items})
+
+	u := store.Create("Ada", RoleUser, nil)
+	if u.ID != "8" {
+		t.Fatalf("first create after restore got id %s, want 8", u.ID)
+	}
+	for _, id := range []UserID{"7", "2", "5"} {
+		if got, _ := store.Get(id); got.Name != "restored "+string(id) {
+			t.Errorf("restored user %s overwritten: %+v", id, got)
+		}
+	}
+
+	// newID sendiri juga melewati ID yang sudah dipakai walau nextID tertinggal
+	store.mu.Lock()
+	store.nextID = 2
+	id := store.newID()
+	store.mu.Unlock()
+	if id != "3" {
+		t.Fatalf("newID with stale nextID = %s, want 3", id)
+	}
+}